@@ -1,25 +1,64 @@
 package config
 
 import (
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
 	"github.com/spf13/viper"
 )
 
+// EnvPrefix 环境变量前缀，配置键中的 "." 替换为 "_"，如 GOBOOT_MYSQL_PASSWORD 覆盖 mysql.password
+const EnvPrefix = "GOBOOT"
+
 type Config struct {
 	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
 	MySQL     MySQLConfig     `mapstructure:"mysql"`
 	Redis     RedisConfig     `mapstructure:"redis"`
 	JWT       JWTConfig       `mapstructure:"jwt"`
+	Password  PasswordConfig  `mapstructure:"password"`
 	Log       LogConfig       `mapstructure:"log"`
 	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
 	Email     EmailConfig     `mapstructure:"email"`
 	Upload    UploadConfig    `mapstructure:"upload"`
+	CORS      CORSConfig      `mapstructure:"cors"`
+	Compress  CompressConfig  `mapstructure:"compress"`
+
+	SecurityHeaders SecurityHeadersConfig `mapstructure:"security_headers"`
+	Metrics         MetricsConfig         `mapstructure:"metrics"`
+	Audit           AuditConfig           `mapstructure:"audit"`
+	AdminIPFilter   IPFilterConfig        `mapstructure:"admin_ip_filter"`
+	Webhook         WebhookConfig         `mapstructure:"webhook"`
 }
 
 type ServerConfig struct {
 	Host           string   `mapstructure:"host"`
-	Port           int      `mapstructure:"port"`
-	Mode           string   `mapstructure:"mode"`
+	Port           int      `mapstructure:"port" validate:"gte=1,lte=65535" label:"server.port"`
+	Mode           string   `mapstructure:"mode" validate:"omitempty,oneof=debug release test" label:"server.mode"`
 	TrustedProxies []string `mapstructure:"trusted_proxies"` // 可信代理IP列表，空则不信任任何代理
+	ProxyHeader    string   `mapstructure:"proxy_header"`    // 可信代理传递客户端IP的请求头，默认 X-Forwarded-For，取最右侧不属于可信代理的IP
+
+	RequestIDInResponse bool `mapstructure:"request_id_in_response"` // 是否在响应体中返回请求ID(requestId)
+	ShutdownTimeout     int  `mapstructure:"shutdown_timeout"`       // 优雅关闭超时时间(秒)，默认30
+	Pprof               bool `mapstructure:"pprof"`                  // 是否启用 /debug/pprof 性能分析接口(仅超级管理员可访问)
+	Swagger             bool `mapstructure:"swagger"`                // 是否启用 /swagger 接口文档，生产环境应关闭
+}
+
+// IPFilterConfig IP访问控制，支持单个IP和CIDR
+type IPFilterConfig struct {
+	Allow []string `mapstructure:"allow"` // 白名单，为空表示不限制
+	Deny  []string `mapstructure:"deny"`  // 黑名单，优先于白名单
+}
+
+type DatabaseConfig struct {
+	Driver     string `mapstructure:"driver" validate:"omitempty,oneof=mysql sqlite" label:"database.driver"` // 数据库驱动: mysql(默认), sqlite
+	SQLitePath string `mapstructure:"sqlite_path"`                                                            // SQLite 数据库文件路径，":memory:" 表示内存数据库
+
+	SlowThreshold int `mapstructure:"slow_threshold"` // 慢查询阈值(毫秒)，默认200，超过时记录警告日志
+	MaxPageSize   int `mapstructure:"max_page_size"`  // 列表接口每页最大条数，默认100，超过时按最大值返回
+	QueryTimeout  int `mapstructure:"query_timeout"`  // 单次数据库操作超时时间(秒)，默认5，超时后取消查询
 }
 
 type MySQLConfig struct {
@@ -29,8 +68,21 @@ type MySQLConfig struct {
 	Password     string `mapstructure:"password"`
 	Database     string `mapstructure:"database"`
 	Charset      string `mapstructure:"charset"`
-	MaxIdleConns int    `mapstructure:"max_idle_conns"`
-	MaxOpenConns int    `mapstructure:"max_open_conns"`
+	MaxIdleConns int    `mapstructure:"max_idle_conns" validate:"gte=0" label:"mysql.max_idle_conns"`
+	MaxOpenConns int    `mapstructure:"max_open_conns"` // driver 为 mysql 时必须大于0，见 Config.Validate
+
+	Replicas []MySQLReplicaConfig `mapstructure:"replicas"` // 只读从库，未配置时读写都走主库
+
+	ConnectRetries  int `mapstructure:"connect_retries"`  // 启动时连接的最大尝试次数，默认5
+	ConnectInterval int `mapstructure:"connect_interval"` // 启动时首次重试间隔(秒)，默认1，之后每次翻倍，最长30秒
+}
+
+// MySQLReplicaConfig MySQL 从库配置，user/password 为空时沿用主库配置
+type MySQLReplicaConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
 }
 
 type RedisConfig struct {
@@ -38,30 +90,113 @@ type RedisConfig struct {
 	Port     int    `mapstructure:"port"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
-	PoolSize int    `mapstructure:"pool_size"`
+	PoolSize int    `mapstructure:"pool_size" validate:"gte=0" label:"redis.pool_size"` // 连接池大小，0 表示使用 go-redis 默认值
+
+	// 哨兵模式：配置 master_name 后通过 sentinel_addrs 发现主节点
+	MasterName       string   `mapstructure:"master_name"`
+	SentinelAddrs    []string `mapstructure:"sentinel_addrs"`
+	SentinelPassword string   `mapstructure:"sentinel_password"`
+	// 集群模式：配置 cluster_addrs 后使用集群客户端，忽略 host/port/db
+	ClusterAddrs []string `mapstructure:"cluster_addrs"`
+
+	UserCacheTTL int `mapstructure:"user_cache_ttl"` // 用户信息缓存时间(秒)，默认60，小于0时不缓存
+
+	ConnectRetries  int `mapstructure:"connect_retries"`  // 启动时连接的最大尝试次数，默认5
+	ConnectInterval int `mapstructure:"connect_interval"` // 启动时首次重试间隔(秒)，默认1，之后每次翻倍，最长30秒
+
+	TokenCheckFailClosed bool `mapstructure:"token_check_fail_closed"` // Redis 不可用时令牌黑名单和会话撤销检查是否拒绝访问，默认放行
 }
 
 type JWTConfig struct {
-	Secret        string `mapstructure:"secret"`
-	AccessExpire  int    `mapstructure:"access_expire"`  // Access Token过期时间(小时)
-	RefreshExpire int    `mapstructure:"refresh_expire"` // Refresh Token过期时间(小时)
-	RefreshSecret string `mapstructure:"refresh_secret"` // Refresh Token密钥
+	Secret        string `mapstructure:"secret"`                                                     // HS256 时必填，见 Config.Validate
+	AccessExpire  int    `mapstructure:"access_expire" validate:"gte=1" label:"jwt.access_expire"`   // Access Token过期时间(小时)
+	RefreshExpire int    `mapstructure:"refresh_expire" validate:"gte=1" label:"jwt.refresh_expire"` // Refresh Token过期时间(小时)
+	RefreshSecret string `mapstructure:"refresh_secret"`                                             // Refresh Token密钥
+	Algorithm     string `mapstructure:"algorithm"`                                                  // 签名算法: HS256(默认), RS256
+	PrivateKey    string `mapstructure:"private_key"`                                                // RS256 私钥PEM文件路径(签发令牌)
+	PublicKey     string `mapstructure:"public_key"`                                                 // RS256 公钥PEM文件路径(验证令牌)
+	Issuer        string `mapstructure:"issuer"`                                                     // 签发者(iss)，为空则不校验
+	Audience      string `mapstructure:"audience"`                                                   // 受众(aud)，为空则不校验
+}
+
+// PasswordConfig 密码哈希配置，哈希自带算法和参数，修改后旧密码仍可验证，登录成功时自动升级
+type PasswordConfig struct {
+	Algorithm     string `mapstructure:"algorithm" validate:"omitempty,oneof=bcrypt argon2id" label:"password.algorithm"` // 哈希算法: bcrypt(默认), argon2id
+	BcryptCost    int    `mapstructure:"bcrypt_cost" validate:"omitempty,gte=4,lte=31" label:"password.bcrypt_cost"`      // bcrypt 成本(4-31)，默认10，每加1耗时翻倍
+	Argon2Memory  uint32 `mapstructure:"argon2_memory"`                                                                   // argon2id 内存(KB)，默认65536
+	Argon2Time    uint32 `mapstructure:"argon2_time"`                                                                     // argon2id 迭代次数，默认3
+	Argon2Threads uint8  `mapstructure:"argon2_threads"`                                                                  // argon2id 并行度，默认2
 }
 
 type LogConfig struct {
-	Level      string `mapstructure:"level"`
+	Level      string `mapstructure:"level" validate:"omitempty,oneof=debug info warn error" label:"log.level"`
 	Filename   string `mapstructure:"filename"`
 	MaxSize    int    `mapstructure:"max_size"`
 	MaxBackups int    `mapstructure:"max_backups"`
 	MaxAge     int    `mapstructure:"max_age"`
 	Compress   bool   `mapstructure:"compress"`
 	Console    bool   `mapstructure:"console"`
+	Format     string `mapstructure:"format"`      // 日志格式: json(默认), text
+	Color      bool   `mapstructure:"color"`       // text 格式下控制台输出是否着色
+	AddSource  bool   `mapstructure:"add_source"`  // 是否输出调用位置(source)
+	TimeFormat string `mapstructure:"time_format"` // 时间格式(Go layout)，为空时使用 RFC3339Nano
 }
 
 type RateLimitConfig struct {
 	Enabled  bool `mapstructure:"enabled"`  // 是否启用限流
 	Requests int  `mapstructure:"requests"` // 时间窗口内允许的请求数
 	Window   int  `mapstructure:"window"`   // 时间窗口（秒）
+
+	// 按登录用户限流，在认证之后的路由组上生效，与按IP限流同时生效
+	UserRequests int `mapstructure:"user_requests"` // 时间窗口内每个用户允许的请求数，0 表示不限制
+	UserWindow   int `mapstructure:"user_window"`   // 时间窗口（秒），为 0 时使用 window
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`   // 允许的来源，为空则允许任意来源(*)
+	AllowedMethods   []string `mapstructure:"allowed_methods"`   // 允许的请求方法
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`   // 允许的请求头
+	AllowCredentials bool     `mapstructure:"allow_credentials"` // 是否允许携带凭证(仅对白名单中明确列出的来源生效，不能与 * 同时使用)
+	MaxAge           int      `mapstructure:"max_age"`           // 预检请求缓存时间(秒)
+}
+
+type CompressConfig struct {
+	Enabled bool `mapstructure:"enabled"`  // 是否启用响应压缩
+	Level   int  `mapstructure:"level"`    // 压缩级别 1(最快)-9(最高压缩率)，默认6
+	MinSize int  `mapstructure:"min_size"` // 最小压缩大小(字节)，小于该值不压缩，默认1024
+}
+
+type SecurityHeadersConfig struct {
+	Enabled               bool   `mapstructure:"enabled"`                 // 是否启用安全响应头
+	XContentTypeOptions   string `mapstructure:"x_content_type_options"`  // X-Content-Type-Options，为空则不设置
+	XFrameOptions         string `mapstructure:"x_frame_options"`         // X-Frame-Options，为空则不设置
+	UploadsXFrameOptions  string `mapstructure:"uploads_x_frame_options"` // /uploads/* 使用的 X-Frame-Options，为空则不设置
+	ReferrerPolicy        string `mapstructure:"referrer_policy"`         // Referrer-Policy，为空则不设置
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"` // Content-Security-Policy，为空则不设置
+	HSTSMaxAge            int    `mapstructure:"hsts_max_age"`            // Strict-Transport-Security max-age(秒)，0 表示不设置，仅在 HTTPS 下开启
+	HSTSIncludeSubdomains bool   `mapstructure:"hsts_include_subdomains"` // HSTS 是否包含子域名
+}
+
+type MetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"` // 是否启用 Prometheus 指标(/metrics)
+}
+
+type AuditConfig struct {
+	RetentionDays int    `mapstructure:"retention_days"` // 审计日志保留天数，0 表示永久保留
+	CleanupSpec   string `mapstructure:"cleanup_spec"`   // 过期审计日志清理任务的 cron 表达式(秒 分 时 日 月 周)
+
+	BufferSize    int `mapstructure:"buffer_size"`    // 写入缓冲队列长度，默认1024，队列满时直接写库
+	BatchSize     int `mapstructure:"batch_size"`     // 每批写入条数，默认100
+	FlushInterval int `mapstructure:"flush_interval"` // 最长写入间隔(毫秒)，默认2000
+}
+
+// WebhookConfig 事件通知配置，事件发生时向订阅地址异步 POST JSON
+type WebhookConfig struct {
+	Enabled    bool                `mapstructure:"enabled"`     // 是否启用事件通知
+	Secret     string              `mapstructure:"secret"`      // HMAC-SHA256 签名密钥，签名放在 X-Webhook-Signature 请求头，为空时不签名
+	Timeout    int                 `mapstructure:"timeout"`     // 单次请求超时时间(秒)，默认5
+	MaxRetries int                 `mapstructure:"max_retries"` // 失败后的最大重试次数，默认3，间隔从1秒起每次翻倍，小于0时不重试
+	Endpoints  map[string][]string `mapstructure:"endpoints"`   // 事件名(审计操作类型，如 register、update_status)到目标地址的映射，"*" 订阅所有事件
 }
 
 type EmailConfig struct {
@@ -78,31 +213,98 @@ type EmailConfig struct {
 }
 
 type UploadConfig struct {
-	Enabled      bool     `mapstructure:"enabled"`        // 是否启用上传服务
-	StorageType  string   `mapstructure:"storage_type"`   // 存储类型: local, oss, s3
-	LocalPath    string   `mapstructure:"local_path"`     // 本地存储路径
-	BaseURL      string   `mapstructure:"base_url"`       // 文件访问URL前缀
-	MaxSize      int      `mapstructure:"max_size"`       // 最大文件大小(MB)
-	MaxImageSize int      `mapstructure:"max_image_size"` // 最大图片大小(MB)
-	AllowedExts  []string `mapstructure:"allowed_exts"`   // 允许的文件扩展名
-	ImageExts    []string `mapstructure:"image_exts"`     // 允许的图片扩展名
+	Enabled       bool     `mapstructure:"enabled"`                                                                                       // 是否启用上传服务
+	StorageType   string   `mapstructure:"storage_type" validate:"omitempty,oneof=local oss s3" label:"upload.storage_type"`              // 存储类型: local, oss, s3
+	LocalPath     string   `mapstructure:"local_path"`                                                                                    // 本地存储路径
+	BaseURL       string   `mapstructure:"base_url"`                                                                                      // 文件访问URL前缀
+	MaxSize       int      `mapstructure:"max_size"`                                                                                      // 最大文件大小(MB)
+	MaxImageSize  int      `mapstructure:"max_image_size"`                                                                                // 最大图片大小(MB)
+	MaxAvatarSize int      `mapstructure:"max_avatar_size"`                                                                               // 最大头像大小(MB)，默认 2
+	AllowedExts   []string `mapstructure:"allowed_exts"`                                                                                  // 允许的文件扩展名
+	ImageExts     []string `mapstructure:"image_exts"`                                                                                    // 允许的图片扩展名
+	StripExif     bool     `mapstructure:"strip_exif"`                                                                                    // 上传 JPEG/WebP 图片时去除 EXIF(含GPS定位)等元数据
+	AutoOrient    bool     `mapstructure:"auto_orient"`                                                                                   // 上传 JPEG 图片时按 EXIF 方向标记自动旋转
+	ImageQuality  int      `mapstructure:"image_quality"`                                                                                 // 图片重新编码质量(1-100)，默认 85
+	Dedup         bool     `mapstructure:"dedup"`                                                                                         // 按内容哈希去重，相同文件只保存一份
+	Concurrency   int      `mapstructure:"concurrency" validate:"gte=0,lte=32" label:"upload.concurrency"`                                // 批量上传时同时上传的文件数，默认 4
+	ActiveContent string   `mapstructure:"active_content" validate:"omitempty,oneof=reject sanitize allow" label:"upload.active_content"` // SVG/HTML 文件的处理策略: reject, sanitize, allow，默认 reject
+	SignSecret    string   `mapstructure:"sign_secret"`                                                                                   // 本地存储签名下载链接的密钥，为空时由 jwt.secret 派生
+	MinFreeDisk   float64  `mapstructure:"min_free_disk"`                                                                                 // 本地存储磁盘可用空间低于该百分比时健康检查返回 degraded，默认 10
+	StaticMaxAge  int      `mapstructure:"static_max_age"`                                                                                // 公开文件静态访问的缓存时间(秒)，默认 30 天，负数表示不设置缓存头
+}
+
+// current 当前生效的配置，加载或重新加载时整体原子替换，读取方无需加锁
+var current atomic.Pointer[Config]
+
+// Get 返回当前配置，可在多个协程中并发调用；返回的配置不应被修改，
+// 同一次处理中多次读取时应先保存到局部变量，避免重新加载前后读到不一致的值
+func Get() *Config {
+	return current.Load()
+}
+
+// Set 原子替换当前配置，用于重新加载配置和测试
+func Set(cfg *Config) {
+	current.Store(cfg)
 }
 
-var AppConfig *Config
+// IsSet 配置文件或环境变量中是否设置了 key(如 "upload.max_size")，用于区分未配置和配置为零值
+func IsSet(key string) bool {
+	return viper.IsSet(key)
+}
 
-func InitConfig() error {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
+// InitConfig 加载配置文件，并使用 GOBOOT_ 前缀的环境变量覆盖其中的配置
+// path 为空时使用 CONFIG_FILE 环境变量，均未指定时读取当前目录的 config.yaml；
+// 指定路径时按扩展名识别格式，支持 yaml、json、toml 等；再次调用可重新加载，新配置整体原子替换
+func InitConfig(path string) error {
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+		viper.AddConfigPath(".")
+	}
 
 	if err := viper.ReadInConfig(); err != nil {
 		return err
 	}
 
-	AppConfig = &Config{}
-	if err := viper.Unmarshal(AppConfig); err != nil {
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	// AutomaticEnv 只对 viper 已知的键生效，绑定所有配置项使配置文件中没有的项也能通过环境变量设置
+	bindEnvs(reflect.TypeOf(Config{}), "")
+
+	cfg := &Config{}
+	if err := viper.Unmarshal(cfg); err != nil {
 		return err
 	}
 
+	Set(cfg)
 	return nil
 }
+
+// bindEnvs 递归绑定结构体中 mapstructure 标签对应的配置键，切片类型的环境变量值按逗号分隔
+func bindEnvs(t reflect.Type, prefix string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch field.Type.Kind() {
+		case reflect.Struct:
+			bindEnvs(field.Type, key)
+		case reflect.Map:
+			// 映射的键不固定，无法通过环境变量设置
+		default:
+			_ = viper.BindEnv(key)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"goboot/pkg/validator"
+)
+
+// Validate 检查配置项取值，返回列出全部问题的错误，应在加载配置后立即调用以便启动时尽早失败
+// 单个字段的规则写在结构体的 validate 标签中，依赖其他配置项的规则在此处检查
+func (c *Config) Validate() error {
+	var problems []string
+	if err := validator.Validate(c); err != nil {
+		var verrs validator.ValidationErrors
+		if !errors.As(err, &verrs) {
+			return err
+		}
+		problems = append(problems, verrs.All()...)
+	}
+
+	switch strings.ToUpper(c.JWT.Algorithm) {
+	case "", "HS256":
+		if c.JWT.Secret == "" {
+			problems = append(problems, "jwt.secret不能为空")
+		}
+		if c.JWT.RefreshSecret == "" {
+			problems = append(problems, "jwt.refresh_secret不能为空")
+		}
+	case "RS256":
+		// 只配置私钥时从私钥导出公钥，只配置公钥时仅能验证令牌
+		if c.JWT.PrivateKey == "" && c.JWT.PublicKey == "" {
+			problems = append(problems, "jwt.private_key和jwt.public_key至少配置一个")
+		}
+	default:
+		problems = append(problems, "jwt.algorithm必须是以下值之一: HS256 RS256")
+	}
+
+	if c.CORS.AllowCredentials {
+		for _, origin := range c.CORS.AllowedOrigins {
+			if strings.TrimSpace(origin) == "*" {
+				problems = append(problems, "cors.allowed_origins包含*时不能开启cors.allow_credentials，请列出允许携带凭证的具体来源")
+				break
+			}
+		}
+	}
+
+	if c.Database.Driver != "sqlite" && c.MySQL.MaxOpenConns <= 0 {
+		problems = append(problems, "mysql.max_open_conns必须大于0")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("配置校验失败:\n  - %s", strings.Join(problems, "\n  - "))
+}
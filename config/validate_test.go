@@ -0,0 +1,27 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateCorsCredentials(t *testing.T) {
+	const problem = "cors.allowed_origins包含*时不能开启cors.allow_credentials"
+	tests := []struct {
+		name    string
+		cors    CORSConfig
+		invalid bool
+	}{
+		{"wildcard with credentials", CORSConfig{AllowedOrigins: []string{"https://a.example.com", " * "}, AllowCredentials: true}, true},
+		{"wildcard without credentials", CORSConfig{AllowedOrigins: []string{"*"}}, false},
+		{"listed origins with credentials", CORSConfig{AllowedOrigins: []string{"https://a.example.com"}, AllowCredentials: true}, false},
+	}
+	for _, tt := range tests {
+		cfg := &Config{CORS: tt.cors}
+		err := cfg.Validate()
+		got := err != nil && strings.Contains(err.Error(), problem)
+		if got != tt.invalid {
+			t.Errorf("%s: Validate() = %v, want cors problem %v", tt.name, err, tt.invalid)
+		}
+	}
+}
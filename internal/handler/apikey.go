@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"fmt"
+	"goboot/internal/model"
+	"goboot/internal/service"
+	"goboot/pkg/response"
+	"goboot/pkg/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+	auditService  *service.AuditService
+}
+
+func NewAPIKeyHandler() *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: service.NewAPIKeyService(),
+		auditService:  service.NewAuditService(),
+	}
+}
+
+type CreateAPIKeyRequest struct {
+	Name       string   `json:"name" validate:"required,max=64" label:"名称"`
+	Scopes     []string `json:"scopes" label:"权限范围"`                      // 权限标识列表，为空表示继承用户的全部权限
+	ExpireDays int      `json:"expireDays" validate:"gte=0" label:"有效天数"` // 0 表示永不过期
+}
+
+type RevokeAPIKeyRequest struct {
+	ID uint `json:"id" validate:"required" label:"API Key ID"`
+}
+
+// CreateAPIKeyResponse 创建 API Key 的结果，key 为密钥明文，仅返回这一次
+type CreateAPIKeyResponse struct {
+	Key    string        `json:"key"`
+	APIKey *model.APIKey `json:"apiKey"`
+}
+
+// GetAPIKeys 获取当前用户的 API Key 列表
+// @Summary API Key 列表
+// @Tags 用户
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.APIKey}
+// @Router /api/user/apiKeys [get]
+func (h *APIKeyHandler) GetAPIKeys(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	keys, err := h.apiKeyService.List(userID)
+	if err != nil {
+		return response.Fail(c, err.Error())
+	}
+	return response.Success(c, keys)
+}
+
+// CreateAPIKey 为当前用户创建 API Key
+// @Summary 创建 API Key
+// @Description 返回的 key 为密钥明文，仅在创建时返回一次，请求时通过 X-API-Key 请求头携带
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateAPIKeyRequest true "API Key 信息"
+// @Success 200 {object} response.Response{data=CreateAPIKeyResponse}
+// @Router /api/user/apiKeys/add [post]
+func (h *APIKeyHandler) CreateAPIKey(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var req CreateAPIKeyRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	var expiresAt *time.Time
+	if req.ExpireDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpireDays)
+		expiresAt = &t
+	}
+
+	key, plain, err := h.apiKeyService.Create(userID, req.Name, req.Scopes, expiresAt)
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionCreate, model.ModuleUser, req.Name, err.Error())
+		return response.Fail(c, err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionCreate, model.ModuleUser, fmt.Sprintf("%d", key.ID), "创建API Key: "+req.Name)
+	return response.SuccessWithMessage(c, "创建成功，请妥善保存密钥，关闭后将无法再次查看", CreateAPIKeyResponse{
+		Key:    plain,
+		APIKey: key,
+	})
+}
+
+// RevokeAPIKey 删除当前用户的 API Key
+// @Summary 删除 API Key
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body RevokeAPIKeyRequest true "API Key ID"
+// @Success 200 {object} response.Response
+// @Router /api/user/apiKeys/revoke [post]
+func (h *APIKeyHandler) RevokeAPIKey(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var req RevokeAPIKeyRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	target := fmt.Sprintf("%d", req.ID)
+	if err := h.apiKeyService.Revoke(userID, req.ID); err != nil {
+		h.auditService.LogFail(c, model.ActionDelete, model.ModuleUser, target, err.Error())
+		return response.Fail(c, err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionDelete, model.ModuleUser, target, "删除API Key")
+	return response.SuccessWithMessage(c, "删除成功", nil)
+}
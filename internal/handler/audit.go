@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"bufio"
+	"fmt"
+	"goboot/internal/model"
 	"goboot/internal/service"
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
 	"goboot/pkg/response"
+	"log/slog"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -19,30 +25,21 @@ func NewAuditHandler() *AuditHandler {
 }
 
 type AuditLogListRequest struct {
-	Page      int    `json:"page"`
-	PageSize  int    `json:"pageSize"`
-	UserID    uint   `json:"userId"`
-	Action    string `json:"action"`
-	Module    string `json:"module"`
-	StartTime string `json:"startTime"` // 格式: 2006-01-02 15:04:05
-	EndTime   string `json:"endTime"`
+	Page      int    `json:"page" query:"page"`
+	PageSize  int    `json:"pageSize" query:"pageSize"`
+	Cursor    uint   `json:"cursor" query:"-"` // 游标分页：上一页返回的 nextCursor，传 limit 时启用游标分页
+	Limit     int    `json:"limit" query:"-"`
+	UserID    uint   `json:"userId" query:"userId"`
+	Action    string `json:"action" query:"action"`
+	Module    string `json:"module" query:"module"`
+	Keyword   string `json:"keyword" query:"keyword"`     // 在操作详情、操作目标和用户名中模糊搜索
+	Status    *int   `json:"status" query:"status"`       // 1成功 0失败，不传表示不限
+	StartTime string `json:"startTime" query:"startTime"` // 格式: 2006-01-02 15:04:05
+	EndTime   string `json:"endTime" query:"endTime"`
 }
 
-// GetAuditLogs 获取审计日志列表
-func (h *AuditHandler) GetAuditLogs(c fiber.Ctx) error {
-	var req AuditLogListRequest
-	if err := c.Bind().Body(&req); err != nil {
-		req.Page = 1
-		req.PageSize = 10
-	}
-
-	if req.Page <= 0 {
-		req.Page = 1
-	}
-	if req.PageSize <= 0 {
-		req.PageSize = 10
-	}
-
+// toServiceRequest 解析时间等筛选条件，转换为服务层请求
+func (req *AuditLogListRequest) toServiceRequest() *service.AuditLogListRequest {
 	// 解析时间
 	var startTime, endTime *time.Time
 	if req.StartTime != "" {
@@ -58,20 +55,86 @@ func (h *AuditHandler) GetAuditLogs(c fiber.Ctx) error {
 		}
 	}
 
-	serviceReq := &service.AuditLogListRequest{
+	return &service.AuditLogListRequest{
 		Page:      req.Page,
 		PageSize:  req.PageSize,
+		Cursor:    req.Cursor,
+		Limit:     req.Limit,
 		UserID:    req.UserID,
 		Action:    req.Action,
 		Module:    req.Module,
+		Keyword:   req.Keyword,
+		Status:    req.Status,
 		StartTime: startTime,
 		EndTime:   endTime,
 	}
+}
+
+// GetAuditLogs 获取审计日志列表
+// 请求携带 limit 时使用游标分页(按ID倒序，cursor 为上一页返回的 nextCursor)，否则使用 page/pageSize 分页
+// @Summary 审计日志列表
+// @Description 请求携带 limit 时返回 response.CursorResult，否则返回 response.PageResult
+// @Tags 审计日志
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AuditLogListRequest false "筛选与分页条件"
+// @Success 200 {object} response.Response{data=response.PageResult{items=[]model.AuditLog}}
+// @Router /api/admin/audit/list [post]
+func (h *AuditHandler) GetAuditLogs(c fiber.Ctx) error {
+	var req AuditLogListRequest
+	_ = c.Bind().Body(&req)
+
+	if req.Limit > 0 {
+		req.Limit = database.ClampPageSize(req.Limit)
+		logs, nextCursor, hasMore, err := h.auditService.GetLogsByCursor(req.toServiceRequest())
+		if err != nil {
+			return response.Fail(c, err.Error())
+		}
+		return response.SuccessWithCursor(c, logs, nextCursor, hasMore, req.Limit)
+	}
 
-	logs, total, err := h.auditService.GetLogs(serviceReq)
+	req.Page, req.PageSize = database.NormalizePage(req.Page, req.PageSize)
+	logs, total, err := h.auditService.GetLogs(req.toServiceRequest())
 	if err != nil {
 		return response.Fail(c, err.Error())
 	}
 
 	return response.SuccessWithPage(c, logs, total, req.Page, req.PageSize)
 }
+
+// ExportAuditLogs 按筛选条件(Query参数，与列表接口一致)导出审计日志为CSV文件
+// @Summary 导出审计日志
+// @Tags 审计日志
+// @Produce text/csv
+// @Security BearerAuth
+// @Param userId query int false "用户ID"
+// @Param action query string false "操作类型"
+// @Param module query string false "模块"
+// @Param keyword query string false "关键字，在操作详情、操作目标和用户名中模糊搜索"
+// @Param status query int false "状态：1成功 0失败"
+// @Param startTime query string false "开始时间，格式: 2006-01-02 15:04:05"
+// @Param endTime query string false "结束时间，格式: 2006-01-02 15:04:05"
+// @Success 200 {file} file "CSV 文件"
+// @Router /api/admin/audit/export [get]
+func (h *AuditHandler) ExportAuditLogs(c fiber.Ctx) error {
+	var req AuditLogListRequest
+	if err := c.Bind().Query(&req); err != nil {
+		return response.Fail(c, "参数错误: "+err.Error())
+	}
+	serviceReq := req.toServiceRequest()
+
+	h.auditService.LogSuccess(c, model.ActionExport, model.ModuleAdmin, "", "导出审计日志")
+
+	filename := fmt.Sprintf("audit_logs_%s.csv", time.Now().Format("20060102150405"))
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// 响应头已发送，导出中途出错只能记录日志并截断文件
+	ctx := c.Context()
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		if err := h.auditService.ExportCSV(serviceReq, w); err != nil {
+			logger.ErrorContext(ctx, "导出审计日志失败", slog.Any("error", err))
+		}
+	})
+}
@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"goboot/internal/model"
 	"goboot/internal/service"
+	"goboot/pkg/database"
 	"goboot/pkg/response"
+	"goboot/pkg/validator"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -23,6 +29,13 @@ func NewConfigHandler() *ConfigHandler {
 }
 
 // GetAllConfigs 获取所有配置(管理员)
+// @Summary 全部配置
+// @Description 按分组返回全部配置
+// @Tags 系统配置
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=map[string][]model.SysConfig}
+// @Router /api/admin/config/list [get]
 func (h *ConfigHandler) GetAllConfigs(c fiber.Ctx) error {
 	configs, err := h.configService.GetAll()
 	if err != nil {
@@ -39,6 +52,13 @@ func (h *ConfigHandler) GetAllConfigs(c fiber.Ctx) error {
 }
 
 // GetConfigsByGroup 按分组获取配置
+// @Summary 按分组获取配置
+// @Tags 系统配置
+// @Produce json
+// @Security BearerAuth
+// @Param group query string true "配置分组"
+// @Success 200 {object} response.Response{data=[]model.SysConfig}
+// @Router /api/admin/config/group [get]
 func (h *ConfigHandler) GetConfigsByGroup(c fiber.Ctx) error {
 	group := c.Query("group")
 	if group == "" {
@@ -54,6 +74,12 @@ func (h *ConfigHandler) GetConfigsByGroup(c fiber.Ctx) error {
 }
 
 // GetPublicConfigs 获取公开配置(无需登录)
+// @Summary 公开配置
+// @Description 返回标记为公开的配置，格式为 key-value
+// @Tags 系统配置
+// @Produce json
+// @Success 200 {object} response.Response{data=map[string]string}
+// @Router /api/config/public [get]
 func (h *ConfigHandler) GetPublicConfigs(c fiber.Ctx) error {
 	configs, err := h.configService.GetPublic()
 	if err != nil {
@@ -82,6 +108,14 @@ type CreateConfigRequest struct {
 }
 
 // CreateConfig 创建配置
+// @Summary 创建配置
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateConfigRequest true "配置信息"
+// @Success 200 {object} response.Response{data=model.SysConfig}
+// @Router /api/admin/config/add [post]
 func (h *ConfigHandler) CreateConfig(c fiber.Ctx) error {
 	var req CreateConfigRequest
 	if err := c.Bind().Body(&req); err != nil {
@@ -131,9 +165,18 @@ type UpdateConfigRequest struct {
 	Remark      string `json:"remark"`
 	Sort        int    `json:"sort"`
 	IsPublic    bool   `json:"isPublic"`
+	Version     int    `json:"version" validate:"required"` // 读取配置时的版本号，与当前版本不一致时拒绝更新
 }
 
 // UpdateConfig 更新配置
+// @Summary 更新配置
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body UpdateConfigRequest true "配置信息"
+// @Success 200 {object} response.Response{data=model.SysConfig}
+// @Router /api/admin/config/update [post]
 func (h *ConfigHandler) UpdateConfig(c fiber.Ctx) error {
 	var req UpdateConfigRequest
 	if err := c.Bind().Body(&req); err != nil {
@@ -143,9 +186,12 @@ func (h *ConfigHandler) UpdateConfig(c fiber.Ctx) error {
 	if req.ID == 0 {
 		return response.Fail(c, "配置ID不能为空")
 	}
+	if req.Version == 0 {
+		return response.Fail(c, "配置版本号不能为空")
+	}
 
 	config := &model.SysConfig{
-		ID:          req.ID,
+		BaseModel:   model.BaseModel{ID: req.ID},
 		ConfigKey:   req.ConfigKey,
 		ConfigValue: req.ConfigValue,
 		ConfigType:  req.ConfigType,
@@ -154,9 +200,10 @@ func (h *ConfigHandler) UpdateConfig(c fiber.Ctx) error {
 		Remark:      req.Remark,
 		Sort:        req.Sort,
 		IsPublic:    req.IsPublic,
+		Version:     req.Version,
 	}
 
-	if err := h.configService.Update(config); err != nil {
+	if err := h.configService.Update(config, currentUsername(c)); err != nil {
 		h.auditService.LogFail(c, model.ActionUpdate, model.ModuleConfig, req.ConfigKey, err.Error())
 		return response.Fail(c, "更新配置失败: "+err.Error())
 	}
@@ -171,6 +218,14 @@ type BatchUpdateRequest struct {
 }
 
 // BatchUpdateConfigs 批量更新配置值
+// @Summary 批量更新配置值
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body BatchUpdateRequest true "配置键与配置值"
+// @Success 200 {object} response.Response
+// @Router /api/admin/config/batchUpdate [post]
 func (h *ConfigHandler) BatchUpdateConfigs(c fiber.Ctx) error {
 	var req BatchUpdateRequest
 	if err := c.Bind().Body(&req); err != nil {
@@ -181,7 +236,7 @@ func (h *ConfigHandler) BatchUpdateConfigs(c fiber.Ctx) error {
 		return response.Fail(c, "配置数据不能为空")
 	}
 
-	if err := h.configService.BatchUpdate(req.Configs); err != nil {
+	if err := h.configService.BatchUpdate(req.Configs, currentUsername(c)); err != nil {
 		h.auditService.LogFail(c, model.ActionUpdate, model.ModuleConfig, "", err.Error())
 		return response.Fail(c, "批量更新失败: "+err.Error())
 	}
@@ -196,6 +251,14 @@ type DeleteConfigRequest struct {
 }
 
 // DeleteConfig 删除配置
+// @Summary 删除配置
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body DeleteConfigRequest true "配置ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/config/delete [post]
 func (h *ConfigHandler) DeleteConfig(c fiber.Ctx) error {
 	var req DeleteConfigRequest
 	if err := c.Bind().Body(&req); err != nil {
@@ -215,7 +278,131 @@ func (h *ConfigHandler) DeleteConfig(c fiber.Ctx) error {
 	return response.SuccessWithMessage(c, "删除成功", nil)
 }
 
+// ConfigHistoryRequest 配置变更历史查询请求
+type ConfigHistoryRequest struct {
+	Key      string `query:"key"`
+	Page     int    `query:"page"`
+	PageSize int    `query:"pageSize"`
+}
+
+// GetConfigHistory 获取配置变更历史
+// @Summary 配置变更历史
+// @Tags 系统配置
+// @Produce json
+// @Security BearerAuth
+// @Param key query string false "配置键，为空时查询全部"
+// @Param page query int false "页码"
+// @Param pageSize query int false "每页条数"
+// @Success 200 {object} response.Response{data=response.PageResult{items=[]model.ConfigHistory}}
+// @Router /api/admin/config/history [get]
+func (h *ConfigHandler) GetConfigHistory(c fiber.Ctx) error {
+	var req ConfigHistoryRequest
+	if err := c.Bind().Query(&req); err != nil {
+		return response.Fail(c, "参数错误: "+err.Error())
+	}
+
+	req.Page, req.PageSize = database.NormalizePage(req.Page, req.PageSize)
+	histories, total, err := h.configService.GetHistory(req.Key, req.Page, req.PageSize)
+	if err != nil {
+		return response.Fail(c, "获取变更历史失败: "+err.Error())
+	}
+
+	return response.SuccessWithPage(c, histories, total, req.Page, req.PageSize)
+}
+
+// RollbackConfigRequest 配置回滚请求
+type RollbackConfigRequest struct {
+	HistoryID uint `json:"historyId" validate:"required"`
+}
+
+// RollbackConfig 将配置恢复为指定变更记录之前的值
+// @Summary 回滚配置
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body RollbackConfigRequest true "变更记录ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/config/rollback [post]
+func (h *ConfigHandler) RollbackConfig(c fiber.Ctx) error {
+	var req RollbackConfigRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return response.Fail(c, "参数错误: "+err.Error())
+	}
+
+	if req.HistoryID == 0 {
+		return response.Fail(c, "变更记录ID不能为空")
+	}
+
+	history, err := h.configService.Rollback(req.HistoryID, currentUsername(c))
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionUpdate, model.ModuleConfig, "", err.Error())
+		return response.Fail(c, "回滚配置失败: "+err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionUpdate, model.ModuleConfig, history.ConfigKey, fmt.Sprintf("回滚系统配置至变更记录 %d 之前", history.ID))
+	return response.SuccessWithMessage(c, "回滚成功", nil)
+}
+
+// ExportConfigs 导出所有配置为JSON文件
+// @Summary 导出配置
+// @Tags 系统配置
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} service.ConfigExport
+// @Router /api/admin/config/export [get]
+func (h *ConfigHandler) ExportConfigs(c fiber.Ctx) error {
+	data, err := h.configService.Export()
+	if err != nil {
+		return response.Fail(c, "导出配置失败: "+err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionExport, model.ModuleConfig, "", "导出系统配置")
+
+	filename := fmt.Sprintf("sys_configs_%s.json", time.Now().Format("20060102150405"))
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return c.Send(data)
+}
+
+// ImportConfigs 导入配置，请求体为导出的JSON文档，overwrite=true 时覆盖已存在的配置
+// @Summary 导入配置
+// @Description 部分配置被跳过时 data.skipped 返回跳过的配置键
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param overwrite query bool false "是否覆盖已存在的配置"
+// @Param body body service.ConfigExport true "导出的配置文档"
+// @Success 200 {object} response.Response
+// @Router /api/admin/config/import [post]
+func (h *ConfigHandler) ImportConfigs(c fiber.Ctx) error {
+	overwrite := fiber.Query[bool](c, "overwrite")
+
+	err := h.configService.Import(c.Body(), overwrite)
+	var importErr *service.ConfigImportError
+	if errors.As(err, &importErr) {
+		h.auditService.LogSuccess(c, model.ActionImport, model.ModuleConfig, "", "导入系统配置(部分跳过)")
+		return response.SuccessWithMessage(c, "导入完成，部分配置已跳过", fiber.Map{
+			"skipped": importErr.Skipped,
+		})
+	}
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionImport, model.ModuleConfig, "", err.Error())
+		return response.Fail(c, "导入配置失败: "+err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionImport, model.ModuleConfig, "", "导入系统配置")
+	return response.SuccessWithMessage(c, "导入成功", nil)
+}
+
 // RefreshCache 刷新配置缓存
+// @Summary 刷新配置缓存
+// @Tags 系统配置
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Router /api/admin/config/refresh [post]
 func (h *ConfigHandler) RefreshCache(c fiber.Ctx) error {
 	if err := h.configService.LoadAll(); err != nil {
 		return response.Fail(c, "刷新缓存失败: "+err.Error())
@@ -226,6 +413,12 @@ func (h *ConfigHandler) RefreshCache(c fiber.Ctx) error {
 }
 
 // GetEmailConfig 获取邮件配置
+// @Summary 获取邮件配置
+// @Tags 系统配置
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.SysConfig}
+// @Router /api/admin/config/email [get]
 func (h *ConfigHandler) GetEmailConfig(c fiber.Ctx) error {
 	configs, err := h.configService.GetByGroup(model.ConfigGroupEmail)
 	if err != nil {
@@ -249,6 +442,14 @@ type UpdateEmailConfigRequest struct {
 }
 
 // UpdateEmailConfig 更新邮件配置
+// @Summary 更新邮件配置
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body UpdateEmailConfigRequest true "邮件配置"
+// @Success 200 {object} response.Response
+// @Router /api/admin/config/email [post]
 func (h *ConfigHandler) UpdateEmailConfig(c fiber.Ctx) error {
 	var req UpdateEmailConfigRequest
 	if err := c.Bind().Body(&req); err != nil {
@@ -268,7 +469,7 @@ func (h *ConfigHandler) UpdateEmailConfig(c fiber.Ctx) error {
 		"email_reset_expire": intToString(req.ResetExpire),
 	}
 
-	if err := h.configService.BatchUpdate(configs); err != nil {
+	if err := h.configService.BatchUpdate(configs, currentUsername(c)); err != nil {
 		h.auditService.LogFail(c, model.ActionUpdate, model.ModuleConfig, "email", err.Error())
 		return response.Fail(c, "更新邮件配置失败: "+err.Error())
 	}
@@ -277,7 +478,165 @@ func (h *ConfigHandler) UpdateEmailConfig(c fiber.Ctx) error {
 	return response.SuccessWithMessage(c, "邮件配置更新成功", nil)
 }
 
+// GetUploadConfig 获取上传配置
+// @Summary 获取上传配置
+// @Tags 系统配置
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.SysConfig}
+// @Router /api/admin/config/upload [get]
+func (h *ConfigHandler) GetUploadConfig(c fiber.Ctx) error {
+	configs, err := h.configService.GetByGroup(model.ConfigGroupUpload)
+	if err != nil {
+		return response.Fail(c, "获取配置失败: "+err.Error())
+	}
+	return response.Success(c, configs)
+}
+
+// UpdateUploadConfigRequest 更新上传配置请求，大小单位为MB，扩展名不区分大小写，可省略前导点
+type UpdateUploadConfigRequest struct {
+	Enabled       bool     `json:"enabled"`
+	StorageType   string   `json:"storageType" validate:"required,oneof=local oss s3" label:"存储类型"`
+	LocalPath     string   `json:"localPath" label:"本地存储路径"`
+	BaseURL       string   `json:"baseUrl" label:"文件访问URL"`
+	MaxSize       int      `json:"maxSize" validate:"gt=0" label:"最大文件大小"`
+	MaxImageSize  int      `json:"maxImageSize" validate:"gt=0" label:"最大图片大小"`
+	MaxAvatarSize int      `json:"maxAvatarSize" validate:"gt=0" label:"最大头像大小"`
+	AllowedExts   []string `json:"allowedExts" validate:"required" label:"允许的文件类型"`
+	ImageExts     []string `json:"imageExts" validate:"required" label:"允许的图片类型"`
+	StripExif     bool     `json:"stripExif"`
+	AutoOrient    bool     `json:"autoOrient"`
+	ImageQuality  int      `json:"imageQuality" validate:"gte=1,lte=100" label:"图片压缩质量"`
+	Dedup         bool     `json:"dedup"`
+	Concurrency   int      `json:"concurrency" validate:"omitempty,gte=1,lte=32" label:"批量上传并发数"`                       // 为空时保持当前值
+	ActiveContent string   `json:"activeContent" validate:"omitempty,oneof=reject sanitize allow" label:"SVG/HTML处理策略"` // 为空时保持当前值
+}
+
+// UpdateUploadConfig 更新上传配置，保存后立即生效，存储类型或路径变更时重建存储后端
+// @Summary 更新上传配置
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body UpdateUploadConfigRequest true "上传配置"
+// @Success 200 {object} response.Response
+// @Router /api/admin/config/upload [post]
+func (h *ConfigHandler) UpdateUploadConfig(c fiber.Ctx) error {
+	var req UpdateUploadConfigRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if req.StorageType == "local" && req.LocalPath == "" {
+		return response.Fail(c, "本地存储路径不能为空")
+	}
+
+	allowedExts, err := extsToJSON(req.AllowedExts)
+	if err != nil {
+		return response.Fail(c, "允许的文件类型"+err.Error())
+	}
+	imageExts, err := extsToJSON(req.ImageExts)
+	if err != nil {
+		return response.Fail(c, "允许的图片类型"+err.Error())
+	}
+
+	configs := map[string]string{
+		"upload_enabled":         boolToString(req.Enabled),
+		"upload_storage_type":    req.StorageType,
+		"upload_local_path":      req.LocalPath,
+		"upload_base_url":        req.BaseURL,
+		"upload_max_size":        intToString(req.MaxSize),
+		"upload_max_image_size":  intToString(req.MaxImageSize),
+		"upload_max_avatar_size": intToString(req.MaxAvatarSize),
+		"upload_allowed_exts":    allowedExts,
+		"upload_image_exts":      imageExts,
+		"upload_strip_exif":      boolToString(req.StripExif),
+		"upload_auto_orient":     boolToString(req.AutoOrient),
+		"upload_image_quality":   intToString(req.ImageQuality),
+		"upload_dedup":           boolToString(req.Dedup),
+	}
+	if req.Concurrency > 0 {
+		configs["upload_concurrency"] = intToString(req.Concurrency)
+	}
+	if req.ActiveContent != "" {
+		configs["upload_active_content"] = req.ActiveContent
+	}
+
+	if err := h.configService.BatchUpdate(configs, currentUsername(c)); err != nil {
+		h.auditService.LogFail(c, model.ActionUpdate, model.ModuleConfig, "upload", err.Error())
+		return response.Fail(c, "更新上传配置失败: "+err.Error())
+	}
+	service.ReloadStorage()
+
+	h.auditService.LogSuccess(c, model.ActionUpdate, model.ModuleConfig, "upload", "更新上传配置")
+	return response.SuccessWithMessage(c, "上传配置更新成功", nil)
+}
+
+// extsToJSON 将扩展名规范为小写并带前导点，去重后序列化为 JSON 数组
+func extsToJSON(exts []string) (string, error) {
+	normalized := make([]string, 0, len(exts))
+	seen := make(map[string]bool)
+	for _, ext := range exts {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if strings.ContainsAny(ext[1:], "./\\ ") {
+			return "", fmt.Errorf("包含无效的扩展名: %s", ext)
+		}
+		if !seen[ext] {
+			seen[ext] = true
+			normalized = append(normalized, ext)
+		}
+	}
+	if len(normalized) == 0 {
+		return "", errors.New("不能为空")
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SendTestEmailRequest 发送测试邮件请求
+type SendTestEmailRequest struct {
+	To string `json:"to" validate:"required,email" label:"收件人"`
+}
+
+// SendTestEmail 使用当前邮件配置发送测试邮件
+// @Summary 发送测试邮件
+// @Description 按当前邮件配置新建 SMTP 连接发送测试邮件(不经过连接池)，同步返回发送结果
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body SendTestEmailRequest true "收件人"
+// @Success 200 {object} response.Response
+// @Router /api/admin/config/email/test [post]
+func (h *ConfigHandler) SendTestEmail(c fiber.Ctx) error {
+	var req SendTestEmailRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := service.NewEmailService().SendTestMail(req.To); err != nil {
+		return response.Fail(c, "发送测试邮件失败: "+err.Error())
+	}
+	return response.SuccessWithMessage(c, "测试邮件已发送", nil)
+}
+
 // 辅助函数
+func currentUsername(c fiber.Ctx) string {
+	if name, ok := c.Locals("username").(string); ok {
+		return name
+	}
+	return ""
+}
+
 func boolToString(b bool) string {
 	if b {
 		return "true"
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"goboot/internal/service"
+	"goboot/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type CronHandler struct {
+	cronService *service.CronService
+}
+
+func NewCronHandler() *CronHandler {
+	return &CronHandler{
+		cronService: service.GetCronService(),
+	}
+}
+
+// GetJobs 获取已注册的定时任务
+// @Summary 定时任务列表
+// @Tags 系统
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]string}
+// @Router /api/admin/cron/jobs [get]
+func (h *CronHandler) GetJobs(c fiber.Ctx) error {
+	return response.Success(c, h.cronService.GetJobs())
+}
+
+// JobEvents 以 SSE 推送定时任务的执行进度(开始、完成、失败)，客户端断开或服务关闭时结束
+// @Summary 定时任务执行事件
+// @Description SSE 事件流，事件名为 job，data 为 service.JobEvent
+// @Tags 系统
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {object} service.JobEvent
+// @Router /api/admin/cron/events [get]
+func (h *CronHandler) JobEvents(c fiber.Ctx) error {
+	jobEvents, unsubscribe := h.cronService.Subscribe()
+
+	events := make(chan response.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(events)
+		for {
+			select {
+			case event, ok := <-jobEvents:
+				if !ok {
+					return
+				}
+				select {
+				case events <- response.Event{Event: "job", Data: event}:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return response.SSE(c, events, func() {
+		close(done)
+		unsubscribe()
+	})
+}
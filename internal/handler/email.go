@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"log/slog"
+
 	"goboot/internal/model"
 	"goboot/internal/service"
+	"goboot/pkg/logger"
 	"goboot/pkg/response"
+	"goboot/pkg/validator"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -32,6 +36,14 @@ type ResetPasswordRequest struct {
 }
 
 // ForgotPassword 忘记密码，发送重置邮件
+// @Summary 忘记密码
+// @Description 向注册邮箱发送密码重置邮件，无论邮箱是否注册或是否超过限流均返回相同提示
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param body body ForgotPasswordRequest true "注册邮箱"
+// @Success 200 {object} response.Response
+// @Router /api/auth/forgotPassword [post]
 func (h *EmailHandler) ForgotPassword(c fiber.Ctx) error {
 	var req ForgotPasswordRequest
 	if err := c.Bind().Body(&req); err != nil {
@@ -41,6 +53,12 @@ func (h *EmailHandler) ForgotPassword(c fiber.Ctx) error {
 	if req.Email == "" {
 		return response.Fail(c, "参数错误: 邮箱不能为空")
 	}
+	c.Locals("auditTarget", req.Email)
+
+	// 超过限制时同样返回通用提示，不发送邮件，避免被用来轰炸邮箱或探测邮箱是否注册
+	if !h.emailService.AllowEmailAction(service.EmailActionForgotPassword, req.Email, c.IP()) {
+		return response.SuccessWithMessage(c, "如果该邮箱已注册，您将收到密码重置邮件", nil)
+	}
 
 	// 根据邮箱查找用户
 	user, err := h.userService.GetUserByEmail(req.Email)
@@ -58,6 +76,15 @@ func (h *EmailHandler) ForgotPassword(c fiber.Ctx) error {
 }
 
 // ResetPassword 重置密码
+// @Summary 重置密码
+// @Description 使用重置邮件中的 token 设置新密码
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param body body ResetPasswordRequest true "重置令牌与新密码"
+// @Success 200 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/auth/resetPassword [post]
 func (h *EmailHandler) ResetPassword(c fiber.Ctx) error {
 	var req ResetPasswordRequest
 	if err := c.Bind().Body(&req); err != nil {
@@ -71,6 +98,11 @@ func (h *EmailHandler) ResetPassword(c fiber.Ctx) error {
 		return response.Fail(c, "参数错误: 密码长度必须在6-20位之间")
 	}
 
+	// 按IP限制重置次数，防止暴力猜测 token
+	if !h.emailService.AllowEmailAction(service.EmailActionResetPassword, "", c.IP()) {
+		return response.TooManyRequests(c, "请求过于频繁，请稍后再试")
+	}
+
 	// 验证 token
 	userID, err := h.emailService.VerifyResetToken(req.Token)
 	if err != nil {
@@ -83,10 +115,138 @@ func (h *EmailHandler) ResetPassword(c fiber.Ctx) error {
 	}
 
 	// 删除已使用的 token
-	h.emailService.DeleteResetToken(req.Token)
+	h.emailService.DeleteResetToken(req.Token, userID)
+
+	// 密码已重置，其他设备上的登录全部失效
+	if err := h.userService.LogoutAll(userID); err != nil {
+		logger.Warn("重置密码后撤销会话失败", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+	}
 
 	// 记录审计日志
 	h.auditService.LogSuccess(c, model.ActionResetPassword, model.ModuleAuth, "", "用户通过邮件重置密码")
 
 	return response.SuccessWithMessage(c, "密码重置成功", nil)
 }
+
+// VerifyEmail 验证邮箱，激活待验证的账号
+// @Summary 验证邮箱
+// @Tags 认证
+// @Produce json
+// @Param token query string true "验证邮件中的 token"
+// @Success 200 {object} response.Response
+// @Router /api/auth/verifyEmail [get]
+func (h *EmailHandler) VerifyEmail(c fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return response.Fail(c, "参数错误: token不能为空")
+	}
+
+	userID, err := h.emailService.VerifyEmailToken(token)
+	if err != nil {
+		return response.Fail(c, err.Error())
+	}
+
+	user, err := h.userService.VerifyEmail(userID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	// 删除已使用的 token
+	h.emailService.DeleteEmailToken(token, user.ID)
+
+	c.Locals("userID", user.ID)
+	c.Locals("username", user.Username)
+	h.auditService.LogSuccess(c, model.ActionVerifyEmail, model.ModuleAuth, user.Username, "用户验证邮箱")
+
+	return response.SuccessWithMessage(c, "邮箱验证成功", nil)
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResendVerification 重新发送邮箱验证邮件
+// @Summary 重新发送验证邮件
+// @Description 邮箱已注册且未验证时重新发送验证邮件，之前的验证链接失效；无论邮箱是否注册或是否超过限流均返回相同提示
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param body body ResendVerificationRequest true "注册邮箱"
+// @Success 200 {object} response.Response
+// @Router /api/auth/resendVerification [post]
+func (h *EmailHandler) ResendVerification(c fiber.Ctx) error {
+	const message = "如果该邮箱已注册且未验证，您将收到验证邮件"
+
+	var req ResendVerificationRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return response.Fail(c, "参数错误: "+err.Error())
+	}
+
+	if req.Email == "" {
+		return response.Fail(c, "参数错误: 邮箱不能为空")
+	}
+	c.Locals("auditTarget", req.Email)
+
+	// 超过限制时同样返回通用提示，不发送邮件
+	if !h.emailService.AllowEmailAction(service.EmailActionResendVerification, req.Email, c.IP()) {
+		return response.SuccessWithMessage(c, message, nil)
+	}
+
+	// 为了安全，不暴露用户是否存在或是否已验证
+	user, err := h.userService.GetUserByEmail(req.Email)
+	if err != nil || user.EmailVerifiedAt != nil || user.Status == model.UserStatusDisabled {
+		return response.SuccessWithMessage(c, message, nil)
+	}
+
+	if err := h.emailService.SendVerificationEmail(user.Email, user.Username, user.ID); err != nil {
+		return response.Fail(c, "发送邮件失败，请稍后重试")
+	}
+
+	return response.SuccessWithMessage(c, message, nil)
+}
+
+// AdminGetTokenStats 统计未过期的邮件令牌(管理员)
+// @Summary 邮件令牌统计
+// @Description 使用 SCAN 统计未过期的密码重置和邮箱验证令牌数
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=service.EmailTokenStats}
+// @Router /api/admin/user/tokens [get]
+func (h *EmailHandler) AdminGetTokenStats(c fiber.Ctx) error {
+	stats, err := h.emailService.CountTokens(c.Context())
+	if err != nil {
+		return response.Error(c, err)
+	}
+	return response.Success(c, stats)
+}
+
+type AdminPurgeTokensRequest struct {
+	Type string `json:"type" validate:"omitempty,oneof=password_reset email_verify"` // 令牌类型，为空时清除全部
+}
+
+// AdminPurgeTokens 清除未过期的邮件令牌(管理员)
+// @Summary 清除邮件令牌
+// @Description 使已发出的密码重置或邮箱验证链接全部失效，type 为空时清除全部类型，返回清除的令牌数
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AdminPurgeTokensRequest true "令牌类型"
+// @Success 200 {object} response.Response{data=service.EmailTokenStats}
+// @Router /api/admin/user/tokens/purge [post]
+func (h *EmailHandler) AdminPurgeTokens(c fiber.Ctx) error {
+	var req AdminPurgeTokensRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+	if req.Type != "" {
+		c.Locals("auditTarget", req.Type)
+	}
+
+	stats, err := h.emailService.PurgeTokens(c.Context(), req.Type)
+	if err != nil {
+		return response.Error(c, err)
+	}
+	return response.SuccessWithMessage(c, "令牌已清除", stats)
+}
@@ -2,54 +2,222 @@ package handler
 
 import (
 	"context"
+	"goboot/config"
+	"goboot/internal/service"
 	"goboot/pkg/database"
+	"goboot/pkg/utils"
+	"goboot/pkg/version"
+	"math"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 )
 
+// defaultMinFreeDisk 本地存储磁盘可用空间的默认告警阈值(百分比)
+const defaultMinFreeDisk = 10
+
 type HealthStatus struct {
-	Status string            `json:"status"`
-	Checks map[string]string `json:"checks"`
+	Status  string                      `json:"status"` // ok / degraded / error，只有 error 返回 503
+	Checks  map[string]string           `json:"checks,omitempty"`
+	Details map[string]DependencyDetail `json:"details,omitempty"` // 各依赖的响应耗时和连接池状态，键与 checks 相同
+	Disk    *DiskStatus                 `json:"disk,omitempty"`    // 本地存储所在磁盘的空间，仅使用本地存储时返回
 }
 
-// HealthCheck 健康检查接口，检查 MySQL 和 Redis 连接状态
-func HealthCheck(c fiber.Ctx) error {
-	status := HealthStatus{
-		Status: "ok",
-		Checks: make(map[string]string),
-	}
-	httpStatus := fiber.StatusOK
+// DiskStatus 磁盘空间状态
+type DiskStatus struct {
+	Path           string  `json:"path"`           // 实际统计的目录
+	TotalBytes     uint64  `json:"totalBytes"`     // 总空间(字节)
+	FreeBytes      uint64  `json:"freeBytes"`      // 可用空间(字节)
+	FreePercent    float64 `json:"freePercent"`    // 可用空间百分比
+	MinFreePercent float64 `json:"minFreePercent"` // 告警阈值(百分比)
+}
+
+// DependencyDetail 依赖检查详情
+type DependencyDetail struct {
+	LatencyMs float64 `json:"latencyMs"`      // Ping 耗时(毫秒)
+	Pool      any     `json:"pool,omitempty"` // 连接池状态，数据库为 DBPoolStats，Redis 为 RedisPoolStats
+}
+
+// DBPoolStats 数据库连接池状态
+type DBPoolStats struct {
+	MaxOpen        int   `json:"maxOpen"`        // 最大连接数，0 表示不限制
+	Open           int   `json:"open"`           // 当前连接数(使用中+空闲)
+	InUse          int   `json:"inUse"`          // 使用中的连接数
+	Idle           int   `json:"idle"`           // 空闲连接数
+	WaitCount      int64 `json:"waitCount"`      // 等待可用连接的累计次数
+	WaitDurationMs int64 `json:"waitDurationMs"` // 等待可用连接的累计耗时(毫秒)
+}
+
+// RedisPoolStats Redis 连接池状态
+type RedisPoolStats struct {
+	TotalConns uint32 `json:"totalConns"` // 当前连接数
+	IdleConns  uint32 `json:"idleConns"`  // 空闲连接数
+	StaleConns uint32 `json:"staleConns"` // 已移除的过期连接数
+	Hits       uint32 `json:"hits"`       // 从池中取到空闲连接的次数
+	Misses     uint32 `json:"misses"`     // 池中没有空闲连接的次数
+	Timeouts   uint32 `json:"timeouts"`   // 等待连接超时的次数
+}
+
+// HealthLive 存活探针，只要进程能处理请求即返回 200，不检查外部依赖，
+// 避免 MySQL/Redis 短暂故障导致容器被重启
+// @Summary 存活探针
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} HealthStatus
+// @Router /health/live [get]
+func HealthLive(c fiber.Ctx) error {
+	return c.JSON(HealthStatus{Status: "ok"})
+}
 
+// HealthCheck 就绪探针，检查数据库和 Redis 连接状态，任一失败返回 503；
+// 使用本地存储时检查磁盘剩余空间，空间不足只标记为 degraded，仍返回 200
+// @Summary 就绪探针
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} HealthStatus
+// @Failure 503 {object} HealthStatus
+// @Router /health [get]
+func HealthCheck(c fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	// 检查 MySQL
+	status := checkDependencies(ctx)
+	httpStatus := fiber.StatusOK
+	if status.Status == "error" {
+		httpStatus = fiber.StatusServiceUnavailable
+	}
+
+	return c.Status(httpStatus).JSON(status)
+}
+
+// checkDependencies 检查外部依赖的连接状态，并记录 Ping 耗时和连接池状态
+func checkDependencies(ctx context.Context) HealthStatus {
+	status := HealthStatus{
+		Status:  "ok",
+		Checks:  make(map[string]string),
+		Details: make(map[string]DependencyDetail),
+	}
+
+	// 检查数据库(键名为驱动名: mysql/sqlite)
+	driver := database.Driver()
+	start := time.Now()
 	sqlDB, err := database.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
 	if err != nil {
-		status.Checks["mysql"] = "error: " + err.Error()
-		status.Status = "error"
-		httpStatus = fiber.StatusServiceUnavailable
-	} else if err := sqlDB.PingContext(ctx); err != nil {
-		status.Checks["mysql"] = "error: " + err.Error()
+		status.Checks[driver] = "error: " + err.Error()
 		status.Status = "error"
-		httpStatus = fiber.StatusServiceUnavailable
 	} else {
-		status.Checks["mysql"] = "ok"
+		status.Checks[driver] = "ok"
 	}
+	detail := DependencyDetail{LatencyMs: elapsedMs(start)}
+	if sqlDB != nil {
+		stats := sqlDB.Stats()
+		detail.Pool = DBPoolStats{
+			MaxOpen:        stats.MaxOpenConnections,
+			Open:           stats.OpenConnections,
+			InUse:          stats.InUse,
+			Idle:           stats.Idle,
+			WaitCount:      stats.WaitCount,
+			WaitDurationMs: stats.WaitDuration.Milliseconds(),
+		}
+	}
+	status.Details[driver] = detail
 
 	// 检查 Redis
+	start = time.Now()
 	if err := database.RDB.Ping(ctx).Err(); err != nil {
 		status.Checks["redis"] = "error: " + err.Error()
 		status.Status = "error"
-		httpStatus = fiber.StatusServiceUnavailable
 	} else {
 		status.Checks["redis"] = "ok"
 	}
+	stats := database.RDB.PoolStats()
+	status.Details["redis"] = DependencyDetail{
+		LatencyMs: elapsedMs(start),
+		Pool: RedisPoolStats{
+			TotalConns: stats.TotalConns,
+			IdleConns:  stats.IdleConns,
+			StaleConns: stats.StaleConns,
+			Hits:       stats.Hits,
+			Misses:     stats.Misses,
+			Timeouts:   stats.Timeouts,
+		},
+	}
 
-	return c.Status(httpStatus).JSON(status)
+	checkDisk(&status)
+
+	return status
+}
+
+// checkDisk 使用本地存储时检查上传目录所在磁盘的剩余空间，不足或无法统计时标记为 degraded
+func checkDisk(status *HealthStatus) {
+	upload := service.GetConfigService().GetUploadConfig()
+	if !upload.Enabled || (upload.StorageType != "" && upload.StorageType != "local") {
+		return
+	}
+
+	localPath := upload.LocalPath
+	if localPath == "" {
+		localPath = "./uploads"
+	}
+	minFree := config.Get().Upload.MinFreeDisk
+	if minFree <= 0 {
+		minFree = defaultMinFreeDisk
+	}
+
+	usage, err := utils.GetDiskUsage(localPath)
+	if err != nil {
+		status.Checks["disk"] = "error: " + err.Error()
+		degrade(status)
+		return
+	}
+
+	freePercent := math.Round(usage.FreePercent()*100) / 100
+	status.Disk = &DiskStatus{
+		Path:           usage.Path,
+		TotalBytes:     usage.Total,
+		FreeBytes:      usage.Free,
+		FreePercent:    freePercent,
+		MinFreePercent: minFree,
+	}
+	if freePercent < minFree {
+		status.Checks["disk"] = "low"
+		degrade(status)
+	} else {
+		status.Checks["disk"] = "ok"
+	}
+}
+
+// degrade 将状态降级为 degraded，已经是 error 时保持不变
+func degrade(status *HealthStatus) {
+	if status.Status == "ok" {
+		status.Status = "degraded"
+	}
+}
+
+// elapsedMs 返回从 start 到现在的耗时(毫秒，保留两位小数)
+func elapsedMs(start time.Time) float64 {
+	return math.Round(float64(time.Since(start).Microseconds())/10) / 100
+}
+
+// Version 获取当前运行的构建信息
+// @Summary 构建信息
+// @Tags 健康检查
+// @Produce json
+// @Success 200 {object} version.Info
+// @Router /version [get]
+func Version(c fiber.Ctx) error {
+	return c.JSON(version.Get())
 }
 
+// Ping 连通性检查
+// @Summary 连通性检查
+// @Tags 健康检查
+// @Produce plain
+// @Success 200 {string} string "pong"
+// @Router /ping [get]
 func Ping(c fiber.Ctx) error {
 	return c.SendString("pong")
 }
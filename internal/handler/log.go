@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"fmt"
+	"goboot/internal/model"
+	"goboot/internal/service"
+	"goboot/pkg/logger"
+	"goboot/pkg/response"
+	"goboot/pkg/validator"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type LogHandler struct {
+	auditService *service.AuditService
+}
+
+func NewLogHandler() *LogHandler {
+	return &LogHandler{
+		auditService: service.NewAuditService(),
+	}
+}
+
+type SetLogLevelRequest struct {
+	Level string `json:"level" validate:"required,oneof=debug info warn error" label:"日志级别"`
+}
+
+// LogLevelResponse 当前日志级别
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// GetLogLevel 获取当前日志级别
+// @Summary 获取日志级别
+// @Tags 系统
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=LogLevelResponse}
+// @Router /api/admin/log/level [get]
+func (h *LogHandler) GetLogLevel(c fiber.Ctx) error {
+	return response.Success(c, LogLevelResponse{Level: logger.GetLevel()})
+}
+
+// SetLogLevel 运行时调整日志级别，无需重启，重启后恢复为配置文件中的级别
+// @Summary 调整日志级别
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body SetLogLevelRequest true "日志级别"
+// @Success 200 {object} response.Response{data=LogLevelResponse}
+// @Router /api/admin/log/level [post]
+func (h *LogHandler) SetLogLevel(c fiber.Ctx) error {
+	var req SetLogLevelRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	old := logger.GetLevel()
+	if err := logger.SetLevel(req.Level); err != nil {
+		h.auditService.LogFail(c, model.ActionUpdate, model.ModuleSystem, "log_level", err.Error())
+		return response.Fail(c, err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionUpdate, model.ModuleSystem, "log_level", fmt.Sprintf("日志级别: %s -> %s", old, req.Level))
+	return response.SuccessWithMessage(c, "日志级别已更新", LogLevelResponse{Level: logger.GetLevel()})
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"goboot/internal/model"
+	"goboot/internal/service"
+	"goboot/pkg/response"
+	"goboot/pkg/validator"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type MaintenanceHandler struct {
+	configService *service.ConfigService
+	auditService  *service.AuditService
+}
+
+func NewMaintenanceHandler() *MaintenanceHandler {
+	return &MaintenanceHandler{
+		configService: service.GetConfigService(),
+		auditService:  service.NewAuditService(),
+	}
+}
+
+type SetMaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message" validate:"max=255" label:"维护提示"` // 为空时保留原提示信息
+}
+
+// GetMaintenance 获取维护模式状态
+// @Summary 获取维护模式状态
+// @Tags 系统
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=service.MaintenanceStatus}
+// @Router /api/admin/maintenance [get]
+func (h *MaintenanceHandler) GetMaintenance(c fiber.Ctx) error {
+	return response.Success(c, h.configService.GetMaintenance())
+}
+
+// SetMaintenance 开启或关闭维护模式
+// @Summary 切换维护模式
+// @Description 开启后除健康检查、登录和管理接口外的请求均返回 503，无需重启服务
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body SetMaintenanceRequest true "维护模式开关"
+// @Success 200 {object} response.Response{data=service.MaintenanceStatus}
+// @Router /api/admin/maintenance [post]
+func (h *MaintenanceHandler) SetMaintenance(c fiber.Ctx) error {
+	var req SetMaintenanceRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	status, err := h.configService.SetMaintenance(req.Enabled, req.Message, currentUsername(c))
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionUpdate, model.ModuleSystem, "maintenance", err.Error())
+		return response.Fail(c, err.Error())
+	}
+
+	detail := "关闭维护模式"
+	if status.Enabled {
+		detail = "开启维护模式: " + status.Message
+	}
+	h.auditService.LogSuccess(c, model.ActionUpdate, model.ModuleSystem, "maintenance", detail)
+	return response.SuccessWithMessage(c, detail, status)
+}
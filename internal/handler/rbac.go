@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"fmt"
+	"goboot/internal/model"
+	"goboot/internal/service"
+	"goboot/pkg/response"
+	"goboot/pkg/validator"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type RBACHandler struct {
+	rbacService  *service.RBACService
+	auditService *service.AuditService
+}
+
+func NewRBACHandler() *RBACHandler {
+	return &RBACHandler{
+		rbacService:  service.NewRBACService(),
+		auditService: service.NewAuditService(),
+	}
+}
+
+type CreateRoleRequest struct {
+	Code          string `json:"code" validate:"required,max=64,username" label:"角色标识"`
+	Name          string `json:"name" validate:"required,max=64" label:"角色名称"`
+	Remark        string `json:"remark" validate:"max=255" label:"备注"`
+	PermissionIDs []uint `json:"permissionIds" label:"权限"`
+}
+
+type UpdateRoleRequest struct {
+	ID            uint   `json:"id" validate:"required" label:"角色ID"`
+	Name          string `json:"name" validate:"required,max=64" label:"角色名称"`
+	Remark        string `json:"remark" validate:"max=255" label:"备注"`
+	PermissionIDs []uint `json:"permissionIds" label:"权限"`
+}
+
+type RoleIDRequest struct {
+	ID uint `json:"id" validate:"required" label:"角色ID"`
+}
+
+type AssignUserRolesRequest struct {
+	UserID  uint   `json:"userId" validate:"required" label:"用户ID"`
+	RoleIDs []uint `json:"roleIds" label:"角色"`
+}
+
+// GetPermissions 获取所有权限
+// @Summary 权限列表
+// @Tags 角色权限
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.Permission}
+// @Router /api/admin/role/permissions [get]
+func (h *RBACHandler) GetPermissions(c fiber.Ctx) error {
+	perms, err := h.rbacService.GetPermissions()
+	if err != nil {
+		return response.Fail(c, "获取权限列表失败")
+	}
+	return response.Success(c, perms)
+}
+
+// GetRoles 获取所有角色
+// @Summary 角色列表
+// @Tags 角色权限
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.Role}
+// @Router /api/admin/role/list [get]
+func (h *RBACHandler) GetRoles(c fiber.Ctx) error {
+	roles, err := h.rbacService.GetRoles()
+	if err != nil {
+		return response.Fail(c, "获取角色列表失败")
+	}
+	return response.Success(c, roles)
+}
+
+// CreateRole 创建角色
+// @Summary 创建角色
+// @Tags 角色权限
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateRoleRequest true "角色信息"
+// @Success 200 {object} response.Response{data=model.Role}
+// @Router /api/admin/role/add [post]
+func (h *RBACHandler) CreateRole(c fiber.Ctx) error {
+	var req CreateRoleRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	role, err := h.rbacService.CreateRole(req.Code, req.Name, req.Remark, req.PermissionIDs, h.isSuperAdmin(c))
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionCreate, model.ModuleRole, req.Code, err.Error())
+		return response.Fail(c, err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionCreate, model.ModuleRole, req.Code, "创建角色: "+req.Code)
+	return response.SuccessWithMessage(c, "创建成功", role)
+}
+
+// UpdateRole 更新角色
+// @Summary 更新角色
+// @Tags 角色权限
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body UpdateRoleRequest true "角色信息"
+// @Success 200 {object} response.Response{data=model.Role}
+// @Router /api/admin/role/update [post]
+func (h *RBACHandler) UpdateRole(c fiber.Ctx) error {
+	var req UpdateRoleRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	role, err := h.rbacService.UpdateRole(req.ID, req.Name, req.Remark, req.PermissionIDs, h.isSuperAdmin(c))
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionUpdate, model.ModuleRole, fmt.Sprintf("%d", req.ID), err.Error())
+		return response.Fail(c, err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionUpdate, model.ModuleRole, fmt.Sprintf("%d", req.ID), "更新角色: "+role.Code)
+	return response.SuccessWithMessage(c, "更新成功", role)
+}
+
+// DeleteRole 删除角色
+// @Summary 删除角色
+// @Tags 角色权限
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body RoleIDRequest true "角色ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/role/delete [post]
+func (h *RBACHandler) DeleteRole(c fiber.Ctx) error {
+	var req RoleIDRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.rbacService.DeleteRole(req.ID); err != nil {
+		h.auditService.LogFail(c, model.ActionDelete, model.ModuleRole, fmt.Sprintf("%d", req.ID), err.Error())
+		return response.Fail(c, err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionDelete, model.ModuleRole, fmt.Sprintf("%d", req.ID), fmt.Sprintf("删除角色ID: %d", req.ID))
+	return response.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// GetUserRoles 获取用户的角色
+// @Summary 获取用户的角色
+// @Tags 角色权限
+// @Produce json
+// @Security BearerAuth
+// @Param userId query int true "用户ID"
+// @Success 200 {object} response.Response{data=[]model.Role}
+// @Router /api/admin/role/user [get]
+func (h *RBACHandler) GetUserRoles(c fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Query("userId"), 10, 32)
+	if err != nil || id == 0 {
+		return response.Fail(c, "参数错误: userId必须为有效数字")
+	}
+
+	roles, err := h.rbacService.GetUserRoles(uint(id))
+	if err != nil {
+		return response.Fail(c, err.Error())
+	}
+	return response.Success(c, roles)
+}
+
+// AssignUserRoles 分配用户角色(覆盖原有角色)
+// @Summary 分配用户角色
+// @Tags 角色权限
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AssignUserRolesRequest true "用户ID与角色ID列表"
+// @Success 200 {object} response.Response
+// @Router /api/admin/role/assign [post]
+func (h *RBACHandler) AssignUserRoles(c fiber.Ctx) error {
+	var req AssignUserRolesRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	target := fmt.Sprintf("%d", req.UserID)
+	if err := h.rbacService.AssignUserRoles(req.UserID, req.RoleIDs, h.isSuperAdmin(c)); err != nil {
+		h.auditService.LogFail(c, model.ActionAssignRoles, model.ModuleRole, target, err.Error())
+		return response.Fail(c, err.Error())
+	}
+
+	h.auditService.LogSuccess(c, model.ActionAssignRoles, model.ModuleRole, target, fmt.Sprintf("分配用户角色: %v", req.RoleIDs))
+	return response.SuccessWithMessage(c, "分配成功", nil)
+}
+
+// isSuperAdmin 当前用户是否为超级管理员
+func (h *RBACHandler) isSuperAdmin(c fiber.Ctx) bool {
+	userID, _ := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(int8)
+	return h.rbacService.IsSuperAdmin(userID, role)
+}
+
+// GetMyPermissions 获取当前用户的有效权限
+// @Summary 当前用户的有效权限
+// @Tags 用户
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]string}
+// @Router /api/user/permissions [get]
+func (h *RBACHandler) GetMyPermissions(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(int8)
+
+	perms, err := h.rbacService.GetUserPermissions(userID, role)
+	if err != nil {
+		return response.Fail(c, err.Error())
+	}
+	return response.Success(c, perms)
+}
@@ -1,6 +1,11 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
 	"goboot/internal/model"
 	"goboot/internal/service"
 	"goboot/pkg/response"
@@ -11,12 +16,14 @@ import (
 type UploadHandler struct {
 	uploadService *service.UploadService
 	auditService  *service.AuditService
+	rbacService   *service.RBACService
 }
 
 func NewUploadHandler() *UploadHandler {
 	return &UploadHandler{
 		uploadService: service.NewUploadService(),
 		auditService:  service.NewAuditService(),
+		rbacService:   service.NewRBACService(),
 	}
 }
 
@@ -26,11 +33,14 @@ func NewUploadHandler() *UploadHandler {
 // @Tags 文件上传
 // @Accept multipart/form-data
 // @Produce json
-// @Param file formance file true "上传的文件"
-// @Param category formance string false "文件分类目录"
+// @Security BearerAuth
+// @Param file formData file true "上传的文件"
+// @Param category formData string false "文件分类目录，只能包含字母、数字、下划线和短横线，默认 files"
 // @Success 200 {object} response.Response{data=service.FileInfo}
 // @Router /api/upload/file [post]
 func (h *UploadHandler) UploadFile(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
 	// 获取上传的文件
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -41,7 +51,7 @@ func (h *UploadHandler) UploadFile(c fiber.Ctx) error {
 	category := c.FormValue("category", "files")
 
 	// 上传文件
-	fileInfo, err := h.uploadService.UploadFile(file, category)
+	fileInfo, err := h.uploadService.UploadFile(userID, file, category)
 	if err != nil {
 		h.auditService.LogFail(c, model.ActionUpload, model.ModuleFile, file.Filename, err.Error())
 		return response.Fail(c, err.Error())
@@ -59,11 +69,14 @@ func (h *UploadHandler) UploadFile(c fiber.Ctx) error {
 // @Tags 文件上传
 // @Accept multipart/form-data
 // @Produce json
+// @Security BearerAuth
 // @Param file formData file true "上传的图片"
-// @Param category formData string false "图片分类目录"
+// @Param category formData string false "图片分类目录，只能包含字母、数字、下划线和短横线，默认 images"
 // @Success 200 {object} response.Response{data=service.FileInfo}
 // @Router /api/upload/image [post]
 func (h *UploadHandler) UploadImage(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
 	// 获取上传的文件
 	file, err := c.FormFile("file")
 	if err != nil {
@@ -74,7 +87,7 @@ func (h *UploadHandler) UploadImage(c fiber.Ctx) error {
 	category := c.FormValue("category", "images")
 
 	// 上传图片
-	fileInfo, err := h.uploadService.UploadImage(file, category)
+	fileInfo, err := h.uploadService.UploadImage(userID, file, category)
 	if err != nil {
 		h.auditService.LogFail(c, model.ActionUpload, model.ModuleFile, file.Filename, err.Error())
 		return response.Fail(c, err.Error())
@@ -88,15 +101,18 @@ func (h *UploadHandler) UploadImage(c fiber.Ctx) error {
 
 // UploadFiles 批量上传文件
 // @Summary 批量上传文件
-// @Description 同时上传多个文件
+// @Description 同时上传多个文件，全部成功时 code=0，部分失败时 code=2，全部失败时 code=1；失败原因按原始文件名返回
 // @Tags 文件上传
 // @Accept multipart/form-data
 // @Produce json
+// @Security BearerAuth
 // @Param files formData file true "上传的文件列表"
-// @Param category formData string false "文件分类目录"
+// @Param category formData string false "文件分类目录，只能包含字母、数字、下划线和短横线，默认 files"
 // @Success 200 {object} response.Response{data=UploadFilesResponse}
 // @Router /api/upload/files [post]
 func (h *UploadHandler) UploadFiles(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
 	// 获取表单
 	form, err := c.MultipartForm()
 	if err != nil {
@@ -113,38 +129,45 @@ func (h *UploadHandler) UploadFiles(c fiber.Ctx) error {
 	category := c.FormValue("category", "files")
 
 	// 批量上传
-	results, errs := h.uploadService.UploadFiles(files, category)
-
-	// 构建错误信息
-	var errMsgs []string
-	for _, e := range errs {
-		errMsgs = append(errMsgs, e.Error())
-	}
+	results, errs := h.uploadService.UploadFiles(userID, files, category)
 
 	// 记录审计日志
 	if len(results) > 0 {
 		h.auditService.LogSuccess(c, model.ActionUpload, model.ModuleFile, "",
-			"批量上传成功"+string(rune(len(results)))+"个文件")
+			fmt.Sprintf("批量上传成功%d个文件", len(results)))
 	}
 
-	return response.Success(c, fiber.Map{
-		"success": results,
-		"errors":  errMsgs,
-		"total":   len(files),
-		"failed":  len(errs),
-	})
+	resp := UploadFilesResponse{
+		Success: results,
+		Errors:  errs,
+		Total:   len(files),
+		Failed:  len(files) - len(results),
+	}
+	switch {
+	case resp.Failed == 0:
+		return response.Success(c, resp)
+	case len(results) == 0:
+		return response.Result(c, response.ERROR, "文件上传失败", resp)
+	default:
+		return response.PartialSuccess(c, fmt.Sprintf("部分文件上传失败，成功%d个，失败%d个", len(results), resp.Failed), resp)
+	}
 }
 
 // DeleteFile 删除文件
 // @Summary 删除文件
-// @Description 根据路径删除文件
+// @Description 根据路径删除当前用户上传的文件，开启去重时其他用户仍引用该文件则只删除当前用户的记录
 // @Tags 文件上传
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param body body DeleteFileRequest true "删除文件请求"
 // @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Router /api/upload/delete [post]
 func (h *UploadHandler) DeleteFile(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(int8)
+
 	var req DeleteFileRequest
 	if err := c.Bind().Body(&req); err != nil {
 		return response.Fail(c, "参数错误: "+err.Error())
@@ -155,8 +178,11 @@ func (h *UploadHandler) DeleteFile(c fiber.Ctx) error {
 	}
 
 	// 删除文件
-	if err := h.uploadService.DeleteFile(req.Path); err != nil {
+	if err := h.uploadService.DeleteFile(userID, h.rbacService.IsSuperAdmin(userID, role), req.Path); err != nil {
 		h.auditService.LogFail(c, model.ActionDelete, model.ModuleFile, req.Path, err.Error())
+		if errors.Is(err, service.ErrFileForbidden) {
+			return response.Forbidden(c, err.Error())
+		}
 		return response.Fail(c, "删除文件失败: "+err.Error())
 	}
 
@@ -168,21 +194,29 @@ func (h *UploadHandler) DeleteFile(c fiber.Ctx) error {
 
 // GetFileInfo 获取文件信息
 // @Summary 获取文件信息
-// @Description 根据路径获取文件信息
+// @Description 根据路径获取当前用户上传的文件信息，超级管理员可查看全部文件
 // @Tags 文件上传
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param path query string true "文件路径"
 // @Success 200 {object} response.Response{data=service.FileInfo}
+// @Failure 403 {object} response.Response
 // @Router /api/upload/info [get]
 func (h *UploadHandler) GetFileInfo(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(int8)
+
 	path := c.Query("path")
 	if path == "" {
 		return response.Fail(c, "文件路径不能为空")
 	}
 
 	// 获取文件信息
-	info, err := h.uploadService.GetFileInfo(path)
+	info, err := h.uploadService.GetFileInfo(userID, h.rbacService.IsSuperAdmin(userID, role), path)
+	if errors.Is(err, service.ErrFileForbidden) {
+		return response.Forbidden(c, err.Error())
+	}
 	if err != nil {
 		return response.Fail(c, err.Error())
 	}
@@ -190,7 +224,114 @@ func (h *UploadHandler) GetFileInfo(c fiber.Ctx) error {
 	return response.Success(c, info)
 }
 
+// Download 下载文件
+// @Summary 下载文件
+// @Description 下载当前用户上传的文件，超级管理员可下载全部文件；公开目录(public)下的文件也可通过 /uploads/public/* 直接访问
+// @Tags 文件上传
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param path query string true "文件路径"
+// @Success 200 {file} file
+// @Failure 403 {object} response.Response
+// @Router /api/upload/download [get]
+func (h *UploadHandler) Download(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(int8)
+
+	path := c.Query("path")
+	if path == "" {
+		return response.Fail(c, "文件路径不能为空")
+	}
+
+	reader, info, err := h.uploadService.OpenFile(userID, h.rbacService.IsSuperAdmin(userID, role), path)
+	if errors.Is(err, service.ErrFileForbidden) {
+		return response.Forbidden(c, err.Error())
+	}
+	if err != nil {
+		return response.Fail(c, err.Error())
+	}
+	return sendFile(c, reader, info)
+}
+
+// PresignURL 生成签名下载链接
+// @Summary 生成签名下载链接
+// @Description 为当前用户上传的文件(超级管理员为任意文件)生成限时有效的下载链接，无需携带 Token 即可下载；本地存储指向 /api/upload/signed，对象存储返回原生预签名URL
+// @Tags 文件上传
+// @Produce json
+// @Security BearerAuth
+// @Param path query string true "文件路径"
+// @Param expire query int false "有效期(秒)，默认 300，最长 86400"
+// @Success 200 {object} response.Response{data=PresignURLResponse}
+// @Failure 403 {object} response.Response
+// @Router /api/upload/presign [get]
+func (h *UploadHandler) PresignURL(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	role, _ := c.Locals("role").(int8)
+
+	path := c.Query("path")
+	if path == "" {
+		return response.Fail(c, "文件路径不能为空")
+	}
+	expire := fiber.Query[int](c, "expire")
+
+	signedURL, expiresAt, err := h.uploadService.PresignURL(userID, h.rbacService.IsSuperAdmin(userID, role), path, time.Duration(expire)*time.Second)
+	if errors.Is(err, service.ErrFileForbidden) {
+		return response.Forbidden(c, err.Error())
+	}
+	if err != nil {
+		return response.Fail(c, err.Error())
+	}
+
+	return response.Success(c, PresignURLResponse{
+		URL:       signedURL,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// SignedDownload 通过签名链接下载文件
+// @Summary 签名链接下载文件
+// @Description 校验 /api/upload/presign 生成的本地存储签名链接并下载文件，无需登录
+// @Tags 文件上传
+// @Produce octet-stream
+// @Param path query string true "文件路径"
+// @Param expires query int true "过期时间戳(秒)"
+// @Param sign query string true "签名"
+// @Success 200 {file} file
+// @Failure 403 {object} response.Response
+// @Router /api/upload/signed [get]
+func (h *UploadHandler) SignedDownload(c fiber.Ctx) error {
+	reader, info, err := h.uploadService.OpenPresigned(c.Query("path"), fiber.Query[int64](c, "expires"), c.Query("sign"))
+	if errors.Is(err, service.ErrPresignInvalid) {
+		return response.Forbidden(c, err.Error())
+	}
+	if err != nil {
+		return response.Fail(c, err.Error())
+	}
+	return sendFile(c, reader, info)
+}
+
+// sendFile 以附件形式返回文件内容，Content-Type 按扩展名确定，不使用上传时客户端提供的类型
+func sendFile(c fiber.Ctx, reader io.Reader, info *service.FileInfo) error {
+	c.Attachment(info.Name)
+	c.Set(fiber.HeaderContentType, info.MimeType)
+	return c.SendStream(reader, int(info.Size))
+}
+
 // DeleteFileRequest 删除文件请求
 type DeleteFileRequest struct {
 	Path string `json:"path" validate:"required"`
 }
+
+// PresignURLResponse 签名下载链接
+type PresignURLResponse struct {
+	URL       string    `json:"url"`       // 下载链接
+	ExpiresAt time.Time `json:"expiresAt"` // 过期时间
+}
+
+// UploadFilesResponse 批量上传结果
+type UploadFilesResponse struct {
+	Success []*service.FileInfo       `json:"success"`          // 上传成功的文件
+	Errors  []service.UploadFileError `json:"errors,omitempty"` // 上传失败的文件及原因，按文件序号排列
+	Total   int                       `json:"total"`            // 文件总数
+	Failed  int                       `json:"failed"`           // 失败数量
+}
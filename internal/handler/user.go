@@ -1,25 +1,35 @@
 package handler
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"goboot/internal/model"
 	"goboot/internal/service"
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
 	"goboot/pkg/response"
 	"goboot/pkg/validator"
+	"log/slog"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 )
 
 type UserHandler struct {
-	userService  *service.UserService
-	auditService *service.AuditService
+	userService   *service.UserService
+	rbacService   *service.RBACService
+	auditService  *service.AuditService
+	uploadService *service.UploadService
 }
 
 func NewUserHandler() *UserHandler {
 	return &UserHandler{
-		userService:  service.NewUserService(),
-		auditService: service.NewAuditService(),
+		userService:   service.NewUserService(),
+		rbacService:   service.NewRBACService(),
+		auditService:  service.NewAuditService(),
+		uploadService: service.NewUploadService(),
 	}
 }
 
@@ -32,10 +42,33 @@ type RegisterRequest struct {
 }
 
 type LoginRequest struct {
-	Username string `json:"username" validate:"required" label:"用户名"`
+	Username string `json:"username" validate:"required" label:"账号"` // 用户名、邮箱或手机号
 	Password string `json:"password" validate:"required" label:"密码"`
 }
 
+// PasswordExpiredResponse 密码已过期时登录接口返回的数据(code=1001)
+type PasswordExpiredResponse struct {
+	ChangeToken string `json:"changeToken"` // 修改过期密码的一次性令牌
+	ExpiresIn   int64  `json:"expiresIn"`   // 令牌有效期(秒)
+}
+
+// LoginResponse 登录成功返回的令牌及用户信息
+type LoginResponse struct {
+	AccessToken  string      `json:"accessToken"`
+	RefreshToken string      `json:"refreshToken"`
+	ExpiresIn    int64       `json:"expiresIn"` // Access Token过期时间(秒)
+	User         *model.User `json:"user"`
+}
+
+// Register 用户注册
+// @Summary 用户注册
+// @Description 邮件服务开启且要求验证邮箱时，账号为待验证状态，需完成邮箱验证后才能登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param body body RegisterRequest true "注册信息"
+// @Success 200 {object} response.Response{data=model.User}
+// @Router /api/auth/register [post]
 func (h *UserHandler) Register(c fiber.Ctx) error {
 	var req RegisterRequest
 	if err := validator.BindAndValidate(c, &req); err != nil {
@@ -45,23 +78,52 @@ func (h *UserHandler) Register(c fiber.Ctx) error {
 	user, err := h.userService.Register(req.Username, req.Password, req.Nickname, req.Phone, req.Email)
 	if err != nil {
 		h.auditService.LogFail(c, model.ActionRegister, model.ModuleAuth, req.Username, err.Error())
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	h.auditService.LogSuccess(c, model.ActionRegister, model.ModuleAuth, req.Username, "用户注册成功")
+	if user.Status == model.UserStatusPending {
+		return response.SuccessWithMessage(c, "注册成功，请前往邮箱完成验证后登录", user)
+	}
 	return response.SuccessWithMessage(c, "注册成功", user)
 }
 
+// Login 用户登录
+// @Summary 用户登录
+// @Description 支持用户名、邮箱或手机号登录，返回 Access Token 和 Refresh Token。
+// @Description 密码已过期时返回 code=1001 及 data=PasswordExpiredResponse，不签发令牌，需调用 /api/auth/changeExpiredPassword 修改密码后重新登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param body body LoginRequest true "登录信息"
+// @Success 200 {object} response.Response{data=LoginResponse}
+// @Router /api/auth/login [post]
 func (h *UserHandler) Login(c fiber.Ctx) error {
 	var req LoginRequest
 	if err := validator.BindAndValidate(c, &req); err != nil {
 		return err
 	}
 
-	tokenPair, user, err := h.userService.Login(req.Username, req.Password)
+	tokenPair, user, err := h.userService.Login(c.Context(), req.Username, req.Password, c.IP(), string(c.Request().Header.UserAgent()))
+	var expiredErr *service.PasswordExpiredError
+	if errors.As(err, &expiredErr) {
+		c.Locals("userID", user.ID)
+		c.Locals("username", user.Username)
+		h.auditService.LogFail(c, model.ActionLogin, model.ModuleAuth, req.Username, err.Error())
+		return response.Result(c, response.PASSWORD_EXPIRED, response.Message(c, response.PASSWORD_EXPIRED, err.Error()), PasswordExpiredResponse{
+			ChangeToken: expiredErr.ChangeToken,
+			ExpiresIn:   int64(expiredErr.ExpiresIn.Seconds()),
+		})
+	}
+	var lockedErr *service.AccountLockedError
+	if errors.As(err, &lockedErr) {
+		h.auditService.LogFail(c, model.ActionLogin, model.ModuleAuth, req.Username, err.Error())
+		c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(int64(lockedErr.RetryAfter.Seconds()), 10))
+		return response.TooManyRequests(c, err.Error())
+	}
 	if err != nil {
 		h.auditService.LogFail(c, model.ActionLogin, model.ModuleAuth, req.Username, err.Error())
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	// 登录成功后设置用户信息用于审计日志
@@ -69,18 +131,59 @@ func (h *UserHandler) Login(c fiber.Ctx) error {
 	c.Locals("username", user.Username)
 	h.auditService.LogSuccess(c, model.ActionLogin, model.ModuleAuth, req.Username, "用户登录成功")
 
-	return response.Success(c, fiber.Map{
-		"accessToken":  tokenPair.AccessToken,
-		"refreshToken": tokenPair.RefreshToken,
-		"expiresIn":    tokenPair.ExpiresIn,
-		"user":         user,
+	return response.Success(c, LoginResponse{
+		AccessToken:  tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+		ExpiresIn:    tokenPair.ExpiresIn,
+		User:         user,
 	})
 }
 
+type ChangeExpiredPasswordRequest struct {
+	ChangeToken string `json:"changeToken" validate:"required" label:"修改密码令牌"`
+	NewPassword string `json:"newPassword" validate:"required,min=6,max=20" label:"新密码"`
+}
+
+// ChangeExpiredPassword 密码过期后使用登录返回的令牌修改密码
+// @Summary 修改过期密码
+// @Description 使用登录接口在密码过期时返回的 changeToken 设置新密码，令牌仅能使用一次，修改后需重新登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param body body ChangeExpiredPasswordRequest true "修改密码令牌与新密码"
+// @Success 200 {object} response.Response
+// @Router /api/auth/changeExpiredPassword [post]
+func (h *UserHandler) ChangeExpiredPassword(c fiber.Ctx) error {
+	var req ChangeExpiredPasswordRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.userService.ChangeExpiredPassword(req.ChangeToken, req.NewPassword)
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionChangePassword, model.ModuleAuth, "", err.Error())
+		return response.Error(c, err)
+	}
+
+	c.Locals("userID", user.ID)
+	c.Locals("username", user.Username)
+	h.auditService.LogSuccess(c, model.ActionChangePassword, model.ModuleAuth, fmt.Sprintf("%d", user.ID), "修改过期密码")
+	return response.SuccessWithMessage(c, "密码修改成功，请重新登录", nil)
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" validate:"required" label:"刷新令牌"`
 }
 
+// RefreshToken 使用 Refresh Token 换取新的双Token
+// @Summary 刷新令牌
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param body body RefreshTokenRequest true "刷新令牌"
+// @Success 200 {object} response.Response{data=utils.TokenPair}
+// @Failure 401 {object} response.Response
+// @Router /api/auth/refreshToken [post]
 func (h *UserHandler) RefreshToken(c fiber.Ctx) error {
 	var req RefreshTokenRequest
 	if err := validator.BindAndValidate(c, &req); err != nil {
@@ -99,43 +202,116 @@ func (h *UserHandler) RefreshToken(c fiber.Ctx) error {
 	})
 }
 
+// GetProfile 获取当前用户信息
+// @Summary 获取个人信息
+// @Tags 用户
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=ProfileResponse}
+// @Router /api/user/profile [get]
 func (h *UserHandler) GetProfile(c fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
-	user, err := h.userService.GetUserByID(userID)
+	user, err := h.userService.GetUserByID(c.Context(), userID)
 	if err != nil {
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
-	return response.Success(c, user)
+	return response.Success(c, ProfileResponse{
+		User:               user,
+		PasswordExpireDays: h.userService.PasswordExpireDays(user),
+	})
+}
+
+// ProfileResponse 个人信息，在用户信息基础上附带密码有效期
+type ProfileResponse struct {
+	*model.User
+	PasswordExpireDays *int `json:"passwordExpireDays"` // 密码距离过期的天数，未开启密码有效期时为 null
 }
 
 type UpdateProfileRequest struct {
-	Nickname string `json:"nickname"`
-	Phone    string `json:"phone"`
-	Email    string `json:"email"`
-	Avatar   string `json:"avatar"`
+	Nickname string `json:"nickname" validate:"omitempty,max=50" label:"昵称"`
+	Phone    string `json:"phone" validate:"omitempty,phone" label:"手机号"`
+	Email    string `json:"email" validate:"omitempty,email,max=100" label:"邮箱"`
+	Avatar   string `json:"avatar" validate:"omitempty,max=255" label:"头像"`
 }
 
+// UpdateProfile 更新当前用户信息
+// @Summary 更新个人信息
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body UpdateProfileRequest true "个人信息"
+// @Success 200 {object} response.Response{data=model.User}
+// @Router /api/user/updateProfile [post]
 func (h *UserHandler) UpdateProfile(c fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
+	c.Locals("auditTarget", fmt.Sprintf("%d", userID))
 	var req UpdateProfileRequest
-	if err := c.Bind().Body(&req); err != nil {
-		return response.Fail(c, "参数错误: "+err.Error())
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
 	}
 
 	user, err := h.userService.UpdateProfile(userID, req.Nickname, req.Phone, req.Email, req.Avatar)
 	if err != nil {
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	return response.Success(c, user)
 }
 
+// UploadAvatar 上传并设置当前用户头像
+// @Summary 上传头像
+// @Description 上传图片作为头像，仅支持图片格式，大小不超过 upload.max_avatar_size(默认 2MB)，返回更新后的个人信息
+// @Tags 用户
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "头像图片"
+// @Success 200 {object} response.Response{data=ProfileResponse}
+// @Router /api/user/avatar [post]
+func (h *UserHandler) UploadAvatar(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	c.Locals("auditTarget", fmt.Sprintf("%d", userID))
+	file, err := c.FormFile("file")
+	if err != nil {
+		return response.Fail(c, "获取上传文件失败: "+err.Error())
+	}
+
+	fileInfo, err := h.uploadService.UploadAvatar(userID, file)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	user, err := h.userService.UpdateAvatar(userID, fileInfo.URL)
+	if err != nil {
+		// 头像未能保存时删除已上传的文件
+		if delErr := h.uploadService.DeleteFile(userID, false, fileInfo.Path); delErr != nil {
+			logger.Warn("删除未使用的头像文件失败", slog.String("path", fileInfo.Path), slog.Any("error", delErr))
+		}
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, ProfileResponse{
+		User:               user,
+		PasswordExpireDays: h.userService.PasswordExpireDays(user),
+	})
+}
+
 type ChangePasswordRequest struct {
 	OldPassword string `json:"oldPassword" validate:"required" label:"原密码"`
 	NewPassword string `json:"newPassword" validate:"required,min=6,max=20" label:"新密码"`
 }
 
+// ChangePassword 修改当前用户密码
+// @Summary 修改密码
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body ChangePasswordRequest true "原密码与新密码"
+// @Success 200 {object} response.Response
+// @Router /api/user/changePassword [post]
 func (h *UserHandler) ChangePassword(c fiber.Ctx) error {
 	userID := c.Locals("userID").(uint)
 	var req ChangePasswordRequest
@@ -146,7 +322,7 @@ func (h *UserHandler) ChangePassword(c fiber.Ctx) error {
 	err := h.userService.ChangePassword(userID, req.OldPassword, req.NewPassword)
 	if err != nil {
 		h.auditService.LogFail(c, model.ActionChangePassword, model.ModuleUser, fmt.Sprintf("%d", userID), err.Error())
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	h.auditService.LogSuccess(c, model.ActionChangePassword, model.ModuleUser, fmt.Sprintf("%d", userID), "用户修改密码")
@@ -157,6 +333,16 @@ type LogoutRequest struct {
 	RefreshToken string `json:"refreshToken"`
 }
 
+// Logout 退出登录，使 Access Token 和 Refresh Token 失效
+// @Summary 退出登录
+// @Description 请求头携带 Access Token，请求体可选携带 Refresh Token 一并注销
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body LogoutRequest false "刷新令牌"
+// @Success 200 {object} response.Response
+// @Router /api/auth/logout [post]
 func (h *UserHandler) Logout(c fiber.Ctx) error {
 	userID, _ := c.Locals("userID").(uint)
 
@@ -170,14 +356,79 @@ func (h *UserHandler) Logout(c fiber.Ctx) error {
 	var req LogoutRequest
 	_ = c.Bind().Body(&req)
 
-	if err := h.userService.Logout(userID, accessToken, req.RefreshToken); err != nil {
-		return response.Fail(c, err.Error())
-	}
+	h.userService.Logout(accessToken, req.RefreshToken)
 
 	h.auditService.LogSuccess(c, model.ActionLogout, model.ModuleAuth, fmt.Sprintf("%d", userID), "用户退出登录")
 	return response.SuccessWithMessage(c, "退出成功", nil)
 }
 
+// GetSessions 获取当前用户的活跃会话列表
+// @Summary 获取登录会话列表
+// @Tags 用户
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]service.SessionInfo}
+// @Router /api/user/sessions [get]
+func (h *UserHandler) GetSessions(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	sessionID, _ := c.Locals("sessionID").(string)
+
+	sessions, err := h.userService.GetSessions(userID, sessionID)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	return response.Success(c, sessions)
+}
+
+type RevokeSessionRequest struct {
+	SessionID string `json:"sessionId" validate:"required" label:"会话ID"`
+}
+
+// RevokeSession 撤销当前用户的指定会话(远程下线)
+// @Summary 撤销登录会话
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body RevokeSessionRequest true "会话ID"
+// @Success 200 {object} response.Response
+// @Router /api/user/sessions/revoke [post]
+func (h *UserHandler) RevokeSession(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+	var req RevokeSessionRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.userService.RevokeSession(userID, req.SessionID); err != nil {
+		h.auditService.LogFail(c, model.ActionLogout, model.ModuleAuth, req.SessionID, err.Error())
+		return response.Error(c, err)
+	}
+
+	h.auditService.LogSuccess(c, model.ActionLogout, model.ModuleAuth, req.SessionID, "撤销登录会话")
+	return response.SuccessWithMessage(c, "会话已下线", nil)
+}
+
+// LogoutAll 退出当前用户的全部会话
+// @Summary 退出全部会话
+// @Tags 用户
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Router /api/user/logoutAll [post]
+func (h *UserHandler) LogoutAll(c fiber.Ctx) error {
+	userID := c.Locals("userID").(uint)
+
+	if err := h.userService.LogoutAll(userID); err != nil {
+		h.auditService.LogFail(c, model.ActionLogout, model.ModuleAuth, fmt.Sprintf("%d", userID), err.Error())
+		return response.Error(c, err)
+	}
+
+	h.auditService.LogSuccess(c, model.ActionLogout, model.ModuleAuth, fmt.Sprintf("%d", userID), "退出全部会话")
+	return response.SuccessWithMessage(c, "已退出全部会话", nil)
+}
+
 // ==================== 管理员用户管理 ====================
 
 type AdminUserListRequest struct {
@@ -187,6 +438,15 @@ type AdminUserListRequest struct {
 	Phone    string `json:"phone"`
 	Email    string `json:"email"`
 	Status   int8   `json:"status"`
+
+	IncludeDeleted bool `json:"includeDeleted"` // 是否包含已删除的用户，仅列表接口有效
+}
+
+// AdminUserListItem 用户列表项，包含已删除用户时返回删除时间和删除前的用户名
+type AdminUserListItem struct {
+	model.User
+	DeletedAt        *time.Time `json:"deletedAt,omitempty"`        // 删除时间，未删除时不返回
+	OriginalUsername string     `json:"originalUsername,omitempty"` // 删除前的用户名，恢复时优先使用
 }
 
 type AdminCreateUserRequest struct {
@@ -201,16 +461,16 @@ type AdminCreateUserRequest struct {
 
 type AdminUpdateUserRequest struct {
 	ID       uint   `json:"id" validate:"required" label:"用户ID"`
-	Nickname string `json:"nickname" label:"昵称"`
-	Phone    string `json:"phone" validate:"phone" label:"手机号"`
-	Email    string `json:"email" validate:"email" label:"邮箱"`
-	Avatar   string `json:"avatar" label:"头像"`
+	Nickname string `json:"nickname" validate:"omitempty,max=50" label:"昵称"`
+	Phone    string `json:"phone" validate:"omitempty,phone" label:"手机号"`
+	Email    string `json:"email" validate:"omitempty,email,max=100" label:"邮箱"`
+	Avatar   string `json:"avatar" validate:"omitempty,max=255" label:"头像"`
 	Role     int8   `json:"role" label:"角色"`
 	Status   int8   `json:"status" label:"状态"`
 }
 
 type AdminUserIDRequest struct {
-	ID uint `json:"id" validate:"required" label:"用户ID"`
+	ID uint `json:"id" query:"id" validate:"required" label:"用户ID"`
 }
 
 type AdminResetPasswordRequest struct {
@@ -224,30 +484,80 @@ type AdminUpdateStatusRequest struct {
 }
 
 // AdminGetUserList 获取用户列表
+// @Summary 用户列表
+// @Description status 为 -1 时不按状态筛选；includeDeleted 为 true 时包含已删除的用户，可配合恢复接口使用
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AdminUserListRequest false "筛选与分页条件"
+// @Success 200 {object} response.Response{data=response.PageResult{items=[]AdminUserListItem}}
+// @Router /api/admin/user/list [post]
 func (h *UserHandler) AdminGetUserList(c fiber.Ctx) error {
 	var req AdminUserListRequest
 	if err := c.Bind().Body(&req); err != nil {
-		req.Page = 1
-		req.PageSize = 10
 		req.Status = -1
 	}
+	req.Page, req.PageSize = database.NormalizePage(req.Page, req.PageSize)
 
-	if req.Page <= 0 {
-		req.Page = 1
+	users, total, err := h.userService.AdminGetUserList(req.Page, req.PageSize, req.Username, req.Phone, req.Email, req.Status, req.IncludeDeleted)
+	if err != nil {
+		return response.Error(c, err)
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = 10
+
+	items := make([]AdminUserListItem, len(users))
+	for i, user := range users {
+		items[i] = AdminUserListItem{User: user}
+		if user.DeletedAt.Valid {
+			items[i].DeletedAt = &user.DeletedAt.Time
+			items[i].OriginalUsername = user.OriginalUsername
+		}
 	}
 
-	users, total, err := h.userService.AdminGetUserList(req.Page, req.PageSize, req.Username, req.Phone, req.Email, req.Status)
-	if err != nil {
-		return response.Fail(c, err.Error())
+	return response.SuccessWithPage(c, items, total, req.Page, req.PageSize)
+}
+
+// AdminExportUsers 按筛选条件(与列表接口一致)导出用户为CSV文件，不分页
+// @Summary 导出用户
+// @Description 筛选条件与用户列表接口一致，导出全部匹配的用户，不包含密码
+// @Tags 用户管理
+// @Accept json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param body body AdminUserListRequest false "筛选条件(忽略分页参数)"
+// @Success 200 {file} file "CSV 文件"
+// @Router /api/admin/user/export [post]
+func (h *UserHandler) AdminExportUsers(c fiber.Ctx) error {
+	var req AdminUserListRequest
+	if err := c.Bind().Body(&req); err != nil {
+		req.Status = -1
 	}
 
-	return response.SuccessWithPage(c, users, total, req.Page, req.PageSize)
+	h.auditService.LogSuccess(c, model.ActionExport, model.ModuleAdmin, "", "导出用户列表")
+
+	filename := fmt.Sprintf("users_%s.csv", time.Now().Format("20060102150405"))
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// 响应头已发送，导出中途出错只能记录日志并截断文件
+	ctx := c.Context()
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		if err := h.userService.ExportUsersCSV(req.Username, req.Phone, req.Email, req.Status, w); err != nil {
+			logger.ErrorContext(ctx, "导出用户失败", slog.Any("error", err))
+		}
+	})
 }
 
 // AdminCreateUser 创建用户
+// @Summary 创建用户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AdminCreateUserRequest true "用户信息"
+// @Success 200 {object} response.Response{data=model.User}
+// @Failure 403 {object} response.Response
+// @Router /api/admin/user/add [post]
 func (h *UserHandler) AdminCreateUser(c fiber.Ctx) error {
 	var req AdminCreateUserRequest
 	if err := validator.BindAndValidate(c, &req); err != nil {
@@ -259,10 +569,15 @@ func (h *UserHandler) AdminCreateUser(c fiber.Ctx) error {
 		req.Status = 1
 	}
 
+	if err := h.checkAdminTarget(c, 0, req.Role); err != nil {
+		h.auditService.LogFail(c, model.ActionCreateUser, model.ModuleAdmin, req.Username, err.Error())
+		return response.Forbidden(c, err.Error())
+	}
+
 	user, err := h.userService.AdminCreateUser(req.Username, req.Password, req.Nickname, req.Phone, req.Email, req.Role, req.Status)
 	if err != nil {
 		h.auditService.LogFail(c, model.ActionCreateUser, model.ModuleAdmin, req.Username, err.Error())
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	h.auditService.LogSuccess(c, model.ActionCreateUser, model.ModuleAdmin, req.Username, fmt.Sprintf("创建用户: %s", req.Username))
@@ -270,16 +585,30 @@ func (h *UserHandler) AdminCreateUser(c fiber.Ctx) error {
 }
 
 // AdminUpdateUser 更新用户
+// @Summary 更新用户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AdminUpdateUserRequest true "用户信息"
+// @Success 200 {object} response.Response{data=model.User}
+// @Failure 403 {object} response.Response
+// @Router /api/admin/user/update [post]
 func (h *UserHandler) AdminUpdateUser(c fiber.Ctx) error {
 	var req AdminUpdateUserRequest
 	if err := validator.BindAndValidate(c, &req); err != nil {
 		return err
 	}
 
+	if err := h.checkAdminTarget(c, req.ID, req.Role); err != nil {
+		h.auditService.LogFail(c, model.ActionUpdateUser, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), err.Error())
+		return response.Forbidden(c, err.Error())
+	}
+
 	user, err := h.userService.AdminUpdateUser(req.ID, req.Nickname, req.Phone, req.Email, req.Avatar, req.Role, req.Status)
 	if err != nil {
 		h.auditService.LogFail(c, model.ActionUpdateUser, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), err.Error())
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	h.auditService.LogSuccess(c, model.ActionUpdateUser, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), fmt.Sprintf("更新用户ID: %d", req.ID))
@@ -287,63 +616,222 @@ func (h *UserHandler) AdminUpdateUser(c fiber.Ctx) error {
 }
 
 // AdminDeleteUser 删除用户
+// @Summary 删除用户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AdminUserIDRequest true "用户ID"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/admin/user/delete [post]
 func (h *UserHandler) AdminDeleteUser(c fiber.Ctx) error {
 	var req AdminUserIDRequest
 	if err := validator.BindAndValidate(c, &req); err != nil {
 		return err
 	}
 
+	if err := h.checkAdminTarget(c, req.ID, 0); err != nil {
+		h.auditService.LogFail(c, model.ActionDeleteUser, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), err.Error())
+		return response.Forbidden(c, err.Error())
+	}
+
 	if err := h.userService.AdminDeleteUser(req.ID); err != nil {
 		h.auditService.LogFail(c, model.ActionDeleteUser, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), err.Error())
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	h.auditService.LogSuccess(c, model.ActionDeleteUser, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), fmt.Sprintf("删除用户ID: %d", req.ID))
 	return response.SuccessWithMessage(c, "删除成功", nil)
 }
 
+// AdminRestoreUser 恢复已删除的用户
+// @Summary 恢复已删除的用户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AdminUserIDRequest true "用户ID"
+// @Success 200 {object} response.Response{data=model.User}
+// @Router /api/admin/user/restore [post]
+func (h *UserHandler) AdminRestoreUser(c fiber.Ctx) error {
+	var req AdminUserIDRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.userService.AdminRestoreUser(req.ID)
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionRestoreUser, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), err.Error())
+		return response.Error(c, err)
+	}
+
+	h.auditService.LogSuccess(c, model.ActionRestoreUser, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), fmt.Sprintf("恢复用户ID: %d, 用户名: %s", req.ID, user.Username))
+	return response.SuccessWithMessage(c, "恢复成功", user)
+}
+
+// AdminImportUsers 从CSV批量导入用户
+// @Summary 批量导入用户
+// @Description 上传CSV文件，首行为表头(username,password,nickname,phone,email,role)，列顺序不限；逐行返回导入结果，用户名重复或校验失败的行会被跳过
+// @Tags 用户管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV文件"
+// @Success 200 {object} response.Response{data=service.UserImportResult}
+// @Router /api/admin/user/import [post]
+func (h *UserHandler) AdminImportUsers(c fiber.Ctx) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return response.Fail(c, "获取上传文件失败: "+err.Error())
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return response.Fail(c, "读取上传文件失败: "+err.Error())
+	}
+	defer file.Close()
+
+	operatorID, _ := c.Locals("userID").(uint)
+	operatorRole, _ := c.Locals("role").(int8)
+	result, err := h.userService.ImportUsersCSV(file, h.rbacService.IsSuperAdmin(operatorID, operatorRole))
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionImport, model.ModuleAdmin, fileHeader.Filename, err.Error())
+		return response.Error(c, err)
+	}
+
+	h.auditService.LogSuccess(c, model.ActionImport, model.ModuleAdmin, fileHeader.Filename,
+		fmt.Sprintf("导入用户: 共 %d 行，成功 %d 行，失败 %d 行", result.Total, result.Succeeded, result.Failed))
+	return response.Success(c, result)
+}
+
+// checkAdminTarget 只有超级管理员可以授予管理员身份(role=1)或操作超级管理员账号，
+// 防止仅拥有用户管理权限的角色越权提升自己或他人；目标为 role=1 或通过角色拥有全部权限的用户均视为超级管理员
+func (h *UserHandler) checkAdminTarget(c fiber.Ctx, userID uint, newRole int8) error {
+	operatorID, _ := c.Locals("userID").(uint)
+	operatorRole, _ := c.Locals("role").(int8)
+	if h.rbacService.IsSuperAdmin(operatorID, operatorRole) {
+		return nil
+	}
+
+	if newRole == 1 {
+		return errors.New("仅超级管理员可设置管理员身份")
+	}
+	if userID != 0 {
+		if user, err := h.userService.GetUserByID(c.Context(), userID); err == nil && h.rbacService.IsSuperAdmin(user.ID, user.Role) {
+			return errors.New("仅超级管理员可操作管理员账号")
+		}
+	}
+	return nil
+}
+
 // AdminGetUserDetail 获取用户详情
+// @Summary 用户详情
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id query int true "用户ID"
+// @Success 200 {object} response.Response{data=model.User}
+// @Router /api/admin/user/detail [get]
 func (h *UserHandler) AdminGetUserDetail(c fiber.Ctx) error {
-	idStr := c.Query("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil || id == 0 {
-		return response.Fail(c, "参数错误: id必须为有效数字")
+	var req AdminUserIDRequest
+	if err := validator.BindAllAndValidate(c, &req); err != nil {
+		return err
 	}
 
-	user, err := h.userService.GetUserByID(uint(id))
+	user, err := h.userService.GetUserByID(c.Context(), req.ID)
 	if err != nil {
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	return response.Success(c, user)
 }
 
 // AdminResetPassword 重置用户密码
+// @Summary 重置用户密码
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AdminResetPasswordRequest true "用户ID与新密码"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/admin/user/resetPassword [post]
 func (h *UserHandler) AdminResetPassword(c fiber.Ctx) error {
 	var req AdminResetPasswordRequest
 	if err := validator.BindAndValidate(c, &req); err != nil {
 		return err
 	}
 
+	if err := h.checkAdminTarget(c, req.ID, 0); err != nil {
+		h.auditService.LogFail(c, model.ActionResetPassword, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), err.Error())
+		return response.Forbidden(c, err.Error())
+	}
+
 	if err := h.userService.AdminResetPassword(req.ID, req.NewPassword); err != nil {
 		h.auditService.LogFail(c, model.ActionResetPassword, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), err.Error())
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	h.auditService.LogSuccess(c, model.ActionResetPassword, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), fmt.Sprintf("重置用户密码ID: %d", req.ID))
 	return response.SuccessWithMessage(c, "密码重置成功", nil)
 }
 
+// AdminUnlockUser 解除用户的登录锁定
+// @Summary 解除登录锁定
+// @Description 清除用户的连续登录失败次数及锁定状态，返回解锁前是否锁定及剩余锁定时间
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AdminUserIDRequest true "用户ID"
+// @Success 200 {object} response.Response{data=service.UnlockUserResult}
+// @Router /api/admin/user/unlock [post]
+func (h *UserHandler) AdminUnlockUser(c fiber.Ctx) error {
+	var req AdminUserIDRequest
+	if err := validator.BindAndValidate(c, &req); err != nil {
+		return err
+	}
+
+	target := fmt.Sprintf("%d", req.ID)
+	result, err := h.userService.UnlockUser(req.ID)
+	if err != nil {
+		h.auditService.LogFail(c, model.ActionUnlockUser, model.ModuleAdmin, target, err.Error())
+		return response.Error(c, err)
+	}
+
+	if !result.Locked {
+		h.auditService.LogSuccess(c, model.ActionUnlockUser, model.ModuleAdmin, target, fmt.Sprintf("用户ID: %d 未被锁定，已清除 %d 次登录失败记录", req.ID, result.FailedAttempts))
+		return response.SuccessWithMessage(c, "该用户未被锁定", result)
+	}
+
+	h.auditService.LogSuccess(c, model.ActionUnlockUser, model.ModuleAdmin, target, fmt.Sprintf("解除用户ID: %d 的登录锁定，剩余锁定 %d 秒", req.ID, result.RemainingSeconds))
+	return response.SuccessWithMessage(c, "解锁成功", result)
+}
+
 // AdminUpdateUserStatus 更新用户状态
+// @Summary 更新用户状态
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body AdminUpdateStatusRequest true "用户ID与状态(0禁用 1启用)"
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/admin/user/updateStatus [post]
 func (h *UserHandler) AdminUpdateUserStatus(c fiber.Ctx) error {
 	var req AdminUpdateStatusRequest
 	if err := validator.BindAndValidate(c, &req); err != nil {
 		return err
 	}
 
+	if err := h.checkAdminTarget(c, req.ID, 0); err != nil {
+		h.auditService.LogFail(c, model.ActionUpdateStatus, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), err.Error())
+		return response.Forbidden(c, err.Error())
+	}
+
 	if err := h.userService.AdminUpdateUserStatus(req.ID, req.Status); err != nil {
 		h.auditService.LogFail(c, model.ActionUpdateStatus, model.ModuleAdmin, fmt.Sprintf("%d", req.ID), err.Error())
-		return response.Fail(c, err.Error())
+		return response.Error(c, err)
 	}
 
 	statusText := "禁用"
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"goboot/config"
+	"goboot/internal/middleware"
+	"goboot/internal/model"
+	"goboot/internal/service"
+	"goboot/internal/testutil"
+	"goboot/pkg/database"
+	"goboot/pkg/response"
+	"goboot/pkg/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// doRequest 发送请求并解析统一响应
+func doRequest(t *testing.T, app *fiber.App, method, path, token, body string) (int, response.Response) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var result response.Response
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, result
+}
+
+func TestLogoutWithUnreachableRedis(t *testing.T) {
+	testutil.Setup(t)
+	testutil.UseUnreachableRedis(t)
+
+	h := NewUserHandler()
+	app := fiber.New()
+	app.Post("/api/auth/logout", middleware.JWTAuth(), h.Logout)
+	app.Get("/api/user/info", middleware.JWTAuth(), func(c fiber.Ctx) error {
+		return response.Success(c, c.Locals("userID"))
+	})
+
+	tokens, err := utils.GenerateTokenPairWithSession(1, "alice", 0, "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 默认放行：鉴权检查失败时不拒绝，退出登录仍返回成功
+	status, result := doRequest(t, app, "POST", "/api/auth/logout", tokens.AccessToken,
+		`{"refreshToken":"`+tokens.RefreshToken+`"}`)
+	if status != fiber.StatusOK || result.Code != response.SUCCESS {
+		t.Fatalf("退出登录应成功，实际: status=%d code=%d msg=%s", status, result.Code, result.Message)
+	}
+
+	// 令牌未能加入黑名单，过期前仍可访问
+	status, result = doRequest(t, app, "GET", "/api/user/info", tokens.AccessToken, "")
+	if status != fiber.StatusOK || result.Code != response.SUCCESS {
+		t.Errorf("默认配置下 Redis 不可用应放行，实际: status=%d msg=%s", status, result.Message)
+	}
+
+	// 开启 token_check_fail_closed 后拒绝访问
+	testutil.SetConfig(t, func(cfg *config.Config) {
+		cfg.Redis.TokenCheckFailClosed = true
+	})
+	status, result = doRequest(t, app, "GET", "/api/user/info", tokens.AccessToken, "")
+	if status != fiber.StatusUnauthorized {
+		t.Errorf("token_check_fail_closed 下 Redis 不可用应拒绝访问，实际: status=%d msg=%s", status, result.Message)
+	}
+
+	// 退出登录请求本身同样经过鉴权，拒绝访问但不会卡住或崩溃
+	status, _ = doRequest(t, app, "POST", "/api/auth/logout", tokens.AccessToken, "")
+	if status != fiber.StatusUnauthorized {
+		t.Errorf("token_check_fail_closed 下退出登录应被鉴权拒绝，实际: status=%d", status)
+	}
+}
+
+// assignRole 为用户分配指定标识的角色
+func assignRole(t *testing.T, user *model.User, code string) {
+	t.Helper()
+	roles, err := model.GetAllRoles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, role := range roles {
+		if role.Code == code {
+			if err := model.SetUserRoles(user, []model.Role{role}); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+	}
+	t.Fatalf("角色不存在: %s", code)
+}
+
+func TestAdminCannotManageSuperAdminRoleHolder(t *testing.T) {
+	testutil.Setup(t)
+	testutil.UseMiniRedis(t)
+
+	// 仅拥有用户管理权限的角色
+	perms, err := model.GetAllPermissions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var userManage []model.Permission
+	for _, perm := range perms {
+		if perm.Code == model.PermUserManage {
+			userManage = append(userManage, perm)
+		}
+	}
+	managerRole := &model.Role{Code: "test_user_manager", Name: "用户管理员"}
+	if err := model.CreateRole(managerRole); err != nil {
+		t.Fatal(err)
+	}
+	if err := model.UpdateRole(managerRole, userManage); err != nil {
+		t.Fatal(err)
+	}
+
+	userService := service.NewUserService()
+	register := func(username string) *model.User {
+		user, err := userService.Register(username, "Passw0rd!old", username, "", "")
+		if err != nil {
+			t.Fatalf("Register(%s): %v", username, err)
+		}
+		return user
+	}
+	operator := register("rbac_operator")
+	assignRole(t, operator, "test_user_manager")
+	// role=0 但通过 superadmin 角色拥有全部权限
+	superAdmin := register("rbac_superadmin")
+	assignRole(t, superAdmin, model.RoleSuperAdmin)
+	member := register("rbac_member")
+
+	h := NewUserHandler()
+	app := fiber.New(fiber.Config{ErrorHandler: response.ErrorHandler})
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("userID", operator.ID)
+		c.Locals("role", int8(0))
+		return c.Next()
+	})
+	userAdmin := app.Group("/api/admin/user", middleware.RequirePermission(model.PermUserManage))
+	userAdmin.Post("/resetPassword", h.AdminResetPassword)
+	userAdmin.Post("/updateStatus", h.AdminUpdateUserStatus)
+	userAdmin.Post("/delete", h.AdminDeleteUser)
+
+	resetBody := func(id uint) string {
+		return fmt.Sprintf(`{"id":%d,"newPassword":"Passw0rd!new"}`, id)
+	}
+
+	// 普通用户可以操作
+	if status, result := doRequest(t, app, "POST", "/api/admin/user/resetPassword", "", resetBody(member.ID)); status != fiber.StatusOK || result.Code != response.SUCCESS {
+		t.Fatalf("重置普通用户密码应成功，实际: status=%d msg=%s", status, result.Message)
+	}
+
+	// 拥有 superadmin 角色的用户不能被重置密码或禁用
+	if status, result := doRequest(t, app, "POST", "/api/admin/user/resetPassword", "", resetBody(superAdmin.ID)); status != fiber.StatusForbidden {
+		t.Errorf("重置超级管理员密码应被拒绝，实际: status=%d msg=%s", status, result.Message)
+	}
+	if status, result := doRequest(t, app, "POST", "/api/admin/user/updateStatus", "", fmt.Sprintf(`{"id":%d,"status":0}`, superAdmin.ID)); status != fiber.StatusForbidden {
+		t.Errorf("禁用超级管理员应被拒绝，实际: status=%d msg=%s", status, result.Message)
+	}
+
+	if status, result := doRequest(t, app, "POST", "/api/admin/user/delete", "", fmt.Sprintf(`{"id":%d}`, superAdmin.ID)); status != fiber.StatusForbidden {
+		t.Errorf("删除超级管理员应被拒绝，实际: status=%d msg=%s", status, result.Message)
+	}
+
+	var stored model.User
+	if err := database.DB.First(&stored, superAdmin.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !utils.CheckPassword("Passw0rd!old", stored.Password) || stored.Status != model.UserStatusActive {
+		t.Errorf("超级管理员的密码或状态被修改")
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"goboot/internal/service"
+	"goboot/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// APIKeyHeader 携带 API Key 的请求头
+const APIKeyHeader = "X-API-Key"
+
+var apiKeyService = service.NewAPIKeyService()
+
+// APIKeyAuth 使用 X-API-Key 请求头认证，认证通过后按 JWTAuth 的方式设置用户信息，
+// 另外设置 apiKeyID 和 apiKeyScopes，供 RequirePermission 限制 API Key 的权限范围
+func APIKeyAuth() fiber.Handler {
+	return apiKeyAuth
+}
+
+func apiKeyAuth(c fiber.Ctx) error {
+	plain := c.Get(APIKeyHeader)
+	if plain == "" {
+		return response.Unauthorized(c, "请先登录")
+	}
+
+	key, user, err := apiKeyService.Authenticate(plain)
+	if err != nil {
+		return response.Unauthorized(c, err.Error())
+	}
+
+	c.Locals("userID", user.ID)
+	c.Locals("username", user.Username)
+	c.Locals("role", user.Role)
+	c.Locals("apiKeyID", key.ID)
+	c.Locals("apiKeyScopes", key.ScopeList())
+	return c.Next()
+}
+
+// Auth 登录认证，请求携带 X-API-Key 且未携带 Authorization 时使用 API Key 认证，否则使用 JWT 认证
+func Auth() fiber.Handler {
+	jwtAuth := JWTAuth()
+	return func(c fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" && c.Get(APIKeyHeader) != "" {
+			return apiKeyAuth(c)
+		}
+		return jwtAuth(c)
+	}
+}
+
+// DenyAPIKey 禁止通过 API Key 访问，用于 API Key 管理等只允许用户本人登录操作的接口
+func DenyAPIKey() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if _, ok := c.Locals("apiKeyID").(uint); ok {
+			return response.Forbidden(c, "该接口不支持API Key访问")
+		}
+		return c.Next()
+	}
+}
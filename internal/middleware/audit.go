@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"goboot/internal/service"
+	"goboot/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+var auditService = service.NewAuditService()
+
+// Audit 自动记录路由的审计日志，action/module 写入 c.Locals 供后续使用
+// 处理器未手动调用 auditService.LogSuccess/LogFail 时，按最终响应判断成功或失败：
+// HTTP 状态码 >= 400 或响应体 code 非 0 视为失败，失败详情取响应消息
+// 处理器可通过 c.Locals("auditTarget", ...) 设置操作目标
+func Audit(action, module string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		c.Locals("auditAction", action)
+		c.Locals("auditModule", module)
+
+		err := c.Next()
+
+		if logged, _ := c.Locals("auditLogged").(bool); logged {
+			return err
+		}
+
+		target, _ := c.Locals("auditTarget").(string)
+		success, message := auditResult(c, err)
+		detail := fmt.Sprintf("%s %s", c.Method(), c.Path())
+		if success {
+			auditService.LogSuccess(c, action, module, target, detail)
+		} else {
+			auditService.LogFail(c, action, module, target, detail+": "+message)
+		}
+		return err
+	}
+}
+
+// auditResult 根据处理器返回的错误和响应判断操作是否成功，失败时返回原因
+func auditResult(c fiber.Ctx, err error) (bool, string) {
+	if err != nil && !errors.Is(err, response.ErrAborted) {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return false, fiberErr.Message
+		}
+		return false, err.Error()
+	}
+
+	resp := c.Response()
+	status := resp.StatusCode()
+
+	// 流式响应不读取响应体，避免将整个流加载到内存
+	var body response.Response
+	if !resp.IsBodyStream() && strings.HasPrefix(string(resp.Header.ContentType()), fiber.MIMEApplicationJSON) {
+		if json.Unmarshal(resp.Body(), &body) != nil {
+			body = response.Response{}
+		}
+	}
+
+	if status >= fiber.StatusBadRequest {
+		if body.Message != "" {
+			return false, body.Message
+		}
+		return false, fmt.Sprintf("HTTP %d", status)
+	}
+	if body.Code != response.SUCCESS {
+		return false, body.Message
+	}
+	return true, ""
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"goboot/config"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ClientIP 解析可信代理传递的客户端IP，需在其他中间件之前注册
+// Fiber 从 ProxyHeader 中取第一个合法IP，而代理追加 X-Forwarded-For 时最左侧的地址由客户端提供，可以伪造；
+// 请求来自可信代理时，自右向左跳过可信代理地址，取第一个不属于可信代理的IP改写该请求头，使 c.IP() 返回真实客户端IP；
+// 该地址不是合法IP时删除请求头，c.IP() 回退为连接的对端IP。未配置 server.trusted_proxies 时不做处理
+func ClientIP() fiber.Handler {
+	cfg := config.Get().Server
+	trusted := parseIPNets(cfg.TrustedProxies)
+	if len(trusted) == 0 {
+		return func(c fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	header := cfg.ProxyHeader
+	if header == "" {
+		header = fiber.HeaderXForwardedFor
+	}
+
+	return func(c fiber.Ctx) error {
+		if !c.IsProxyTrusted() {
+			return c.Next()
+		}
+
+		if ip := resolveClientIP(c.Get(header), trusted); ip != "" {
+			c.Request().Header.Set(header, ip)
+		} else {
+			c.Request().Header.Del(header)
+		}
+		return c.Next()
+	}
+}
+
+// resolveClientIP 从逗号分隔的代理链中自右向左取第一个不属于可信代理的IP，
+// 全部为可信代理时取最左侧的地址；遇到非法地址或请求头为空时返回空
+func resolveClientIP(value string, trusted []*net.IPNet) string {
+	hops := strings.Split(value, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return ""
+		}
+		if i == 0 || !containsIP(trusted, ip) {
+			return ip.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"goboot/config"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+const defaultCompressMinSize = 1024
+
+// 已压缩或压缩收益很低的内容类型前缀
+var incompressibleTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-rar-compressed",
+	"application/vnd.rar",
+	"application/x-7z-compressed",
+	"application/octet-stream",
+	"font/woff",
+}
+
+// Compress 响应压缩中间件，根据 Accept-Encoding 协商 gzip/deflate，
+// 只压缩超过阈值的响应体，并跳过图片、压缩包等已压缩内容
+func Compress() fiber.Handler {
+	cfg := config.Get().Compress
+	if !cfg.Enabled {
+		return func(c fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	level := cfg.Level
+	if level < fasthttp.CompressBestSpeed || level > fasthttp.CompressBestCompression {
+		level = fasthttp.CompressDefaultCompression
+	}
+	minSize := cfg.MinSize
+	if minSize <= 0 {
+		minSize = defaultCompressMinSize
+	}
+
+	return func(c fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		if !shouldCompress(c, minSize) {
+			return nil
+		}
+
+		c.Vary(fiber.HeaderAcceptEncoding)
+
+		var compressed []byte
+		encoding := negotiateEncoding(c.Get(fiber.HeaderAcceptEncoding))
+		switch encoding {
+		case "gzip":
+			compressed = fasthttp.AppendGzipBytesLevel(nil, c.Response().Body(), level)
+		case "deflate":
+			compressed = fasthttp.AppendDeflateBytesLevel(nil, c.Response().Body(), level)
+		default:
+			return nil
+		}
+
+		c.Response().SetBodyRaw(compressed)
+		c.Set(fiber.HeaderContentEncoding, encoding)
+		return nil
+	}
+}
+
+// shouldCompress 判断响应是否需要压缩
+func shouldCompress(c fiber.Ctx, minSize int) bool {
+	if c.Method() == fiber.MethodHead {
+		return false
+	}
+
+	resp := c.Response()
+	status := resp.StatusCode()
+	if status < 200 || status == fiber.StatusNoContent || status == fiber.StatusPartialContent || status == fiber.StatusNotModified {
+		return false
+	}
+	// 流式响应(如静态文件)不读入内存压缩
+	if resp.IsBodyStream() || len(resp.Body()) < minSize {
+		return false
+	}
+	if c.GetRespHeader(fiber.HeaderContentEncoding) != "" ||
+		strings.Contains(c.GetRespHeader(fiber.HeaderCacheControl), "no-transform") {
+		return false
+	}
+
+	contentType := strings.ToLower(c.GetRespHeader(fiber.HeaderContentType))
+	for _, prefix := range incompressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// negotiateEncoding 按 Accept-Encoding 选择 gzip 或 deflate，q 值相同时优先 gzip；
+// 请求未携带该头、两者都未提供或 q=0 时返回空，不压缩。"*" 表示接受未单独列出的编码
+func negotiateEncoding(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if key, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(key) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		qualities[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range []string{"gzip", "deflate"} {
+		q, ok := qualities[encoding]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
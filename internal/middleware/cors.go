@@ -1,16 +1,80 @@
 package middleware
 
 import (
+	"strconv"
+	"strings"
+
+	"goboot/config"
+
 	"github.com/gofiber/fiber/v3"
 )
 
+const (
+	defaultCorsMethods = "GET, POST, PUT, DELETE, OPTIONS"
+	defaultCorsHeaders = "Content-Type, Authorization, X-Requested-With"
+	defaultCorsMaxAge  = 86400
+)
+
+// Cors 跨域中间件
+// 未配置 allowed_origins 时允许任意来源(*)；配置后仅对白名单内的 Origin 回显，
+// 按配置允许携带凭证的仅限白名单中明确列出的来源，通过 * 匹配的来源始终不允许携带凭证
 func Cors() fiber.Handler {
+	cfg := config.Get().CORS
+
+	methods := defaultCorsMethods
+	if len(cfg.AllowedMethods) > 0 {
+		methods = strings.Join(cfg.AllowedMethods, ", ")
+	}
+	headers := defaultCorsHeaders
+	if len(cfg.AllowedHeaders) > 0 {
+		headers = strings.Join(cfg.AllowedHeaders, ", ")
+	}
+	maxAge := defaultCorsMaxAge
+	if cfg.MaxAge > 0 {
+		maxAge = cfg.MaxAge
+	}
+
+	// 未配置白名单时保持原有的通配行为，通配符不能与凭证同时使用
+	wildcard := len(cfg.AllowedOrigins) == 0
+	// 白名单中包含 * 时回显任意来源，但不允许携带凭证，否则任意站点都能读取用户已登录的响应
+	anyOrigin := false
+	origins := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+		if origin == "*" {
+			anyOrigin = true
+			continue
+		}
+		origins[strings.ToLower(origin)] = true
+	}
+
 	return func(c fiber.Ctx) error {
-		c.Set("Access-Control-Allow-Origin", "*")
-		c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-		c.Set("Access-Control-Expose-Headers", "Content-Length, Content-Type")
-		c.Set("Access-Control-Max-Age", "86400")
+		origin := c.Get(fiber.HeaderOrigin)
+
+		switch {
+		case wildcard:
+			c.Set(fiber.HeaderAccessControlAllowOrigin, "*")
+		case origin != "" && (anyOrigin || origins[strings.ToLower(origin)]):
+			c.Set(fiber.HeaderAccessControlAllowOrigin, origin)
+			if cfg.AllowCredentials && origins[strings.ToLower(origin)] {
+				c.Set(fiber.HeaderAccessControlAllowCredentials, "true")
+			}
+		default:
+			// 来源不在白名单内，不返回跨域头，由浏览器拦截
+			c.Vary(fiber.HeaderOrigin)
+			if c.Method() == fiber.MethodOptions {
+				return c.SendStatus(fiber.StatusNoContent)
+			}
+			return c.Next()
+		}
+
+		if !wildcard {
+			c.Vary(fiber.HeaderOrigin)
+		}
+		c.Set(fiber.HeaderAccessControlAllowMethods, methods)
+		c.Set(fiber.HeaderAccessControlAllowHeaders, headers)
+		c.Set(fiber.HeaderAccessControlExposeHeaders, "Content-Length, Content-Type")
+		c.Set(fiber.HeaderAccessControlMaxAge, strconv.Itoa(maxAge))
 
 		if c.Method() == fiber.MethodOptions {
 			return c.SendStatus(fiber.StatusNoContent)
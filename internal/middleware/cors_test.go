@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"goboot/config"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestCorsCredentials(t *testing.T) {
+	prev := config.Get()
+	config.Set(&config.Config{CORS: config.CORSConfig{
+		AllowedOrigins:   []string{"https://admin.example.com", "*"},
+		AllowCredentials: true,
+	}})
+	t.Cleanup(func() { config.Set(prev) })
+
+	app := fiber.New()
+	app.Use(Cors())
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	tests := []struct {
+		name        string
+		origin      string
+		allowOrigin string
+		credentials string
+	}{
+		{"listed origin", "https://admin.example.com", "https://admin.example.com", "true"},
+		{"wildcard origin", "https://evil.example.net", "https://evil.example.net", ""},
+		{"no origin", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.origin != "" {
+				req.Header.Set(fiber.HeaderOrigin, tt.origin)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if got := resp.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != tt.allowOrigin {
+				t.Errorf("Allow-Origin = %q, want %q", got, tt.allowOrigin)
+			}
+			if got := resp.Header.Get(fiber.HeaderAccessControlAllowCredentials); got != tt.credentials {
+				t.Errorf("Allow-Credentials = %q, want %q", got, tt.credentials)
+			}
+		})
+	}
+}
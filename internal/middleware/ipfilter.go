@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"goboot/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// IPFilter IP访问控制中间件，allow、deny 为IP或CIDR列表，在创建时解析，格式错误时 panic
+// 命中 deny 的请求直接拒绝；allow 非空时仅允许命中 allow 的请求；两者都为空时不做限制
+// 客户端IP取自 c.IP()，部署在反向代理后时需配置 server.trusted_proxies
+func IPFilter(allow, deny []string) fiber.Handler {
+	allowNets := parseIPNets(allow)
+	denyNets := parseIPNets(deny)
+	if len(allowNets) == 0 && len(denyNets) == 0 {
+		return func(c fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return func(c fiber.Ctx) error {
+		ip := net.ParseIP(c.IP())
+		if ip == nil || containsIP(denyNets, ip) || (len(allowNets) > 0 && !containsIP(allowNets, ip)) {
+			return response.Forbidden(c, "当前IP禁止访问")
+		}
+		return c.Next()
+	}
+}
+
+// parseIPNets 将IP或CIDR列表解析为网段，单个IP按 /32 或 /128 处理
+func parseIPNets(entries []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err != nil {
+				panic(fmt.Sprintf("IPFilter: 无效的CIDR %q: %v", entry, err))
+			}
+			nets = append(nets, ipNet)
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			panic(fmt.Sprintf("IPFilter: 无效的IP %q", entry))
+		}
+		bits := 8 * net.IPv6len
+		if v4 := ip.To4(); v4 != nil {
+			ip, bits = v4, 8*net.IPv4len
+		}
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return nets
+}
+
+// containsIP 检查IP是否在任一网段内
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -25,19 +25,25 @@ func JWTAuth() fiber.Handler {
 
 		token := parts[1]
 
+		claims, err := utils.ParseToken(token)
+		if err != nil {
+			return response.Unauthorized(c, "无效的token")
+		}
+
 		// 检查token是否在黑名单中
-		if userService.IsTokenBlacklisted(token) {
+		if userService.IsTokenBlacklisted(claims.ID) {
 			return response.Unauthorized(c, "token已失效，请重新登录")
 		}
 
-		claims, err := utils.ParseToken(token)
-		if err != nil {
-			return response.Unauthorized(c, "无效的token")
+		// 检查所属会话是否已被撤销
+		if userService.IsSessionRevoked(claims.SessionID) {
+			return response.Unauthorized(c, "登录会话已失效，请重新登录")
 		}
 
 		c.Locals("userID", claims.UserID)
 		c.Locals("username", claims.Username)
 		c.Locals("role", claims.Role)
+		c.Locals("sessionID", claims.SessionID)
 		return c.Next()
 	}
 }
@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"errors"
 	"goboot/pkg/logger"
+	"goboot/pkg/response"
 	"log/slog"
 	"time"
 
@@ -35,15 +37,17 @@ func Logger() fiber.Handler {
 			slog.String("latency", latency.String()),
 		}
 
-		if err != nil {
+		// 请求ID由 RequestID 中间件写入 context，*Context 方法会自动附加 request_id
+		ctx := c.Context()
+		if err != nil && !errors.Is(err, response.ErrAborted) {
 			attrs = append(attrs, slog.String("error", err.Error()))
-			logger.Error("Request error", attrs...)
+			logger.ErrorContext(ctx, "Request error", attrs...)
 		} else if status >= 500 {
-			logger.Error("Server error", attrs...)
+			logger.ErrorContext(ctx, "Server error", attrs...)
 		} else if status >= 400 {
-			logger.Warn("Client error", attrs...)
+			logger.WarnContext(ctx, "Client error", attrs...)
 		} else {
-			logger.Info("Request", attrs...)
+			logger.InfoContext(ctx, "Request", attrs...)
 		}
 
 		return err
@@ -54,7 +58,7 @@ func Recovery() fiber.Handler {
 	return func(c fiber.Ctx) error {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.Error("Panic recovered",
+				logger.ErrorContext(c.Context(), "Panic recovered",
 					slog.Any("error", err),
 					slog.String("path", c.Path()),
 					slog.String("method", c.Method()),
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"strings"
+
+	"goboot/internal/service"
+	"goboot/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// maintenanceAllowedPaths 维护模式下仍可访问的路径前缀：健康检查、监控、性能分析、登录及管理接口(含维护模式开关)
+var maintenanceAllowedPaths = []string{
+	"/ping",
+	"/health",
+	"/version",
+	MetricsPath,
+	"/debug/pprof",
+	"/api/auth/login",
+	"/api/auth/refreshToken",
+	"/api/admin/",
+}
+
+// MaintenanceMode 维护模式，由系统配置 maintenance_enabled 控制，可通过 POST /api/admin/maintenance 切换
+// 开启后除 maintenanceAllowedPaths 外的请求均返回 503 及 maintenance_message 提示
+func MaintenanceMode() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		status := service.GetConfigService().GetMaintenance()
+		if !status.Enabled || maintenanceAllowed(c.Path()) {
+			return c.Next()
+		}
+		return response.ServiceUnavailable(c, status.Message)
+	}
+}
+
+// maintenanceAllowed 检查路径在维护模式下是否放行
+func maintenanceAllowed(path string) bool {
+	for _, prefix := range maintenanceAllowedPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"goboot/config"
+	"goboot/pkg/metrics"
+	"goboot/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// MetricsPath 指标暴露路径，自身请求不计入统计
+const MetricsPath = "/metrics"
+
+// Metrics 请求指标中间件，记录请求数、耗时分布和并发请求数
+// path 标签使用路由模板(如 /api/user/:id)而不是实际路径，避免标签基数过大
+func Metrics() fiber.Handler {
+	if !config.Get().Metrics.Enabled {
+		return func(c fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	return func(c fiber.Ctx) error {
+		if c.Path() == MetricsPath {
+			return c.Next()
+		}
+
+		method := c.Method()
+		start := time.Now()
+		inFlight := metrics.RequestsInFlight.WithLabelValues(method)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil && !errors.Is(err, response.ErrAborted) {
+			if fe, ok := err.(*fiber.Error); ok {
+				status = fe.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+
+		path := c.Route().Path
+		if status == fiber.StatusNotFound {
+			path = "unmatched"
+		}
+
+		labels := []string{method, path, strconv.Itoa(status)}
+		metrics.RequestsTotal.WithLabelValues(labels...).Inc()
+		metrics.RequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
@@ -11,57 +11,66 @@ import (
 	"github.com/gofiber/fiber/v3"
 )
 
-// RateLimiter 基于 Redis 的滑动窗口限流中间件
+// RateLimiter 基于 Redis 的滑动窗口限流中间件，全局注册在认证之前，按客户端IP计数
 func RateLimiter() fiber.Handler {
 	return func(c fiber.Ctx) error {
-		cfg := config.AppConfig.RateLimit
+		cfg := config.Get().RateLimit
 		if !cfg.Enabled {
 			return c.Next()
 		}
+		return limit(c, ipRateLimitKey(c), cfg.Requests, cfg.Window)
+	}
+}
 
-		// 获取限流 key（优先用户ID，否则用IP）
-		key := getRateLimitKey(c)
-
-		// 检查是否超过限制
-		allowed, err := isAllowed(c, key, cfg.Requests, cfg.Window)
-		if err != nil {
-			// Redis 出错时放行，避免影响服务
+// UserRateLimiter 按登录用户限流，需注册在 Auth 之后的路由组上，与全局按IP限流同时生效；
+// 未配置 rate_limit.user_requests 时不限制
+func UserRateLimiter() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		cfg := config.Get().RateLimit
+		if !cfg.Enabled || cfg.UserRequests <= 0 {
 			return c.Next()
 		}
-
-		if !allowed {
-			return response.TooManyRequests(c, "请求过于频繁，请稍后再试")
+		window := cfg.UserWindow
+		if window <= 0 {
+			window = cfg.Window
 		}
-
-		return c.Next()
+		return limit(c, getRateLimitKey(c), cfg.UserRequests, window)
 	}
 }
 
-// RateLimiterWithConfig 支持自定义限流参数
+// RateLimiterWithConfig 支持自定义限流参数，注册在 Auth 之后时按用户计数
 func RateLimiterWithConfig(requests int, window int) fiber.Handler {
 	return func(c fiber.Ctx) error {
-		key := getRateLimitKey(c)
-
-		allowed, err := isAllowed(c, key, requests, window)
-		if err != nil {
-			return c.Next()
-		}
-
-		if !allowed {
-			return response.TooManyRequests(c, "请求过于频繁，请稍后再试")
-		}
+		return limit(c, getRateLimitKey(c), requests, window)
+	}
+}
 
+// limit 检查请求是否超过限制，Redis 出错时放行，避免影响服务
+func limit(c fiber.Ctx, key string, requests int, window int) error {
+	allowed, err := isAllowed(c, key, requests, window)
+	if err != nil {
 		return c.Next()
 	}
+
+	if !allowed {
+		return response.TooManyRequests(c, "请求过于频繁，请稍后再试")
+	}
+
+	return c.Next()
 }
 
-// getRateLimitKey 获取限流 key
+// getRateLimitKey 获取限流 key，已认证(userID 已写入 Locals)时按用户，否则按IP
 func getRateLimitKey(c fiber.Ctx) string {
 	// 优先使用用户ID（已登录用户）
 	if userID := c.Locals("userID"); userID != nil {
 		return fmt.Sprintf("ratelimit:user:%v:%s", userID, c.Path())
 	}
 	// 未登录使用 IP
+	return ipRateLimitKey(c)
+}
+
+// ipRateLimitKey 按客户端IP的限流 key
+func ipRateLimitKey(c fiber.Ctx) string {
 	return fmt.Sprintf("ratelimit:ip:%s:%s", c.IP(), c.Path())
 }
 
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"goboot/internal/service"
+	"goboot/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+var rbacService = service.NewRBACService()
+
+// RequirePermission 检查当前用户是否拥有指定权限，需在 JWTAuth 或 Auth 之后使用
+// role=1 的管理员拥有全部权限
+func RequirePermission(perm string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		userID, ok := c.Locals("userID").(uint)
+		if !ok {
+			return response.Unauthorized(c, "请先登录")
+		}
+		role, _ := c.Locals("role").(int8)
+
+		if !rbacService.HasPermission(userID, role, perm) {
+			return response.Forbidden(c, "无权限访问")
+		}
+
+		// 通过 API Key 认证时，还需在 API Key 的权限范围内
+		if scopes, ok := c.Locals("apiKeyScopes").([]string); ok && !service.ScopeAllows(scopes, perm) {
+			return response.Forbidden(c, "API Key无权访问")
+		}
+
+		return c.Next()
+	}
+}
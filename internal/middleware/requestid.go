@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"strings"
+
+	"goboot/pkg/logger"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID 请求ID请求头/响应头
+const HeaderRequestID = "X-Request-ID"
+
+// HeaderTraceParent W3C Trace Context 请求头，格式: 版本-trace-id-parent-id-flags
+const HeaderTraceParent = "traceparent"
+
+// maxRequestIDLength 客户端传入请求ID的最大长度，超出或含非法字符时重新生成
+const maxRequestIDLength = 64
+
+// RequestID 请求ID中间件，优先使用客户端传入的 X-Request-ID，否则生成UUID
+// 请求ID写入 c.Locals("requestID")、响应头以及请求 context，供日志关联使用
+// 同时从 traceparent 请求头提取链路追踪ID，没有时以请求ID作为追踪ID，写入 c.Locals("traceID") 和请求 context
+func RequestID() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		id := c.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		traceID := parseTraceParent(c.Get(HeaderTraceParent))
+		if traceID == "" {
+			traceID = id
+		}
+
+		c.Locals("requestID", id)
+		c.Locals("traceID", traceID)
+		c.Set(HeaderRequestID, id)
+
+		ctx := logger.WithRequestID(c.Context(), id)
+		ctx = logger.WithTraceID(ctx, traceID)
+		c.SetContext(ctx)
+
+		return c.Next()
+	}
+}
+
+// parseTraceParent 从 traceparent 请求头中解析 trace-id，格式不合法或为全0时返回空
+func parseTraceParent(header string) string {
+	parts := strings.Split(header, "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ""
+	}
+	traceID := strings.ToLower(parts[1])
+	if strings.Trim(traceID, "0") == "" {
+		return ""
+	}
+	for i := 0; i < len(traceID); i++ {
+		if !strings.ContainsRune("0123456789abcdef", rune(traceID[i])) {
+			return ""
+		}
+	}
+	return traceID
+}
+
+// validRequestID 只接受长度合适的可见ASCII字符，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
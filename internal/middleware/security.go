@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"goboot/config"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// uploadsPrefix 上传文件静态服务路径前缀，可单独配置 X-Frame-Options
+const uploadsPrefix = "/uploads/"
+
+// SecurityHeaders 安全响应头中间件，每个响应头可单独配置，值为空则不设置
+func SecurityHeaders() fiber.Handler {
+	cfg := config.Get().SecurityHeaders
+	if !cfg.Enabled {
+		return func(c fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	var hsts string
+	if cfg.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c fiber.Ctx) error {
+		frameOptions := cfg.XFrameOptions
+		if strings.HasPrefix(c.Path(), uploadsPrefix) {
+			frameOptions = cfg.UploadsXFrameOptions
+		}
+
+		setHeader(c, fiber.HeaderXContentTypeOptions, cfg.XContentTypeOptions)
+		setHeader(c, fiber.HeaderXFrameOptions, frameOptions)
+		setHeader(c, fiber.HeaderReferrerPolicy, cfg.ReferrerPolicy)
+		setHeader(c, fiber.HeaderContentSecurityPolicy, cfg.ContentSecurityPolicy)
+		setHeader(c, fiber.HeaderStrictTransportSecurity, hsts)
+
+		return c.Next()
+	}
+}
+
+func setHeader(c fiber.Ctx, key, value string) {
+	if value != "" {
+		c.Set(key, value)
+	}
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"goboot/pkg/database"
+)
+
+// APIKey 服务账号使用的 API Key，只保存密钥的 SHA-256 哈希，明文仅在创建时返回一次
+type APIKey struct {
+	BaseModel
+	UserID     uint       `gorm:"index;not null" json:"userId"`          // 所属用户，请求以该用户身份执行
+	Name       string     `gorm:"size:64;not null" json:"name"`          // 名称，便于识别用途
+	Prefix     string     `gorm:"size:16" json:"prefix"`                 // 密钥前几位，便于识别，不可用于认证
+	KeyHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"` // 密钥哈希
+	Scopes     string     `gorm:"size:512" json:"scopes"`                // 允许使用的权限标识，逗号分隔，为空表示继承用户的全部权限
+	LastUsedAt *time.Time `json:"lastUsedAt"`                            // 最后使用时间
+	ExpiresAt  *time.Time `json:"expiresAt"`                             // 过期时间，为空表示永不过期
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// ScopeList 返回权限范围列表
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// IsExpired 是否已过期
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
+
+// CreateAPIKey 创建 API Key
+func CreateAPIKey(key *APIKey) error {
+	return database.DB.Create(key).Error
+}
+
+// GetAPIKeysByUser 获取用户的全部 API Key
+func GetAPIKeysByUser(userID uint) ([]APIKey, error) {
+	var keys []APIKey
+	err := database.DB.Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+// GetAPIKeyByHash 根据密钥哈希获取 API Key
+func GetAPIKeyByHash(hash string) (*APIKey, error) {
+	var key APIKey
+	if err := database.DB.Where("key_hash = ?", hash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// DeleteAPIKey 删除用户的指定 API Key，返回是否删除了记录
+func DeleteAPIKey(userID, id uint) (bool, error) {
+	result := database.DB.Unscoped().Where("id = ? AND user_id = ?", id, userID).Delete(&APIKey{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// TouchAPIKey 更新 API Key 的最后使用时间
+func TouchAPIKey(id uint, usedAt time.Time) error {
+	return database.DB.Model(&APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
@@ -1,13 +1,16 @@
 package model
 
 import (
+	"encoding/json"
 	"goboot/pkg/database"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // AuditLog 操作审计日志
 type AuditLog struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
+	BaseModel
 	UserID    uint      `json:"user_id" gorm:"index"`                    // 操作用户ID，0表示未登录
 	Username  string    `json:"username" gorm:"size:64"`                 // 操作用户名
 	Action    string    `json:"action" gorm:"size:32;index"`             // 操作类型
@@ -16,10 +19,47 @@ type AuditLog struct {
 	Detail    string    `json:"detail" gorm:"type:text"`                 // 操作详情
 	IP        string    `json:"ip" gorm:"size:64"`                       // 客户端IP
 	UserAgent string    `json:"user_agent" gorm:"size:256"`              // 客户端UA
-	Status    int       `json:"status" gorm:"default:1"`                 // 状态：1成功 0失败
+	Status    int       `json:"status" gorm:"not null"`                  // 状态：1成功 0失败（不设默认值，否则 GORM 会忽略失败状态的零值）
+	RequestID string    `json:"request_id" gorm:"size:64;index"`         // 请求ID，用于关联请求日志
+
+	// 覆盖 BaseModel.CreatedAt，保留按时间查询审计日志所需的索引
 	CreatedAt time.Time `json:"created_at" gorm:"index"`
 }
 
+// auditLogJSON 审计日志的接口输出结构，保持原有的 snake_case 字段名
+type auditLogJSON struct {
+	ID        uint      `json:"id"`
+	UserID    uint      `json:"user_id"`
+	Username  string    `json:"username"`
+	Action    string    `json:"action"`
+	Module    string    `json:"module"`
+	Target    string    `json:"target"`
+	Detail    string    `json:"detail"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Status    int       `json:"status"`
+	RequestID string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MarshalJSON 按 auditLogJSON 输出，避免嵌入 BaseModel 后接口字段名变化
+func (l AuditLog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(auditLogJSON{
+		ID:        l.ID,
+		UserID:    l.UserID,
+		Username:  l.Username,
+		Action:    l.Action,
+		Module:    l.Module,
+		Target:    l.Target,
+		Detail:    l.Detail,
+		IP:        l.IP,
+		UserAgent: l.UserAgent,
+		Status:    l.Status,
+		RequestID: l.RequestID,
+		CreatedAt: l.CreatedAt,
+	})
+}
+
 // 操作类型常量
 const (
 	ActionLogin          = "login"          // 登录
@@ -30,11 +70,20 @@ const (
 	ActionCreateUser     = "create_user"    // 创建用户
 	ActionUpdateUser     = "update_user"    // 更新用户
 	ActionDeleteUser     = "delete_user"    // 删除用户
+	ActionRestoreUser    = "restore_user"   // 恢复用户
+	ActionUnlockUser     = "unlock_user"    // 解除登录锁定
 	ActionUpdateStatus   = "update_status"  // 更新状态
 	ActionUpload         = "upload"         // 上传文件
 	ActionDelete         = "delete"         // 删除
 	ActionCreate         = "create"         // 创建
 	ActionUpdate         = "update"         // 更新
+	ActionExport         = "export"         // 导出
+	ActionImport         = "import"         // 导入
+	ActionAssignRoles    = "assign_roles"   // 分配角色
+	ActionVerifyEmail    = "verify_email"   // 验证邮箱
+	ActionForgotPassword = "forgot_pwd"     // 申请重置密码
+	ActionUpdateProfile  = "update_profile" // 更新个人信息
+	ActionResendVerify   = "resend_verify"  // 重新发送验证邮件
 )
 
 // 模块常量
@@ -44,6 +93,8 @@ const (
 	ModuleAdmin  = "admin"  // 管理模块
 	ModuleFile   = "file"   // 文件模块
 	ModuleConfig = "config" // 配置模块
+	ModuleRole   = "role"   // 角色权限模块
+	ModuleSystem = "system" // 系统运维模块
 )
 
 // CreateAuditLog 创建审计日志
@@ -51,13 +102,89 @@ func CreateAuditLog(log *AuditLog) error {
 	return database.DB.Create(log).Error
 }
 
+// CreateAuditLogs 批量创建审计日志
+func CreateAuditLogs(logs []*AuditLog) error {
+	return database.DB.CreateInBatches(logs, len(logs)).Error
+}
+
+// auditLogDeleteBatch 每批删除的审计日志条数，避免单条大删除语句长时间锁表
+const auditLogDeleteBatch = 1000
+
+// DeleteAuditLogsBefore 分批物理删除早于 t 的审计日志，返回删除的条数
+func DeleteAuditLogsBefore(t time.Time) (int64, error) {
+	var total int64
+	for {
+		var ids []uint
+		if err := database.DB.Unscoped().Model(&AuditLog{}).
+			Where("created_at < ?", t).
+			Order("id ASC").
+			Limit(auditLogDeleteBatch).
+			Pluck("id", &ids).Error; err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		result := database.DB.Unscoped().Delete(&AuditLog{}, ids)
+		if result.Error != nil {
+			return total, result.Error
+		}
+		total += result.RowsAffected
+
+		if len(ids) < auditLogDeleteBatch {
+			return total, nil
+		}
+	}
+}
+
 // GetAuditLogs 获取审计日志列表
-func GetAuditLogs(page, pageSize int, userID uint, action, module string, startTime, endTime *time.Time) ([]AuditLog, int64, error) {
+func GetAuditLogs(page, pageSize int, userID uint, action, module, keyword string, status *int, startTime, endTime *time.Time) ([]AuditLog, int64, error) {
 	var logs []AuditLog
-	var total int64
 
-	db := database.DB.Model(&AuditLog{})
+	db := filterAuditLogs(database.DB.Model(&AuditLog{}), userID, action, module, keyword, status, startTime, endTime)
+	total, err := database.Paginate(db.Order("created_at DESC"), page, pageSize, &logs)
+	if err != nil {
+		return nil, 0, err
+	}
 
+	return logs, total, nil
+}
+
+// GetAuditLogsByCursor 按游标获取审计日志，返回ID小于 cursor 的最多 limit 条记录(cursor 为 0 时从最新开始)，
+// 按ID倒序排列，hasMore 表示是否还有更早的记录
+func GetAuditLogsByCursor(cursor uint, limit int, userID uint, action, module, keyword string, status *int, startTime, endTime *time.Time) ([]AuditLog, bool, error) {
+	var logs []AuditLog
+
+	db := filterAuditLogs(database.DB.Model(&AuditLog{}), userID, action, module, keyword, status, startTime, endTime)
+	if cursor > 0 {
+		db = db.Where("id < ?", cursor)
+	}
+
+	// 多查一条用于判断是否还有下一页
+	if err := db.Order("id DESC").Limit(limit + 1).Find(&logs).Error; err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(logs) > limit
+	if hasMore {
+		logs = logs[:limit]
+	}
+	return logs, hasMore, nil
+}
+
+// FindAuditLogsInBatches 按ID顺序分批读取符合条件的审计日志，避免一次性加载全部数据，
+// fn 返回错误时停止读取
+func FindAuditLogsInBatches(userID uint, action, module, keyword string, status *int, startTime, endTime *time.Time, batchSize int, fn func(logs []AuditLog) error) error {
+	var logs []AuditLog
+	db := filterAuditLogs(database.DB.Model(&AuditLog{}), userID, action, module, keyword, status, startTime, endTime)
+	return db.FindInBatches(&logs, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(logs)
+	}).Error
+}
+
+// filterAuditLogs 添加审计日志的查询条件，keyword 在操作详情、操作目标和用户名中模糊匹配，status 为空表示不限状态
+func filterAuditLogs(db *gorm.DB, userID uint, action, module, keyword string, status *int, startTime, endTime *time.Time) *gorm.DB {
 	if userID > 0 {
 		db = db.Where("user_id = ?", userID)
 	}
@@ -67,21 +194,18 @@ func GetAuditLogs(page, pageSize int, userID uint, action, module string, startT
 	if module != "" {
 		db = db.Where("module = ?", module)
 	}
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		db = db.Where("detail LIKE ? OR target LIKE ? OR username LIKE ?", like, like, like)
+	}
+	if status != nil {
+		db = db.Where("status = ?", *status)
+	}
 	if startTime != nil {
 		db = db.Where("created_at >= ?", startTime)
 	}
 	if endTime != nil {
 		db = db.Where("created_at <= ?", endTime)
 	}
-
-	if err := db.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	offset := (page - 1) * pageSize
-	if err := db.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&logs).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return logs, total, nil
+	return db
 }
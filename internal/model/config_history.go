@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"goboot/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// ConfigHistory 系统配置变更历史
+type ConfigHistory struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ConfigKey string    `json:"configKey" gorm:"size:100;index;not null"` // 配置键
+	OldValue  string    `json:"oldValue" gorm:"type:text"`                // 变更前的值
+	NewValue  string    `json:"newValue" gorm:"type:text"`                // 变更后的值
+	ChangedBy string    `json:"changedBy" gorm:"size:64"`                 // 操作人，为空表示系统
+	ChangedAt time.Time `json:"changedAt" gorm:"index"`                   // 变更时间
+}
+
+// recordConfigChange 在事务中记录配置变更，值未变化时不记录
+func recordConfigChange(tx *gorm.DB, key, oldValue, newValue, changedBy string) error {
+	if oldValue == newValue {
+		return nil
+	}
+	return tx.Create(&ConfigHistory{
+		ConfigKey: key,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		ChangedBy: changedBy,
+		ChangedAt: time.Now(),
+	}).Error
+}
+
+// GetConfigHistory 获取配置变更历史，key 为空时返回全部
+func GetConfigHistory(key string, page, pageSize int) ([]ConfigHistory, int64, error) {
+	var histories []ConfigHistory
+
+	db := database.DB.Model(&ConfigHistory{})
+	if key != "" {
+		db = db.Where("config_key = ?", key)
+	}
+
+	total, err := database.Paginate(db.Order("id DESC"), page, pageSize, &histories)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return histories, total, nil
+}
+
+// GetConfigHistoryByID 根据ID获取变更记录
+func GetConfigHistoryByID(id uint) (*ConfigHistory, error) {
+	var history ConfigHistory
+	if err := database.DB.First(&history, id).Error; err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
@@ -1,11 +1,88 @@
 package model
 
-import "goboot/pkg/database"
+import (
+	"fmt"
+	"strings"
+
+	"goboot/pkg/database"
+
+	"gorm.io/gorm"
+)
 
 func AutoMigrate() error {
+	if err := prepareUserContactIndexes(); err != nil {
+		return err
+	}
+
 	return database.DB.AutoMigrate(
+		&Permission{},
+		&Role{},
 		&User{},
 		&AuditLog{},
 		&SysConfig{},
+		&ConfigHistory{},
+		&PasswordHistory{},
+		&APIKey{},
+		&UploadedFile{},
+		&WebhookDelivery{},
 	)
 }
+
+// prepareUserContactIndexes 邮箱、手机号由普通索引改为唯一索引前整理已有数据：
+// 空值改为 NULL，邮箱转为小写，已删除用户的邮箱和手机号移到 original_* 字段，
+// 然后删除原有的普通索引，由 AutoMigrate 创建唯一索引；存在重复数据时返回错误，需手动处理
+func prepareUserContactIndexes() error {
+	m := database.DB.Migrator()
+	if !m.HasTable(&User{}) || (m.HasIndex(&User{}, "uk_users_email") && m.HasIndex(&User{}, "uk_users_phone")) {
+		return nil
+	}
+
+	for _, field := range []string{"OriginalPhone", "OriginalEmail"} {
+		if !m.HasColumn(&User{}, field) {
+			if err := m.AddColumn(&User{}, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		users := func() *gorm.DB { return tx.Unscoped().Model(&User{}) }
+		for _, column := range []string{"phone", "email"} {
+			if err := users().Where(column+" = ?", "").UpdateColumn(column, nil).Error; err != nil {
+				return err
+			}
+			if err := users().Where("deleted_at IS NOT NULL AND " + column + " IS NOT NULL").UpdateColumns(map[string]interface{}{
+				"original_" + column: gorm.Expr(column),
+				column:               nil,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if err := users().Where("email IS NOT NULL").UpdateColumn("email", gorm.Expr("LOWER(email)")).Error; err != nil {
+			return err
+		}
+
+		for _, column := range []string{"phone", "email"} {
+			var duplicates []string
+			if err := users().Where(column+" IS NOT NULL").Group(column).Having("COUNT(*) > 1").Limit(10).Pluck(column, &duplicates).Error; err != nil {
+				return err
+			}
+			if len(duplicates) > 0 {
+				return fmt.Errorf("users.%s 存在重复数据，无法创建唯一索引，请处理后重试: %s", column, strings.Join(duplicates, ", "))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range []string{"idx_users_phone", "idx_users_email"} {
+		if m.HasIndex(&User{}, name) {
+			if err := m.DropIndex(&User{}, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"goboot/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// PasswordHistory 用户历史密码，用于禁止重复使用最近的密码
+type PasswordHistory struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"userId" gorm:"index;not null"` // 用户ID
+	Password  string    `json:"-" gorm:"size:255;not null"`   // 历史密码哈希
+	CreatedAt time.Time `json:"createdAt" gorm:"index"`       // 密码被替换的时间
+}
+
+// GetRecentPasswordHashes 获取用户最近 limit 个历史密码哈希
+func GetRecentPasswordHashes(userID uint, limit int) ([]string, error) {
+	var hashes []string
+	if limit <= 0 {
+		return hashes, nil
+	}
+	err := database.DB.Model(&PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Pluck("password", &hashes).Error
+	return hashes, err
+}
+
+// recordPasswordHistory 在事务中记录被替换的密码哈希，只保留最近 keep 条，keep<=0 时不记录
+func recordPasswordHistory(tx *gorm.DB, userID uint, hash string, keep int) error {
+	if keep <= 0 || hash == "" {
+		return nil
+	}
+	if err := tx.Create(&PasswordHistory{UserID: userID, Password: hash}).Error; err != nil {
+		return err
+	}
+
+	// 清理超出保留条数的旧记录
+	var keepIDs []uint
+	if err := tx.Model(&PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(keep).
+		Pluck("id", &keepIDs).Error; err != nil {
+		return err
+	}
+	return tx.Where("user_id = ? AND id NOT IN ?", userID, keepIDs).Delete(&PasswordHistory{}).Error
+}
+
+// UpdateUserPassword 更新用户密码及密码修改时间，同时将旧密码哈希记入历史，historyKeep 为保留的历史条数
+func UpdateUserPassword(user *User, hash string, historyKeep int) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := recordPasswordHistory(tx, user.ID, user.Password, historyKeep); err != nil {
+			return err
+		}
+		return tx.Model(user).Updates(map[string]interface{}{
+			"password":            hash,
+			"password_changed_at": time.Now(),
+		}).Error
+	})
+}
@@ -0,0 +1,222 @@
+package model
+
+import (
+	"fmt"
+
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// Permission 权限
+type Permission struct {
+	BaseModel
+	Code   string `gorm:"size:64;uniqueIndex;not null" json:"code"` // 权限标识，如 config:manage
+	Name   string `gorm:"size:64" json:"name"`                      // 权限名称
+	Remark string `gorm:"size:255" json:"remark"`                   // 备注说明
+}
+
+// Role 角色
+type Role struct {
+	BaseModel
+	Code        string       `gorm:"size:64;uniqueIndex;not null" json:"code"`                // 角色标识
+	Name        string       `gorm:"size:64" json:"name"`                                     // 角色名称
+	Remark      string       `gorm:"size:255" json:"remark"`                                  // 备注说明
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions,omitempty"` // 角色拥有的权限
+}
+
+// 权限标识常量
+const (
+	PermAll          = "*"             // 全部权限
+	PermUserManage   = "user:manage"   // 用户管理
+	PermAuditView    = "audit:view"    // 查看审计日志
+	PermConfigManage = "config:manage" // 系统配置管理
+	PermRoleManage   = "role:manage"   // 角色权限管理
+	PermLogManage    = "log:manage"    // 运行时日志级别调整
+)
+
+// 内置角色常量
+const (
+	RoleSuperAdmin  = "superadmin"   // 超级管理员，拥有全部权限
+	RoleConfigAdmin = "config_admin" // 配置管理员
+	RoleAuditAdmin  = "audit_admin"  // 审计管理员
+)
+
+// 默认权限列表
+var defaultPermissions = []Permission{
+	{Code: PermAll, Name: "全部权限", Remark: "拥有系统全部权限"},
+	{Code: PermUserManage, Name: "用户管理", Remark: "管理用户账号"},
+	{Code: PermAuditView, Name: "查看审计日志", Remark: "查看操作审计日志"},
+	{Code: PermConfigManage, Name: "系统配置管理", Remark: "查看和修改系统配置"},
+	{Code: PermRoleManage, Name: "角色权限管理", Remark: "管理角色及用户角色分配"},
+	{Code: PermLogManage, Name: "日志级别管理", Remark: "运行时查看和调整日志级别"},
+}
+
+// 默认角色及其权限
+var defaultRoles = []struct {
+	Role        Role
+	Permissions []string
+}{
+	{Role: Role{Code: RoleSuperAdmin, Name: "超级管理员", Remark: "拥有全部权限，等同于 role=1 的管理员"}, Permissions: []string{PermAll}},
+	{Role: Role{Code: RoleConfigAdmin, Name: "配置管理员", Remark: "仅可管理系统配置"}, Permissions: []string{PermConfigManage}},
+	{Role: Role{Code: RoleAuditAdmin, Name: "审计管理员", Remark: "仅可查看审计日志"}, Permissions: []string{PermAuditView}},
+}
+
+// InitDefaultRBAC 初始化默认权限和角色
+// 只会插入不存在的权限和角色，不会修改已有角色的权限
+func InitDefaultRBAC() error {
+	var insertCount int
+
+	for _, perm := range defaultPermissions {
+		var count int64
+		database.DB.Model(&Permission{}).Where("code = ?", perm.Code).Count(&count)
+		if count > 0 {
+			continue
+		}
+		if err := database.DB.Create(&perm).Error; err != nil {
+			logger.Error("初始化权限失败: " + perm.Code + " - " + err.Error())
+			continue
+		}
+		insertCount++
+	}
+
+	for _, item := range defaultRoles {
+		var count int64
+		database.DB.Model(&Role{}).Where("code = ?", item.Role.Code).Count(&count)
+		if count > 0 {
+			continue
+		}
+
+		var perms []Permission
+		if err := database.DB.Where("code IN ?", item.Permissions).Find(&perms).Error; err != nil {
+			logger.Error("初始化角色失败: " + item.Role.Code + " - " + err.Error())
+			continue
+		}
+		role := item.Role
+		role.Permissions = perms
+		if err := database.DB.Create(&role).Error; err != nil {
+			logger.Error("初始化角色失败: " + item.Role.Code + " - " + err.Error())
+			continue
+		}
+		insertCount++
+	}
+
+	if insertCount > 0 {
+		logger.Info(fmt.Sprintf("初始化角色权限完成，新增 %d 条记录", insertCount))
+	}
+
+	return nil
+}
+
+// GetUserPermissionCodes 获取用户通过角色获得的全部权限标识
+func GetUserPermissionCodes(userID uint) ([]string, error) {
+	var codes []string
+	err := database.DB.Model(&Permission{}).
+		Distinct("permissions.code").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id AND roles.deleted_at IS NULL").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("permissions.code", &codes).Error
+	return codes, err
+}
+
+// GetAllPermissions 获取所有权限
+func GetAllPermissions() ([]Permission, error) {
+	var perms []Permission
+	err := database.DB.Order("id ASC").Find(&perms).Error
+	return perms, err
+}
+
+// GetAllRoles 获取所有角色及其权限
+func GetAllRoles() ([]Role, error) {
+	var roles []Role
+	err := database.DB.Preload("Permissions").Order("id ASC").Find(&roles).Error
+	return roles, err
+}
+
+// GetRoleByID 根据ID获取角色及其权限
+func GetRoleByID(id uint) (*Role, error) {
+	var role Role
+	if err := database.DB.Preload("Permissions").First(&role, id).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// RoleExists 检查角色标识是否存在
+func RoleExists(code string) bool {
+	var count int64
+	database.DB.Model(&Role{}).Where("code = ?", code).Count(&count)
+	return count > 0
+}
+
+// GetPermissionsByIDs 根据ID列表获取权限
+func GetPermissionsByIDs(ids []uint) ([]Permission, error) {
+	var perms []Permission
+	if len(ids) == 0 {
+		return perms, nil
+	}
+	err := database.DB.Where("id IN ?", ids).Find(&perms).Error
+	return perms, err
+}
+
+// CreateRole 创建角色
+func CreateRole(role *Role) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		// 清理同名的已删除角色，避免与唯一索引冲突
+		if err := tx.Unscoped().Where("code = ? AND deleted_at IS NOT NULL", role.Code).Delete(&Role{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(role).Error
+	})
+}
+
+// UpdateRole 更新角色信息并替换其权限
+func UpdateRole(role *Role, perms []Permission) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Updates(map[string]interface{}{
+			"name":   role.Name,
+			"remark": role.Remark,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(role).Association("Permissions").Replace(perms)
+	})
+}
+
+// DeleteRole 删除角色，同时解除其与权限、用户的关联
+func DeleteRole(role *Role) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Table("user_roles").Where("role_id = ?", role.ID).Delete(nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(role).Error
+	})
+}
+
+// GetRolesByIDs 根据ID列表获取角色及其权限
+func GetRolesByIDs(ids []uint) ([]Role, error) {
+	var roles []Role
+	if len(ids) == 0 {
+		return roles, nil
+	}
+	err := database.DB.Preload("Permissions").Where("id IN ?", ids).Find(&roles).Error
+	return roles, err
+}
+
+// GetUserRoles 获取用户的角色
+func GetUserRoles(userID uint) ([]Role, error) {
+	var roles []Role
+	err := database.DB.Model(&User{BaseModel: BaseModel{ID: userID}}).Association("Roles").Find(&roles)
+	return roles, err
+}
+
+// SetUserRoles 替换用户的角色
+func SetUserRoles(user *User, roles []Role) error {
+	return database.DB.Model(user).Association("Roles").Replace(roles)
+}
@@ -1,26 +1,31 @@
 package model
 
 import (
-	"time"
+	"context"
+	"errors"
 
 	"goboot/pkg/database"
+
+	"gorm.io/gorm"
 )
 
 // SysConfig 系统配置模型
 type SysConfig struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	ConfigKey   string    `json:"configKey" gorm:"size:100;uniqueIndex;not null"` // 配置键
-	ConfigValue string    `json:"configValue" gorm:"type:text"`                   // 配置值
-	ConfigType  string    `json:"configType" gorm:"size:20;default:string"`       // 值类型: string, int, bool, json
-	ConfigGroup string    `json:"configGroup" gorm:"size:50;index;default:basic"` // 配置分组
-	Name        string    `json:"name" gorm:"size:100"`                           // 配置名称(中文)
-	Remark      string    `json:"remark" gorm:"size:255"`                         // 备注说明
-	Sort        int       `json:"sort" gorm:"default:0"`                          // 排序
-	IsPublic    bool      `json:"isPublic" gorm:"default:false"`                  // 是否公开(前端可获取)
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	BaseModel
+	ConfigKey   string `json:"configKey" gorm:"size:100;uniqueIndex;not null"` // 配置键
+	ConfigValue string `json:"configValue" gorm:"type:text"`                   // 配置值
+	ConfigType  string `json:"configType" gorm:"size:20;default:string"`       // 值类型: string, int, bool, json
+	ConfigGroup string `json:"configGroup" gorm:"size:50;index;default:basic"` // 配置分组
+	Name        string `json:"name" gorm:"size:100"`                           // 配置名称(中文)
+	Remark      string `json:"remark" gorm:"size:255"`                         // 备注说明
+	Sort        int    `json:"sort" gorm:"default:0"`                          // 排序
+	IsPublic    bool   `json:"isPublic" gorm:"default:false"`                  // 是否公开(前端可获取)
+	Version     int    `json:"version" gorm:"not null;default:1"`              // 版本号，每次修改加1，用于乐观锁
 }
 
+// ErrConfigConflict 更新时配置的版本号与数据库不一致，说明读取后已被他人修改
+var ErrConfigConflict = errors.New("配置已被他人修改")
+
 // 配置分组常量
 const (
 	ConfigGroupBasic    = "basic"    // 基础配置
@@ -40,7 +45,10 @@ const (
 // GetConfigByKey 根据key获取配置
 func GetConfigByKey(key string) (*SysConfig, error) {
 	var config SysConfig
-	err := database.DB.Where("config_key = ?", key).First(&config).Error
+	// 配置只在刷新缓存时读库，读主库避免刚写入后读到从库的旧值
+	db, cancel := database.PrimaryWithTimeout(context.Background())
+	defer cancel()
+	err := db.Where("config_key = ?", key).First(&config).Error
 	if err != nil {
 		return nil, err
 	}
@@ -49,38 +57,95 @@ func GetConfigByKey(key string) (*SysConfig, error) {
 
 // GetConfigsByGroup 根据分组获取配置列表
 func GetConfigsByGroup(group string) ([]SysConfig, error) {
+	db, cancel := database.PrimaryWithTimeout(context.Background())
+	defer cancel()
+
 	var configs []SysConfig
-	err := database.DB.Where("config_group = ?", group).Order("sort ASC, id ASC").Find(&configs).Error
+	err := db.Where("config_group = ?", group).Order("sort ASC, id ASC").Find(&configs).Error
 	return configs, err
 }
 
 // GetAllConfigs 获取所有配置
 func GetAllConfigs() ([]SysConfig, error) {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
 	var configs []SysConfig
-	err := database.DB.Order("config_group ASC, sort ASC, id ASC").Find(&configs).Error
+	err := db.Order("config_group ASC, sort ASC, id ASC").Find(&configs).Error
 	return configs, err
 }
 
 // GetPublicConfigs 获取所有公开配置
 func GetPublicConfigs() ([]SysConfig, error) {
+	db, cancel := database.WithTimeout(context.Background())
+	defer cancel()
+
 	var configs []SysConfig
-	err := database.DB.Where("is_public = ?", true).Order("config_group ASC, sort ASC").Find(&configs).Error
+	err := db.Where("is_public = ?", true).Order("config_group ASC, sort ASC").Find(&configs).Error
 	return configs, err
 }
 
 // CreateConfig 创建配置
 func CreateConfig(config *SysConfig) error {
-	return database.DB.Create(config).Error
+	return database.Transaction(func(tx *gorm.DB) error {
+		if err := purgeDeletedConfig(tx, config.ConfigKey); err != nil {
+			return err
+		}
+		config.Version = 1
+		return tx.Create(config).Error
+	})
+}
+
+// purgeDeletedConfig 彻底删除同名的已软删除配置，避免与配置键唯一索引冲突
+func purgeDeletedConfig(tx *gorm.DB, key string) error {
+	return tx.Unscoped().Where("config_key = ? AND deleted_at IS NOT NULL", key).Delete(&SysConfig{}).Error
+}
+
+// UpdateConfig 更新配置，并记录配置值的变更历史
+// config.Version 为客户端读取时的版本号，与数据库不一致时返回 ErrConfigConflict，成功后版本号加1
+func UpdateConfig(config *SysConfig, changedBy string) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		var old SysConfig
+		if err := tx.First(&old, config.ID).Error; err != nil {
+			return err
+		}
+
+		version := config.Version
+		config.CreatedAt = old.CreatedAt
+		config.Version = version + 1
+		result := tx.Model(config).Where("version = ?", version).Select("*").Updates(config)
+		if result.Error != nil {
+			config.Version = version
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			config.Version = version
+			return ErrConfigConflict
+		}
+		return recordConfigChange(tx, config.ConfigKey, old.ConfigValue, config.ConfigValue, changedBy)
+	})
 }
 
-// UpdateConfig 更新配置
-func UpdateConfig(config *SysConfig) error {
-	return database.DB.Save(config).Error
+// UpdateConfigValue 只更新配置值，并记录变更历史
+func UpdateConfigValue(key, value, changedBy string) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		return updateConfigValue(tx, key, value, changedBy)
+	})
 }
 
-// UpdateConfigValue 只更新配置值
-func UpdateConfigValue(key, value string) error {
-	return database.DB.Model(&SysConfig{}).Where("config_key = ?", key).Update("config_value", value).Error
+// updateConfigValue 在事务中更新配置值并记录变更历史
+func updateConfigValue(tx *gorm.DB, key, value, changedBy string) error {
+	var old SysConfig
+	if err := tx.Where("config_key = ?", key).First(&old).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&old).Updates(map[string]interface{}{
+		"config_value": value,
+		"version":      gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		return err
+	}
+	return recordConfigChange(tx, key, old.ConfigValue, value, changedBy)
 }
 
 // DeleteConfig 删除配置
@@ -88,16 +153,63 @@ func DeleteConfig(id uint) error {
 	return database.DB.Delete(&SysConfig{}, id).Error
 }
 
-// BatchUpdateConfigs 批量更新配置值
-func BatchUpdateConfigs(configs map[string]string) error {
-	tx := database.DB.Begin()
-	for key, value := range configs {
-		if err := tx.Model(&SysConfig{}).Where("config_key = ?", key).Update("config_value", value).Error; err != nil {
-			tx.Rollback()
-			return err
+// BatchUpdateConfigs 批量更新配置值，并记录变更历史
+func BatchUpdateConfigs(configs map[string]string, changedBy string) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		for key, value := range configs {
+			if err := updateConfigValue(tx, key, value, changedBy); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+}
+
+// ImportConfigs 在同一事务中按配置键导入配置：不存在则创建，已存在且 overwrite 为 true 时覆盖，
+// 返回实际写入的配置键
+func ImportConfigs(configs []SysConfig, overwrite bool, changedBy string) ([]string, error) {
+	var keys []string
+	err := database.Transaction(func(tx *gorm.DB) error {
+		for i := range configs {
+			config := configs[i]
+
+			var old SysConfig
+			err := tx.Where("config_key = ?", config.ConfigKey).First(&old).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := purgeDeletedConfig(tx, config.ConfigKey); err != nil {
+					return err
+				}
+				config.Version = 1
+				if err := tx.Create(&config).Error; err != nil {
+					return err
+				}
+				keys = append(keys, config.ConfigKey)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if !overwrite {
+				continue
+			}
+
+			config.ID = old.ID
+			config.CreatedAt = old.CreatedAt
+			config.Version = old.Version + 1
+			if err := tx.Save(&config).Error; err != nil {
+				return err
+			}
+			if err := recordConfigChange(tx, config.ConfigKey, old.ConfigValue, config.ConfigValue, changedBy); err != nil {
+				return err
+			}
+			keys = append(keys, config.ConfigKey)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return tx.Commit().Error
+	return keys, nil
 }
 
 // ConfigExists 检查配置是否存在
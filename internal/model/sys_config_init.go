@@ -1,10 +1,15 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
+	"goboot/config"
 	"goboot/pkg/database"
 	"goboot/pkg/logger"
+
+	"gorm.io/gorm"
 )
 
 // 默认配置列表
@@ -15,6 +20,8 @@ var defaultConfigs = []SysConfig{
 	{ConfigKey: "site_description", ConfigValue: "基于Go的现代化Web框架", ConfigType: ConfigTypeString, ConfigGroup: ConfigGroupBasic, Name: "网站描述", Remark: "网站SEO描述", Sort: 3, IsPublic: true},
 	{ConfigKey: "site_keywords", ConfigValue: "go,golang,fiber,web", ConfigType: ConfigTypeString, ConfigGroup: ConfigGroupBasic, Name: "网站关键词", Remark: "网站SEO关键词", Sort: 4, IsPublic: true},
 	{ConfigKey: "site_icp", ConfigValue: "", ConfigType: ConfigTypeString, ConfigGroup: ConfigGroupBasic, Name: "ICP备案号", Remark: "网站ICP备案号", Sort: 5, IsPublic: true},
+	{ConfigKey: "maintenance_enabled", ConfigValue: "false", ConfigType: ConfigTypeBool, ConfigGroup: ConfigGroupBasic, Name: "维护模式", Remark: "开启后除健康检查、登录和管理接口外的请求均返回503", Sort: 6, IsPublic: true},
+	{ConfigKey: "maintenance_message", ConfigValue: "系统维护中，请稍后再试", ConfigType: ConfigTypeString, ConfigGroup: ConfigGroupBasic, Name: "维护提示", Remark: "维护模式下返回给用户的提示信息", Sort: 7, IsPublic: true},
 
 	// ============ 邮件配置 ============
 	{ConfigKey: "email_enabled", ConfigValue: "false", ConfigType: ConfigTypeBool, ConfigGroup: ConfigGroupEmail, Name: "启用邮件服务", Remark: "是否启用邮件发送功能", Sort: 1, IsPublic: false},
@@ -27,6 +34,11 @@ var defaultConfigs = []SysConfig{
 	{ConfigKey: "email_ssl", ConfigValue: "true", ConfigType: ConfigTypeBool, ConfigGroup: ConfigGroupEmail, Name: "启用SSL", Remark: "是否使用SSL加密连接", Sort: 8, IsPublic: false},
 	{ConfigKey: "email_reset_url", ConfigValue: "http://localhost:3000/reset-password", ConfigType: ConfigTypeString, ConfigGroup: ConfigGroupEmail, Name: "密码重置URL", Remark: "密码重置页面地址", Sort: 9, IsPublic: false},
 	{ConfigKey: "email_reset_expire", ConfigValue: "30", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupEmail, Name: "重置链接有效期", Remark: "密码重置链接有效期(分钟)", Sort: 10, IsPublic: false},
+	{ConfigKey: "email_verify_required", ConfigValue: "false", ConfigType: ConfigTypeBool, ConfigGroup: ConfigGroupEmail, Name: "注册需验证邮箱", Remark: "启用邮件服务时，注册用户需验证邮箱后才能登录", Sort: 11, IsPublic: true},
+	{ConfigKey: "email_verify_url", ConfigValue: "http://127.0.0.1:8080/api/auth/verifyEmail", ConfigType: ConfigTypeString, ConfigGroup: ConfigGroupEmail, Name: "邮箱验证URL", Remark: "邮箱验证链接地址，可指向前端页面或验证接口", Sort: 12, IsPublic: false},
+	{ConfigKey: "email_verify_expire", ConfigValue: "1440", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupEmail, Name: "验证链接有效期", Remark: "邮箱验证链接有效期(分钟)", Sort: 13, IsPublic: false},
+	{ConfigKey: "email_pool_size", ConfigValue: "2", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupEmail, Name: "SMTP连接池大小", Remark: "复用的SMTP空闲连接数上限", Sort: 14, IsPublic: false},
+	{ConfigKey: "email_pool_idle_timeout", ConfigValue: "60", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupEmail, Name: "SMTP空闲超时", Remark: "SMTP空闲连接超过该时间(秒)后关闭", Sort: 15, IsPublic: false},
 
 	// ============ 上传配置 ============
 	{ConfigKey: "upload_enabled", ConfigValue: "true", ConfigType: ConfigTypeBool, ConfigGroup: ConfigGroupUpload, Name: "启用上传服务", Remark: "是否启用文件上传功能", Sort: 1, IsPublic: false},
@@ -37,12 +49,25 @@ var defaultConfigs = []SysConfig{
 	{ConfigKey: "upload_max_image_size", ConfigValue: "5", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupUpload, Name: "最大图片大小", Remark: "最大上传图片大小(MB)", Sort: 6, IsPublic: false},
 	{ConfigKey: "upload_allowed_exts", ConfigValue: `[".jpg",".jpeg",".png",".gif",".webp",".pdf",".doc",".docx",".xls",".xlsx",".zip",".rar"]`, ConfigType: ConfigTypeJSON, ConfigGroup: ConfigGroupUpload, Name: "允许的文件类型", Remark: "允许上传的文件扩展名", Sort: 7, IsPublic: false},
 	{ConfigKey: "upload_image_exts", ConfigValue: `[".jpg",".jpeg",".png",".gif",".webp"]`, ConfigType: ConfigTypeJSON, ConfigGroup: ConfigGroupUpload, Name: "允许的图片类型", Remark: "允许上传的图片扩展名", Sort: 8, IsPublic: false},
+	{ConfigKey: "upload_max_avatar_size", ConfigValue: "2", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupUpload, Name: "最大头像大小", Remark: "最大上传头像大小(MB)", Sort: 9, IsPublic: false},
+	{ConfigKey: "upload_strip_exif", ConfigValue: "true", ConfigType: ConfigTypeBool, ConfigGroup: ConfigGroupUpload, Name: "去除图片元数据", Remark: "上传 JPEG/WebP 图片时去除 EXIF(含GPS定位)等元数据", Sort: 10, IsPublic: false},
+	{ConfigKey: "upload_auto_orient", ConfigValue: "true", ConfigType: ConfigTypeBool, ConfigGroup: ConfigGroupUpload, Name: "图片自动旋转", Remark: "上传 JPEG 图片时按 EXIF 方向标记自动旋转", Sort: 11, IsPublic: false},
+	{ConfigKey: "upload_image_quality", ConfigValue: "85", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupUpload, Name: "图片压缩质量", Remark: "图片重新编码质量(1-100)", Sort: 12, IsPublic: false},
+	{ConfigKey: "upload_dedup", ConfigValue: "false", ConfigType: ConfigTypeBool, ConfigGroup: ConfigGroupUpload, Name: "文件去重", Remark: "按内容哈希去重，相同文件只保存一份，全部引用删除后才删除文件", Sort: 13, IsPublic: false},
+	{ConfigKey: "upload_concurrency", ConfigValue: "4", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupUpload, Name: "批量上传并发数", Remark: "批量上传时同时上传的文件数(1-32)", Sort: 14, IsPublic: false},
+	{ConfigKey: "upload_active_content", ConfigValue: "reject", ConfigType: ConfigTypeString, ConfigGroup: ConfigGroupUpload, Name: "SVG/HTML处理策略", Remark: "按内容识别的 SVG/HTML 文件: reject 拒绝, sanitize 清理SVG中的脚本(HTML仍拒绝), allow 原样保存", Sort: 15, IsPublic: false},
 
 	// ============ 安全配置 ============
 	{ConfigKey: "security_max_login_attempts", ConfigValue: "5", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupSecurity, Name: "最大登录尝试", Remark: "登录失败最大尝试次数", Sort: 1, IsPublic: false},
 	{ConfigKey: "security_lockout_duration", ConfigValue: "30", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupSecurity, Name: "锁定时长", Remark: "账户锁定时长(分钟)", Sort: 2, IsPublic: false},
 	{ConfigKey: "security_password_min_length", ConfigValue: "6", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupSecurity, Name: "密码最小长度", Remark: "用户密码最小长度", Sort: 3, IsPublic: false},
 	{ConfigKey: "security_session_timeout", ConfigValue: "120", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupSecurity, Name: "会话超时", Remark: "用户会话超时时间(分钟)", Sort: 4, IsPublic: false},
+	{ConfigKey: "security_password_history", ConfigValue: "5", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupSecurity, Name: "历史密码检查", Remark: "修改密码时不能与当前密码及最近N个历史密码相同，0表示不检查", Sort: 5, IsPublic: false},
+	{ConfigKey: "security_password_history_admin", ConfigValue: "false", ConfigType: ConfigTypeBool, ConfigGroup: ConfigGroupSecurity, Name: "重置密码检查历史", Remark: "管理员重置密码及邮件找回密码时是否同样检查历史密码", Sort: 6, IsPublic: false},
+	{ConfigKey: "security_password_max_age_days", ConfigValue: "0", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupSecurity, Name: "密码有效期", Remark: "密码有效天数，过期后登录需先修改密码，0表示永不过期", Sort: 7, IsPublic: false},
+	{ConfigKey: "security_email_limit_per_email", ConfigValue: "3", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupSecurity, Name: "找回密码邮箱限制", Remark: "时间窗口内同一邮箱可请求找回密码或重发验证邮件的次数，0表示不限制", Sort: 8, IsPublic: false},
+	{ConfigKey: "security_email_limit_per_ip", ConfigValue: "20", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupSecurity, Name: "找回密码IP限制", Remark: "时间窗口内同一IP可请求找回密码、重置密码或重发验证邮件的次数，0表示不限制", Sort: 9, IsPublic: false},
+	{ConfigKey: "security_email_limit_window", ConfigValue: "60", ConfigType: ConfigTypeInt, ConfigGroup: ConfigGroupSecurity, Name: "找回密码限制窗口", Remark: "找回密码、重置密码和重发验证邮件限流的时间窗口(分钟)", Sort: 10, IsPublic: false},
 }
 
 // InitDefaultConfigs 初始化默认配置
@@ -53,6 +78,7 @@ func InitDefaultConfigs() error {
 	for _, cfg := range defaultConfigs {
 		// 检查配置是否已存在
 		if !ConfigExists(cfg.ConfigKey) {
+			cfg.ConfigValue = bootstrapValue(cfg)
 			if err := database.DB.Create(&cfg).Error; err != nil {
 				logger.Error("初始化配置失败: " + cfg.ConfigKey + " - " + err.Error())
 				continue
@@ -71,13 +97,14 @@ func InitDefaultConfigs() error {
 // ResetDefaultConfigs 重置为默认配置
 // 警告: 这将覆盖所有已有配置
 func ResetDefaultConfigs() error {
-	// 删除所有配置
-	if err := database.DB.Exec("DELETE FROM sys_configs").Error; err != nil {
+	// 彻底删除所有配置(包括已软删除的记录，避免与配置键唯一索引冲突)
+	if err := database.DB.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(&SysConfig{}).Error; err != nil {
 		return err
 	}
 
 	// 重新插入默认配置
 	for _, cfg := range defaultConfigs {
+		cfg.ConfigValue = bootstrapValue(cfg)
 		if err := database.DB.Create(&cfg).Error; err != nil {
 			logger.Error("重置配置失败: " + cfg.ConfigKey + " - " + err.Error())
 			continue
@@ -87,3 +114,47 @@ func ResetDefaultConfigs() error {
 	logger.Info("系统配置已重置为默认值")
 	return nil
 }
+
+// bootstrapValue 系统配置首次写入数据库时的值：上传配置优先使用配置文件 upload 段中设置的值，
+// 其余使用内置默认值；写入后以数据库为准，修改配置文件不再影响已有配置
+func bootstrapValue(cfg SysConfig) string {
+	appCfg := config.Get()
+	if appCfg == nil || !strings.HasPrefix(cfg.ConfigKey, "upload_") {
+		return cfg.ConfigValue
+	}
+	fileKey := "upload." + strings.TrimPrefix(cfg.ConfigKey, "upload_")
+	value, ok := uploadFileValues(&appCfg.Upload)[cfg.ConfigKey]
+	if !ok || !config.IsSet(fileKey) {
+		return cfg.ConfigValue
+	}
+
+	if exts, ok := value.([]string); ok {
+		data, err := json.Marshal(exts)
+		if err != nil {
+			return cfg.ConfigValue
+		}
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+// uploadFileValues 配置文件 upload 段中各项对应的系统配置
+func uploadFileValues(cfg *config.UploadConfig) map[string]any {
+	return map[string]any{
+		"upload_enabled":         cfg.Enabled,
+		"upload_storage_type":    cfg.StorageType,
+		"upload_local_path":      cfg.LocalPath,
+		"upload_base_url":        cfg.BaseURL,
+		"upload_max_size":        cfg.MaxSize,
+		"upload_max_image_size":  cfg.MaxImageSize,
+		"upload_max_avatar_size": cfg.MaxAvatarSize,
+		"upload_allowed_exts":    cfg.AllowedExts,
+		"upload_image_exts":      cfg.ImageExts,
+		"upload_strip_exif":      cfg.StripExif,
+		"upload_auto_orient":     cfg.AutoOrient,
+		"upload_image_quality":   cfg.ImageQuality,
+		"upload_dedup":           cfg.Dedup,
+		"upload_concurrency":     cfg.Concurrency,
+		"upload_active_content":  cfg.ActiveContent,
+	}
+}
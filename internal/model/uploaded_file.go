@@ -0,0 +1,64 @@
+package model
+
+import "goboot/pkg/database"
+
+// UploadedFile 上传文件记录，每次上传一条。开启去重时内容相同的文件共用同一个存储路径，
+// 同一路径的记录数即引用计数，最后一条记录删除时才删除文件
+type UploadedFile struct {
+	BaseModel
+	UserID    uint   `gorm:"index" json:"userId"`        // 上传用户
+	Name      string `gorm:"size:255" json:"name"`       // 原始文件名
+	Path      string `gorm:"size:500;index" json:"path"` // 存储路径
+	Hash      string `gorm:"size:64;index" json:"hash"`  // 文件内容的 SHA-256
+	Size      int64  `json:"size"`                       // 文件大小(字节)
+	MimeType  string `gorm:"size:128" json:"mimeType"`   // MIME 类型
+	Extension string `gorm:"size:20" json:"extension"`   // 扩展名
+}
+
+func (UploadedFile) TableName() string {
+	return "uploaded_files"
+}
+
+// CreateUploadedFile 创建上传文件记录
+func CreateUploadedFile(file *UploadedFile) error {
+	return database.DB.Create(file).Error
+}
+
+// GetUploadedFileByHash 根据内容哈希和扩展名获取最早的上传记录
+func GetUploadedFileByHash(hash, ext string) (*UploadedFile, error) {
+	var file UploadedFile
+	if err := database.DB.Where("hash = ? AND extension = ?", hash, ext).Order("id").First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetUploadedFileByUser 获取用户对指定路径的上传记录
+func GetUploadedFileByUser(userID uint, path string) (*UploadedFile, error) {
+	var file UploadedFile
+	if err := database.DB.Where("user_id = ? AND path = ?", userID, path).Order("id").First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetUploadedFileByPath 获取指定路径最早的上传记录
+func GetUploadedFileByPath(path string) (*UploadedFile, error) {
+	var file UploadedFile
+	if err := database.DB.Where("path = ?", path).Order("id").First(&file).Error; err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// CountUploadedFileRefs 统计引用指定路径的上传记录数
+func CountUploadedFileRefs(path string) (int64, error) {
+	var count int64
+	err := database.DB.Model(&UploadedFile{}).Where("path = ?", path).Count(&count).Error
+	return count, err
+}
+
+// DeleteUploadedFile 删除上传文件记录
+func DeleteUploadedFile(id uint) error {
+	return database.DB.Unscoped().Delete(&UploadedFile{}, id).Error
+}
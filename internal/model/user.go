@@ -1,17 +1,55 @@
 package model
 
+import (
+	"strings"
+	"time"
+)
+
 type User struct {
 	BaseModel
 	Username string `gorm:"size:50;uniqueIndex;not null" json:"username"`
 	Password string `gorm:"size:255;not null" json:"-"`
 	Nickname string `gorm:"size:50" json:"nickname"`
-	Phone    string `gorm:"size:20;index" json:"phone"`
-	Email    string `gorm:"size:100;index" json:"email"`
+	Phone    string `gorm:"size:20;uniqueIndex:uk_users_phone;default:null" json:"phone"`  // 为空时存为 NULL，唯一索引允许多个 NULL
+	Email    string `gorm:"size:100;uniqueIndex:uk_users_email;default:null" json:"email"` // 统一存为小写，为空时存为 NULL
 	Avatar   string `gorm:"size:255" json:"avatar"`
-	Status   int8   `gorm:"default:1" json:"status"` // 1: active, 0: disabled
+	Status   int8   `gorm:"default:1" json:"status"` // 1: active, 0: disabled, 2: pending(待邮箱验证)
 	Role     int8   `gorm:"default:0" json:"role"`   // 0: user, 1: admin
+
+	LastLoginAt *time.Time `json:"lastLoginAt"`                // 最后登录时间
+	LastLoginIP string     `gorm:"size:64" json:"lastLoginIp"` // 最后登录IP
+
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"` // 邮箱验证时间，为空表示未验证
+
+	PasswordChangedAt *time.Time `json:"passwordChangedAt"` // 最后修改密码时间，为空表示注册后未修改过
+
+	OriginalUsername string `gorm:"size:50" json:"-"`  // 软删除前的用户名，恢复时使用
+	OriginalPhone    string `gorm:"size:20" json:"-"`  // 软删除前的手机号，恢复时使用
+	OriginalEmail    string `gorm:"size:100" json:"-"` // 软删除前的邮箱，恢复时使用
+
+	Roles []Role `gorm:"many2many:user_roles" json:"roles,omitempty"` // RBAC角色，Role=1 的管理员不依赖此字段
 }
 
+// 用户状态
+const (
+	UserStatusDisabled int8 = 0 // 禁用
+	UserStatusActive   int8 = 1 // 正常
+	UserStatusPending  int8 = 2 // 待邮箱验证
+)
+
 func (User) TableName() string {
 	return "users"
 }
+
+// NormalizeEmail 规范化邮箱，去除首尾空格并转为小写，写入和查询时统一使用
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NullableContact 将空的手机号或邮箱转为 NULL，用于 Updates 的 map 参数
+func NullableContact(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
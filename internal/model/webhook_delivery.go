@@ -0,0 +1,31 @@
+package model
+
+import "goboot/pkg/database"
+
+// 投递状态
+const (
+	WebhookDeliveryFailed  = 0 // 重试次数用尽仍失败
+	WebhookDeliverySuccess = 1 // 目标返回 2xx
+)
+
+// WebhookDelivery 事件通知投递记录，每个事件投递到每个地址(含重试)结束后记录一条
+type WebhookDelivery struct {
+	BaseModel
+	DeliveryID string `gorm:"size:36;uniqueIndex" json:"deliveryId"` // 投递ID，与请求头 X-Webhook-ID 相同
+	Event      string `gorm:"size:32;index" json:"event"`            // 事件名
+	URL        string `gorm:"size:500" json:"url"`                   // 目标地址
+	Payload    string `gorm:"type:text" json:"payload"`              // 请求体
+	Status     int    `gorm:"not null;index" json:"status"`          // 状态：1成功 0失败（不设默认值，否则 GORM 会忽略失败状态的零值）
+	StatusCode int    `json:"statusCode"`                            // 最后一次请求的 HTTP 状态码，请求未发出时为0
+	Attempts   int    `json:"attempts"`                              // 请求次数(含首次)
+	Error      string `gorm:"size:500" json:"error"`                 // 最后一次失败的原因
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// CreateWebhookDelivery 创建投递记录
+func CreateWebhookDelivery(delivery *WebhookDelivery) error {
+	return database.DB.Create(delivery).Error
+}
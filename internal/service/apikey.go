@@ -0,0 +1,168 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"goboot/internal/model"
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+)
+
+const (
+	apiKeyPrefix        = "gbk_"          // API Key 前缀，便于识别和密钥扫描
+	maxAPIKeysPerUser   = 20              // 每个用户最多可创建的 API Key 数量
+	apiKeyTouchInterval = 1 * time.Minute // 最后使用时间的更新间隔，避免每次请求都写数据库
+)
+
+// APIKeyService API Key 服务
+type APIKeyService struct{}
+
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{}
+}
+
+// hashAPIKey 计算 API Key 的哈希，密钥本身为高熵随机值，使用 SHA-256 即可且便于索引查找
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create 为用户创建 API Key，返回记录及密钥明文(仅此一次)
+// scopes 为允许使用的权限标识，为空表示继承用户的全部权限；expiresAt 为空表示永不过期
+func (s *APIKeyService) Create(userID uint, name string, scopes []string, expiresAt *time.Time) (*model.APIKey, string, error) {
+	keys, err := model.GetAPIKeysByUser(userID)
+	if err != nil {
+		return nil, "", errors.New("创建API Key失败")
+	}
+	if len(keys) >= maxAPIKeysPerUser {
+		return nil, "", errors.New("API Key数量已达上限，请先删除不再使用的API Key")
+	}
+
+	scopes, err = s.validateScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", errors.New("生成API Key失败")
+	}
+	plain := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	key := &model.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    plain[:len(apiKeyPrefix)+6],
+		KeyHash:   hashAPIKey(plain),
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+	}
+	if err := model.CreateAPIKey(key); err != nil {
+		return nil, "", errors.New("创建API Key失败")
+	}
+	return key, plain, nil
+}
+
+// validateScopes 检查权限标识是否存在，并去重
+func (s *APIKeyService) validateScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+
+	perms, err := model.GetAllPermissions()
+	if err != nil {
+		return nil, errors.New("获取权限失败")
+	}
+	known := make(map[string]bool, len(perms))
+	for _, perm := range perms {
+		known[perm.Code] = true
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !known[scope] {
+			return nil, errors.New("权限不存在: " + scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+// List 获取用户的 API Key 列表
+func (s *APIKeyService) List(userID uint) ([]model.APIKey, error) {
+	keys, err := model.GetAPIKeysByUser(userID)
+	if err != nil {
+		return nil, errors.New("获取API Key列表失败")
+	}
+	return keys, nil
+}
+
+// Revoke 删除用户的指定 API Key，立即失效
+func (s *APIKeyService) Revoke(userID, id uint) error {
+	deleted, err := model.DeleteAPIKey(userID, id)
+	if err != nil {
+		return errors.New("删除API Key失败")
+	}
+	if !deleted {
+		return errors.New("API Key不存在")
+	}
+	return nil
+}
+
+// Authenticate 校验 API Key，返回 API Key 及其所属用户，并异步更新最后使用时间
+func (s *APIKeyService) Authenticate(plain string) (*model.APIKey, *model.User, error) {
+	if !strings.HasPrefix(plain, apiKeyPrefix) {
+		return nil, nil, errors.New("无效的API Key")
+	}
+
+	key, err := model.GetAPIKeyByHash(hashAPIKey(plain))
+	if err != nil {
+		return nil, nil, errors.New("无效的API Key")
+	}
+	if key.IsExpired() {
+		return nil, nil, errors.New("API Key已过期")
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, key.UserID).Error; err != nil {
+		return nil, nil, errors.New("无效的API Key")
+	}
+	if user.Status != model.UserStatusActive {
+		return nil, nil, ErrUserDisabled
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		go func(id uint) {
+			if err := model.TouchAPIKey(id, now); err != nil {
+				logger.Warn("更新API Key最后使用时间失败", slog.Uint64("apiKeyID", uint64(id)), slog.Any("error", err))
+			}
+		}(key.ID)
+	}
+
+	return key, &user, nil
+}
+
+// ScopeAllows 检查 API Key 的权限范围是否包含指定权限，范围为空表示不限制
+func ScopeAllows(scopes []string, perm string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	for _, scope := range scopes {
+		if scope == perm || scope == model.PermAll {
+			return true
+		}
+	}
+	return false
+}
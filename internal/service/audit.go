@@ -1,6 +1,7 @@
 package service
 
 import (
+	"goboot/config"
 	"goboot/internal/model"
 	"goboot/pkg/logger"
 	"log/slog"
@@ -15,8 +16,10 @@ func NewAuditService() *AuditService {
 	return &AuditService{}
 }
 
-// Log 记录审计日志
+// Log 记录审计日志，同时标记当前请求已记录，审计中间件不再重复记录
 func (s *AuditService) Log(c fiber.Ctx, action, module, target, detail string, status int) {
+	c.Locals("auditLogged", true)
+
 	var userID uint
 	var username string
 
@@ -27,6 +30,7 @@ func (s *AuditService) Log(c fiber.Ctx, action, module, target, detail string, s
 	if name := c.Locals("username"); name != nil {
 		username = name.(string)
 	}
+	requestID, _ := c.Locals("requestID").(string)
 
 	log := &model.AuditLog{
 		UserID:    userID,
@@ -38,14 +42,11 @@ func (s *AuditService) Log(c fiber.Ctx, action, module, target, detail string, s
 		IP:        c.IP(),
 		UserAgent: string(c.Request().Header.UserAgent()),
 		Status:    status,
+		RequestID: requestID,
 	}
 
-	// 异步写入数据库，不阻塞主流程
-	go func() {
-		if err := model.CreateAuditLog(log); err != nil {
-			logger.Error("Failed to create audit log", slog.Any("error", err))
-		}
-	}()
+	// 放入缓冲队列由后台批量写入，不阻塞主流程
+	getAuditWriter().write(log)
 }
 
 // LogSuccess 记录成功操作
@@ -58,17 +59,51 @@ func (s *AuditService) LogFail(c fiber.Ctx, action, module, target, detail strin
 	s.Log(c, action, module, target, detail, 0)
 }
 
+// CleanupExpiredLogs 删除超过保留天数的审计日志，retention_days 为 0 时不清理
+func (s *AuditService) CleanupExpiredLogs() {
+	days := config.Get().Audit.RetentionDays
+	if days <= 0 {
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -days)
+	deleted, err := model.DeleteAuditLogsBefore(cutoff)
+	if err != nil {
+		logger.Error("清理过期审计日志失败", slog.Int64("deleted", deleted), slog.Any("error", err))
+		return
+	}
+	logger.Info("清理过期审计日志完成", slog.Int64("deleted", deleted), slog.Time("before", cutoff))
+}
+
 // GetLogs 获取审计日志列表
 func (s *AuditService) GetLogs(req *AuditLogListRequest) ([]model.AuditLog, int64, error) {
-	return model.GetAuditLogs(req.Page, req.PageSize, req.UserID, req.Action, req.Module, req.StartTime, req.EndTime)
+	return model.GetAuditLogs(req.Page, req.PageSize, req.UserID, req.Action, req.Module, req.Keyword, req.Status, req.StartTime, req.EndTime)
+}
+
+// GetLogsByCursor 按游标获取审计日志列表，返回下一页的游标及是否还有更多数据
+func (s *AuditService) GetLogsByCursor(req *AuditLogListRequest) ([]model.AuditLog, uint, bool, error) {
+	logs, hasMore, err := model.GetAuditLogsByCursor(req.Cursor, req.Limit, req.UserID, req.Action, req.Module, req.Keyword, req.Status, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	var nextCursor uint
+	if len(logs) > 0 {
+		nextCursor = logs[len(logs)-1].ID
+	}
+	return logs, nextCursor, hasMore, nil
 }
 
 type AuditLogListRequest struct {
 	Page      int        `json:"page"`
 	PageSize  int        `json:"pageSize"`
+	Cursor    uint       `json:"cursor"` // 游标分页：上一页返回的 nextCursor，0 表示第一页
+	Limit     int        `json:"limit"`  // 游标分页：每页条数
 	UserID    uint       `json:"userId"`
 	Action    string     `json:"action"`
 	Module    string     `json:"module"`
+	Keyword   string     `json:"keyword"` // 在操作详情、操作目标和用户名中模糊搜索
+	Status    *int       `json:"status"`  // 1成功 0失败，为空表示不限
 	StartTime *time.Time `json:"startTime"`
 	EndTime   *time.Time `json:"endTime"`
 }
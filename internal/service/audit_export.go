@@ -0,0 +1,70 @@
+package service
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"goboot/internal/model"
+)
+
+// auditExportBatchSize 导出时每批读取的审计日志条数
+const auditExportBatchSize = 500
+
+// auditCSVHeader 审计日志导出的CSV表头
+var auditCSVHeader = []string{"ID", "用户ID", "用户名", "操作类型", "模块", "操作目标", "操作详情", "IP", "UserAgent", "状态", "请求ID", "操作时间"}
+
+// ExportCSV 将符合条件的审计日志以CSV格式写入 w，分批读取数据库，每批写完后刷新，
+// 文件以 UTF-8 BOM 开头，保证 Excel 正确识别中文
+func (s *AuditService) ExportCSV(req *AuditLogListRequest, w io.Writer) error {
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	err := model.FindAuditLogsInBatches(req.UserID, req.Action, req.Module, req.Keyword, req.Status, req.StartTime, req.EndTime, auditExportBatchSize, func(logs []model.AuditLog) error {
+		for _, log := range logs {
+			status := "成功"
+			if log.Status != 1 {
+				status = "失败"
+			}
+			if err := writer.Write([]string{
+				strconv.FormatUint(uint64(log.ID), 10),
+				strconv.FormatUint(uint64(log.UserID), 10),
+				csvSafe(log.Username),
+				log.Action,
+				log.Module,
+				csvSafe(log.Target),
+				csvSafe(log.Detail),
+				log.IP,
+				csvSafe(log.UserAgent),
+				status,
+				log.RequestID,
+				log.CreatedAt.Format("2006-01-02 15:04:05"),
+			}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvSafe 对以公式字符开头的单元格加单引号前缀，防止在 Excel 中打开时被当作公式执行
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"goboot/config"
+	"goboot/internal/model"
+	"goboot/pkg/logger"
+)
+
+// 审计日志批量写入的默认参数
+const (
+	defaultAuditBufferSize    = 1024
+	defaultAuditBatchSize     = 100
+	defaultAuditFlushInterval = 2 * time.Second
+)
+
+// auditWriter 审计日志批量写入器，日志先进入缓冲通道，
+// 由后台协程在累计 batchSize 条或每隔 flushInterval 时批量写入数据库
+type auditWriter struct {
+	ch            chan *model.AuditLog
+	batchSize     int
+	flushInterval time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+var (
+	auditWriterInstance *auditWriter
+	auditWriterOnce     sync.Once
+)
+
+// getAuditWriter 获取审计日志写入器单例，首次调用时启动后台写入协程
+func getAuditWriter() *auditWriter {
+	auditWriterOnce.Do(func() {
+		cfg := config.Get().Audit
+		w := &auditWriter{
+			ch:            make(chan *model.AuditLog, positiveOr(cfg.BufferSize, defaultAuditBufferSize)),
+			batchSize:     positiveOr(cfg.BatchSize, defaultAuditBatchSize),
+			flushInterval: defaultAuditFlushInterval,
+			done:          make(chan struct{}),
+		}
+		if cfg.FlushInterval > 0 {
+			w.flushInterval = time.Duration(cfg.FlushInterval) * time.Millisecond
+		}
+		go w.run()
+		auditWriterInstance = w
+	})
+	return auditWriterInstance
+}
+
+// positiveOr value 大于0时返回 value，否则返回默认值
+func positiveOr(value, def int) int {
+	if value > 0 {
+		return value
+	}
+	return def
+}
+
+// write 将日志放入缓冲通道，写入器已关闭或缓冲已满时直接写入数据库，避免丢失日志或阻塞请求
+func (w *auditWriter) write(log *model.AuditLog) {
+	w.mu.RLock()
+	if !w.closed {
+		select {
+		case w.ch <- log:
+			w.mu.RUnlock()
+			return
+		default:
+		}
+	}
+	w.mu.RUnlock()
+
+	go func() {
+		if err := model.CreateAuditLog(log); err != nil {
+			logger.Error("Failed to create audit log", slog.Any("error", err))
+		}
+	}()
+}
+
+// run 后台写入协程，通道关闭后写完剩余日志再退出
+func (w *auditWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*model.AuditLog, 0, w.batchSize)
+	for {
+		select {
+		case log, ok := <-w.ch:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, log)
+			if len(batch) >= w.batchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush 批量写入一批日志
+func (w *auditWriter) flush(batch []*model.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := model.CreateAuditLogs(batch); err != nil {
+		logger.Error("Failed to create audit logs", slog.Int("count", len(batch)), slog.Any("error", err))
+	}
+}
+
+// close 停止接收新日志并等待缓冲中的日志写入完成
+func (w *auditWriter) close(timeout time.Duration) {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	close(w.ch)
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+	case <-time.After(timeout):
+		logger.Warn("Timed out flushing audit logs", slog.Int("pending", len(w.ch)))
+	}
+}
+
+// FlushAuditLogs 停止审计日志批量写入器并写入缓冲中剩余的日志，在服务关闭时调用，
+// 之后记录的审计日志直接写入数据库
+func FlushAuditLogs(timeout time.Duration) {
+	if auditWriterInstance == nil {
+		return
+	}
+	auditWriterInstance.close(timeout)
+}
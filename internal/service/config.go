@@ -1,17 +1,21 @@
 package service
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"goboot/internal/model"
+	"goboot/pkg/cache"
 	"goboot/pkg/database"
 	"goboot/pkg/logger"
+
+	"gorm.io/gorm"
 )
 
 // ConfigService 系统配置服务
@@ -33,6 +37,8 @@ func GetConfigService() *ConfigService {
 		}
 		// 启动时加载所有配置到内存
 		configService.LoadAll()
+		// 订阅其他实例的配置变更，保持多实例缓存一致
+		configService.subscribeChanges()
 	})
 	return configService
 }
@@ -65,6 +71,7 @@ func (s *ConfigService) Refresh(key string) error {
 		s.cacheMutex.Lock()
 		delete(s.cache, key)
 		s.cacheMutex.Unlock()
+		s.deleteRedisCache(key)
 		return err
 	}
 
@@ -73,7 +80,7 @@ func (s *ConfigService) Refresh(key string) error {
 	s.cacheMutex.Unlock()
 
 	// 同时更新Redis缓存
-	s.setRedisCache(key, config.ConfigValue)
+	s.setRedisCache(config)
 	return nil
 }
 
@@ -89,7 +96,7 @@ func (s *ConfigService) RefreshGroup(group string) error {
 
 	for i := range configs {
 		s.cache[configs[i].ConfigKey] = &configs[i]
-		s.setRedisCache(configs[i].ConfigKey, configs[i].ConfigValue)
+		s.setRedisCache(&configs[i])
 	}
 	return nil
 }
@@ -103,8 +110,14 @@ func (s *ConfigService) Get(key string, defaultValue ...string) string {
 	}
 	s.cacheMutex.RUnlock()
 
-	// 缓存未命中，从数据库加载
-	config, err := model.GetConfigByKey(key)
+	// 内存缓存未命中，经Redis缓存从数据库加载，不存在的配置会短暂缓存空值，避免反复查库
+	config, err := cache.GetOrSet(configCacheKey(key), configCacheTTL, func() (*model.SysConfig, error) {
+		config, err := model.GetConfigByKey(key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, cache.ErrNotFound
+		}
+		return config, err
+	})
 	if err != nil {
 		if len(defaultValue) > 0 {
 			return defaultValue[0]
@@ -175,18 +188,129 @@ func (s *ConfigService) GetBool(key string, defaultValue ...bool) bool {
 		return false
 	}
 
-	// 支持多种格式: true/false, 1/0, yes/no, on/off
+	boolVal, ok := parseBool(value)
+	if !ok {
+		if len(defaultValue) > 0 {
+			return defaultValue[0]
+		}
+		return false
+	}
+	return boolVal
+}
+
+// parseBool 解析布尔配置值
+// 支持多种格式: true/false, 1/0, yes/no, on/off
+func parseBool(value string) (bool, bool) {
 	switch value {
 	case "true", "1", "yes", "on":
-		return true
+		return true, true
 	case "false", "0", "no", "off":
-		return false
+		return false, true
 	default:
+		return false, false
+	}
+}
+
+// ValidateConfigValue 检查配置值是否符合声明的类型，空值视为未设置
+func ValidateConfigValue(configType, value string) error {
+	if value == "" {
+		return nil
+	}
+
+	var ok bool
+	switch configType {
+	case model.ConfigTypeInt:
+		_, err := strconv.Atoi(value)
+		ok = err == nil
+	case model.ConfigTypeBool:
+		_, ok = parseBool(value)
+	case model.ConfigTypeJSON:
+		ok = json.Valid([]byte(value))
+	default:
+		ok = true
+	}
+
+	if !ok {
+		return fmt.Errorf("配置值与类型 %s 不匹配", configType)
+	}
+	return nil
+}
+
+// validateValue 按配置键当前声明的类型检查配置值
+func (s *ConfigService) validateValue(key, value string) error {
+	s.cacheMutex.RLock()
+	config, ok := s.cache[key]
+	s.cacheMutex.RUnlock()
+
+	if !ok {
+		var err error
+		if config, err = model.GetConfigByKey(key); err != nil {
+			return fmt.Errorf("配置 %s 不存在", key)
+		}
+	}
+
+	if err := ValidateConfigValue(config.ConfigType, value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// GetFloat64 获取浮点数配置
+func (s *ConfigService) GetFloat64(key string, defaultValue ...float64) float64 {
+	value := s.Get(key)
+	if value == "" {
 		if len(defaultValue) > 0 {
 			return defaultValue[0]
 		}
-		return false
+		return 0
+	}
+
+	floatVal, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		if len(defaultValue) > 0 {
+			return defaultValue[0]
+		}
+		return 0
+	}
+	return floatVal
+}
+
+// GetDuration 获取时长配置，格式如 300ms、30s、1h30m
+func (s *ConfigService) GetDuration(key string, defaultValue ...time.Duration) time.Duration {
+	value := s.Get(key)
+	if value == "" {
+		if len(defaultValue) > 0 {
+			return defaultValue[0]
+		}
+		return 0
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		if len(defaultValue) > 0 {
+			return defaultValue[0]
+		}
+		return 0
 	}
+	return duration
+}
+
+// GetStringSlice 获取以分隔符分隔的列表配置，如逗号分隔的白名单
+// 各项会去除首尾空白，空项被忽略
+func (s *ConfigService) GetStringSlice(key, sep string, defaultValue ...[]string) []string {
+	value := s.Get(key)
+
+	var result []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	if len(result) == 0 && len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+	return result
 }
 
 // GetJSON 获取JSON配置并解析到目标结构
@@ -207,13 +331,21 @@ func (s *ConfigService) GetMap(key string) (map[string]interface{}, error) {
 
 // Set 设置配置值
 func (s *ConfigService) Set(key, value string) error {
-	err := model.UpdateConfigValue(key, value)
+	if err := s.validateValue(key, value); err != nil {
+		return err
+	}
+
+	err := model.UpdateConfigValue(key, value, "")
 	if err != nil {
 		return err
 	}
 
 	// 刷新缓存
-	return s.Refresh(key)
+	if err := s.Refresh(key); err != nil {
+		return err
+	}
+	s.publishChange(key)
+	return nil
 }
 
 // SetInt 设置整数配置
@@ -258,29 +390,42 @@ func (s *ConfigService) Create(config *model.SysConfig) error {
 	if model.ConfigExists(config.ConfigKey) {
 		return errors.New("配置键已存在")
 	}
+	if err := ValidateConfigValue(config.ConfigType, config.ConfigValue); err != nil {
+		return err
+	}
 
 	err := model.CreateConfig(config)
 	if err != nil {
 		return err
 	}
 
-	// 更新缓存
+	// 更新缓存，同时覆盖Redis中可能存在的空值标记
 	s.cacheMutex.Lock()
 	s.cache[config.ConfigKey] = config
 	s.cacheMutex.Unlock()
+	s.setRedisCache(config)
 
+	s.publishChange(config.ConfigKey)
 	return nil
 }
 
-// Update 更新配置
-func (s *ConfigService) Update(config *model.SysConfig) error {
-	err := model.UpdateConfig(config)
+// Update 更新配置，operator 为操作人，记录到变更历史
+func (s *ConfigService) Update(config *model.SysConfig, operator string) error {
+	if err := ValidateConfigValue(config.ConfigType, config.ConfigValue); err != nil {
+		return err
+	}
+
+	err := model.UpdateConfig(config, operator)
 	if err != nil {
 		return err
 	}
 
 	// 刷新缓存
-	return s.Refresh(config.ConfigKey)
+	if err := s.Refresh(config.ConfigKey); err != nil {
+		return err
+	}
+	s.publishChange(config.ConfigKey)
+	return nil
 }
 
 // Delete 删除配置
@@ -304,41 +449,83 @@ func (s *ConfigService) Delete(id uint) error {
 	// 删除Redis缓存
 	s.deleteRedisCache(config.ConfigKey)
 
+	s.publishChange(config.ConfigKey)
 	return nil
 }
 
-// BatchUpdate 批量更新配置值
-func (s *ConfigService) BatchUpdate(configs map[string]string) error {
-	err := model.BatchUpdateConfigs(configs)
+// BatchUpdate 批量更新配置值，operator 为操作人，记录到变更历史
+func (s *ConfigService) BatchUpdate(configs map[string]string, operator string) error {
+	for key, value := range configs {
+		if err := s.validateValue(key, value); err != nil {
+			return err
+		}
+	}
+
+	err := model.BatchUpdateConfigs(configs, operator)
 	if err != nil {
 		return err
 	}
 
 	// 刷新缓存
+	keys := make([]string, 0, len(configs))
 	for key := range configs {
 		s.Refresh(key)
+		keys = append(keys, key)
 	}
+	s.publishChange(keys...)
 	return nil
 }
 
+// GetHistory 获取配置变更历史
+func (s *ConfigService) GetHistory(key string, page, pageSize int) ([]model.ConfigHistory, int64, error) {
+	return model.GetConfigHistory(key, page, pageSize)
+}
+
+// Rollback 将配置恢复为指定变更记录之前的值，回滚本身也会记录到变更历史
+func (s *ConfigService) Rollback(historyID uint, operator string) (*model.ConfigHistory, error) {
+	history, err := model.GetConfigHistoryByID(historyID)
+	if err != nil {
+		return nil, errors.New("变更记录不存在")
+	}
+	if !model.ConfigExists(history.ConfigKey) {
+		return nil, errors.New("配置不存在")
+	}
+	if err := s.validateValue(history.ConfigKey, history.OldValue); err != nil {
+		return nil, err
+	}
+
+	if err := model.UpdateConfigValue(history.ConfigKey, history.OldValue, operator); err != nil {
+		return nil, err
+	}
+
+	// 刷新缓存
+	if err := s.Refresh(history.ConfigKey); err != nil {
+		return nil, err
+	}
+	s.publishChange(history.ConfigKey)
+	return history, nil
+}
+
+// configCacheTTL 配置在Redis中的缓存时间
+const configCacheTTL = 24 * time.Hour
+
+// configCacheKey 配置的Redis缓存键
+func configCacheKey(key string) string {
+	return cache.Key("sys_config", key)
+}
+
 // setRedisCache 设置Redis缓存
-func (s *ConfigService) setRedisCache(key, value string) {
-	if database.RDB == nil {
-		return
+func (s *ConfigService) setRedisCache(config *model.SysConfig) {
+	if err := cache.Set(configCacheKey(config.ConfigKey), config, configCacheTTL); err != nil {
+		logger.Warn("写入配置缓存失败", slog.String("key", config.ConfigKey), slog.Any("error", err))
 	}
-	ctx := context.Background()
-	cacheKey := "sys_config:" + key
-	database.RDB.Set(ctx, cacheKey, value, 24*time.Hour)
 }
 
 // deleteRedisCache 删除Redis缓存
 func (s *ConfigService) deleteRedisCache(key string) {
-	if database.RDB == nil {
-		return
+	if err := cache.Delete(configCacheKey(key)); err != nil {
+		logger.Warn("删除配置缓存失败", slog.String("key", key), slog.Any("error", err))
 	}
-	ctx := context.Background()
-	cacheKey := "sys_config:" + key
-	database.RDB.Del(ctx, cacheKey)
 }
 
 // ============ 邮件配置便捷方法 ============
@@ -355,6 +542,13 @@ type EmailConfig struct {
 	SSL         bool
 	ResetURL    string
 	ResetExpire int
+
+	VerifyRequired bool
+	VerifyURL      string
+	VerifyExpire   int
+
+	PoolSize        int // SMTP 连接池最大空闲连接数
+	PoolIdleTimeout int // SMTP 空闲连接超时(秒)
 }
 
 // GetEmailConfig 获取邮件配置
@@ -370,6 +564,13 @@ func (s *ConfigService) GetEmailConfig() *EmailConfig {
 		SSL:         s.GetBool("email_ssl", true),
 		ResetURL:    s.Get("email_reset_url", ""),
 		ResetExpire: s.GetInt("email_reset_expire", 30),
+
+		VerifyRequired: s.GetBool("email_verify_required", false),
+		VerifyURL:      s.Get("email_verify_url", ""),
+		VerifyExpire:   s.GetInt("email_verify_expire", 1440),
+
+		PoolSize:        s.GetInt("email_pool_size", 2),
+		PoolIdleTimeout: s.GetInt("email_pool_idle_timeout", 60),
 	}
 }
 
@@ -377,14 +578,21 @@ func (s *ConfigService) GetEmailConfig() *EmailConfig {
 
 // UploadConfig 上传配置结构
 type UploadConfigDB struct {
-	Enabled      bool
-	StorageType  string
-	LocalPath    string
-	BaseURL      string
-	MaxSize      int
-	MaxImageSize int
-	AllowedExts  []string
-	ImageExts    []string
+	Enabled             bool
+	StorageType         string
+	LocalPath           string
+	BaseURL             string
+	MaxSize             int
+	MaxImageSize        int
+	MaxAvatarSize       int
+	AllowedExts         []string
+	ImageExts           []string
+	StripExif           bool
+	AutoOrient          bool
+	ImageQuality        int
+	Dedup               bool
+	Concurrency         int
+	ActiveContentPolicy string
 }
 
 // GetUploadConfig 获取上传配置
@@ -402,13 +610,20 @@ func (s *ConfigService) GetUploadConfig() *UploadConfigDB {
 	}
 
 	return &UploadConfigDB{
-		Enabled:      s.GetBool("upload_enabled", true),
-		StorageType:  s.Get("upload_storage_type", "local"),
-		LocalPath:    s.Get("upload_local_path", "./uploads"),
-		BaseURL:      s.Get("upload_base_url", "http://127.0.0.1:8080/uploads"),
-		MaxSize:      s.GetInt("upload_max_size", 10),
-		MaxImageSize: s.GetInt("upload_max_image_size", 5),
-		AllowedExts:  allowedExts,
-		ImageExts:    imageExts,
+		Enabled:             s.GetBool("upload_enabled", true),
+		StorageType:         s.Get("upload_storage_type", "local"),
+		LocalPath:           s.Get("upload_local_path", "./uploads"),
+		BaseURL:             s.Get("upload_base_url", "http://127.0.0.1:8080/uploads"),
+		MaxSize:             s.GetInt("upload_max_size", 10),
+		MaxImageSize:        s.GetInt("upload_max_image_size", 5),
+		MaxAvatarSize:       s.GetInt("upload_max_avatar_size", 2),
+		AllowedExts:         allowedExts,
+		ImageExts:           imageExts,
+		StripExif:           s.GetBool("upload_strip_exif", true),
+		AutoOrient:          s.GetBool("upload_auto_orient", true),
+		ImageQuality:        s.GetInt("upload_image_quality", 85),
+		Dedup:               s.GetBool("upload_dedup", false),
+		Concurrency:         s.GetInt("upload_concurrency", 4),
+		ActiveContentPolicy: s.Get("upload_active_content", ActiveContentReject),
 	}
 }
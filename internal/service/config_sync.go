@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// configChangeChannel 配置变更通知频道
+const configChangeChannel = "sys_config:changed"
+
+// configChangeMessage 配置变更通知
+type configChangeMessage struct {
+	Instance string   `json:"instance"` // 发布方实例ID，用于忽略自身发布的消息
+	Keys     []string `json:"keys"`     // 变更的配置键
+}
+
+// configInstanceID 当前实例ID
+var configInstanceID = uuid.New().String()
+
+// publishChange 广播配置变更，通知其他实例刷新本地缓存
+func (s *ConfigService) publishChange(keys ...string) {
+	if database.RDB == nil || len(keys) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(configChangeMessage{Instance: configInstanceID, Keys: keys})
+	if err != nil {
+		return
+	}
+
+	ctx := context.Background()
+	if err := database.RDB.Publish(ctx, configChangeChannel, payload).Err(); err != nil {
+		logger.Warn("发布配置变更通知失败", slog.Any("keys", keys), slog.Any("error", err))
+	}
+}
+
+// subscribeChanges 订阅其他实例的配置变更通知并刷新本地缓存
+// 配置被删除时 Refresh 会将其从缓存中移除
+func (s *ConfigService) subscribeChanges() {
+	if database.RDB == nil {
+		return
+	}
+
+	pubsub := database.RDB.Subscribe(context.Background(), configChangeChannel)
+	go func() {
+		defer pubsub.Close()
+		for msg := range pubsub.Channel() {
+			var change configChangeMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &change); err != nil {
+				logger.Warn("解析配置变更通知失败", slog.String("payload", msg.Payload), slog.Any("error", err))
+				continue
+			}
+			if change.Instance == configInstanceID {
+				continue
+			}
+
+			for _, key := range change.Keys {
+				s.Refresh(key)
+			}
+			logger.Debug("已同步其他实例的配置变更", slog.Any("keys", change.Keys))
+		}
+	}()
+}
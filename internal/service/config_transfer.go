@@ -0,0 +1,145 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"goboot/internal/model"
+)
+
+// configExportVersion 导出文档格式版本
+const configExportVersion = 1
+
+// ConfigExportItem 导出的单项配置，不包含ID和时间戳，便于跨环境迁移
+type ConfigExportItem struct {
+	ConfigKey   string `json:"configKey"`
+	ConfigValue string `json:"configValue"`
+	ConfigType  string `json:"configType"`
+	ConfigGroup string `json:"configGroup"`
+	Name        string `json:"name"`
+	Remark      string `json:"remark"`
+	Sort        int    `json:"sort"`
+	IsPublic    bool   `json:"isPublic"`
+}
+
+// ConfigExport 配置导出文档
+type ConfigExport struct {
+	Version    int                `json:"version"`
+	ExportedAt time.Time          `json:"exportedAt"`
+	Configs    []ConfigExportItem `json:"configs"`
+}
+
+// ConfigImportError 导入时被跳过的配置，键为配置键，值为跳过原因
+type ConfigImportError struct {
+	Skipped map[string]string
+}
+
+func (e *ConfigImportError) Error() string {
+	keys := make([]string, 0, len(e.Skipped))
+	for key := range e.Skipped {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s(%s)", key, e.Skipped[key]))
+	}
+	return "以下配置未导入: " + strings.Join(parts, ", ")
+}
+
+// Export 导出所有配置为JSON文档
+func (s *ConfigService) Export() ([]byte, error) {
+	configs, err := model.GetAllConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := ConfigExport{
+		Version:    configExportVersion,
+		ExportedAt: time.Now(),
+		Configs:    make([]ConfigExportItem, 0, len(configs)),
+	}
+	for _, cfg := range configs {
+		doc.Configs = append(doc.Configs, ConfigExportItem{
+			ConfigKey:   cfg.ConfigKey,
+			ConfigValue: cfg.ConfigValue,
+			ConfigType:  cfg.ConfigType,
+			ConfigGroup: cfg.ConfigGroup,
+			Name:        cfg.Name,
+			Remark:      cfg.Remark,
+			Sort:        cfg.Sort,
+			IsPublic:    cfg.IsPublic,
+		})
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Import 导入Export生成的JSON文档。不存在的配置直接创建，已存在的配置仅在 overwrite 为 true 时覆盖；
+// 类型校验失败的配置会被跳过，其余配置照常导入，并以 *ConfigImportError 返回被跳过的配置
+func (s *ConfigService) Import(data []byte, overwrite bool) error {
+	var doc ConfigExport
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return errors.New("导入文件格式错误")
+	}
+	if len(doc.Configs) == 0 {
+		return errors.New("导入文件中没有配置")
+	}
+
+	skipped := make(map[string]string)
+	configs := make([]model.SysConfig, 0, len(doc.Configs))
+	seen := make(map[string]bool, len(doc.Configs))
+	for _, item := range doc.Configs {
+		key := strings.TrimSpace(item.ConfigKey)
+		if key == "" {
+			continue
+		}
+		if seen[key] {
+			skipped[key] = "配置键重复"
+			continue
+		}
+		seen[key] = true
+
+		if item.ConfigType == "" {
+			item.ConfigType = model.ConfigTypeString
+		}
+		if err := ValidateConfigValue(item.ConfigType, item.ConfigValue); err != nil {
+			skipped[key] = err.Error()
+			continue
+		}
+
+		configs = append(configs, model.SysConfig{
+			ConfigKey:   key,
+			ConfigValue: item.ConfigValue,
+			ConfigType:  item.ConfigType,
+			ConfigGroup: item.ConfigGroup,
+			Name:        item.Name,
+			Remark:      item.Remark,
+			Sort:        item.Sort,
+			IsPublic:    item.IsPublic,
+		})
+	}
+
+	if len(configs) > 0 {
+		keys, err := model.ImportConfigs(configs, overwrite, "")
+		if err != nil {
+			return err
+		}
+
+		// 刷新缓存
+		for _, key := range keys {
+			s.Refresh(key)
+		}
+		s.publishChange(keys...)
+	}
+
+	if len(skipped) > 0 {
+		return &ConfigImportError{Skipped: skipped}
+	}
+	return nil
+}
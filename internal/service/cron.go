@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"goboot/pkg/logger"
 
@@ -15,6 +17,25 @@ type CronService struct {
 	cron *cron.Cron
 	jobs map[string]cron.EntryID
 	mu   sync.RWMutex
+
+	subscribers map[chan JobEvent]struct{}
+	subMu       sync.Mutex
+}
+
+// 任务执行状态
+const (
+	JobStatusRunning   = "running"   // 开始执行
+	JobStatusCompleted = "completed" // 执行完成
+	JobStatusFailed    = "failed"    // 执行失败(panic)
+)
+
+// JobEvent 任务执行进度事件
+type JobEvent struct {
+	Job      string    `json:"job"`                // 任务名称
+	Status   string    `json:"status"`             // 执行状态
+	Time     time.Time `json:"time"`               // 事件时间
+	Duration int64     `json:"duration,omitempty"` // 执行耗时(毫秒)，结束事件才有
+	Error    string    `json:"error,omitempty"`    // 失败原因
 }
 
 // JobFunc 任务执行函数类型
@@ -30,6 +51,8 @@ func GetCronService() *CronService {
 		cronService = &CronService{
 			cron: cron.New(cron.WithSeconds(), cron.WithLogger(&cronLogger{})),
 			jobs: make(map[string]cron.EntryID),
+
+			subscribers: make(map[chan JobEvent]struct{}),
 		}
 	})
 	return cronService
@@ -66,9 +89,10 @@ func (s *CronService) Start() {
 	logger.Info("Cron scheduler started")
 }
 
-// Stop 停止定时任务调度器（等待正在运行的任务完成）
+// Stop 停止定时任务调度器（等待正在运行的任务完成），同时结束所有进度事件订阅
 func (s *CronService) Stop() context.Context {
 	ctx := s.cron.Stop()
+	s.closeSubscribers()
 	logger.Info("Cron scheduler stopped")
 	return ctx
 }
@@ -87,20 +111,24 @@ func (s *CronService) AddJob(name, spec string, job JobFunc) error {
 		delete(s.jobs, name)
 	}
 
-	// 包装任务函数，添加日志和 panic 恢复
+	// 包装任务函数，添加日志、panic 恢复和执行进度事件
 	wrappedJob := func() {
+		start := time.Now()
 		defer func() {
 			if r := recover(); r != nil {
 				logger.Error("Cron job panic",
 					slog.String("job", name),
 					slog.Any("panic", r),
 				)
+				s.publish(JobEvent{Job: name, Status: JobStatusFailed, Time: time.Now(), Duration: time.Since(start).Milliseconds(), Error: fmt.Sprint(r)})
 			}
 		}()
 
 		logger.Debug("Cron job executing", slog.String("job", name))
+		s.publish(JobEvent{Job: name, Status: JobStatusRunning, Time: start})
 		job()
 		logger.Debug("Cron job completed", slog.String("job", name))
+		s.publish(JobEvent{Job: name, Status: JobStatusCompleted, Time: time.Now(), Duration: time.Since(start).Milliseconds()})
 	}
 
 	entryID, err := s.cron.AddFunc(spec, wrappedJob)
@@ -153,3 +181,46 @@ func (s *CronService) GetJobs() []string {
 func (s *CronService) GetEntries() []cron.Entry {
 	return s.cron.Entries()
 }
+
+// Subscribe 订阅任务执行进度事件，返回事件通道和取消订阅函数
+// 订阅者处理过慢导致通道写满时丢弃新事件，不阻塞任务执行
+func (s *CronService) Subscribe() (<-chan JobEvent, func()) {
+	ch := make(chan JobEvent, 32)
+
+	s.subMu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	unsubscribe := func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe
+}
+
+// closeSubscribers 关闭所有订阅，订阅方的事件通道随之关闭
+func (s *CronService) closeSubscribers() {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subscribers {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+// publish 向所有订阅者发送任务事件
+func (s *CronService) publish(event JobEvent) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -2,11 +2,9 @@ package service
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"log/slog"
-	"net/smtp"
 	"strings"
 	"time"
 
@@ -14,6 +12,7 @@ import (
 	"goboot/pkg/logger"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 )
 
 type EmailService struct{}
@@ -27,7 +26,7 @@ func (s *EmailService) getConfig() *EmailConfig {
 	return GetConfigService().GetEmailConfig()
 }
 
-// SendMail 发送邮件
+// SendMail 发送邮件，复用连接池中的 SMTP 连接
 func (s *EmailService) SendMail(to, subject, body string) error {
 	cfg := s.getConfig()
 
@@ -35,6 +34,33 @@ func (s *EmailService) SendMail(to, subject, body string) error {
 		return errors.New("邮件服务未启用")
 	}
 
+	return smtpPoolInstance.send(cfg, []string{to}, buildMailMessage(cfg, to, subject, body))
+}
+
+// SendTestMail 使用当前邮件配置发送测试邮件，不经过连接池，每次新建连接，
+// 便于修改配置后直接验证服务器地址、端口和账号是否可用
+func (s *EmailService) SendTestMail(to string) error {
+	cfg := s.getConfig()
+
+	if !cfg.Enabled {
+		return errors.New("邮件服务未启用")
+	}
+
+	body := `<p>这是一封测试邮件，收到说明邮件服务配置正确。</p>`
+	client, err := dialSMTP(cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := smtpTransaction(client, cfg.FromAddr, []string{to}, buildMailMessage(cfg, to, "测试邮件", body)); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMailMessage 构建 HTML 邮件内容
+func buildMailMessage(cfg *EmailConfig, to, subject, body string) []byte {
 	// 构建邮件头
 	header := make(map[string]string)
 	header["From"] = fmt.Sprintf("%s <%s>", cfg.FromName, cfg.FromAddr)
@@ -50,65 +76,7 @@ func (s *EmailService) SendMail(to, subject, body string) error {
 	}
 	message.WriteString("\r\n")
 	message.WriteString(body)
-
-	// 发送邮件
-	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	auth := smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
-
-	if cfg.SSL {
-		return s.sendMailSSL(addr, auth, cfg.FromAddr, []string{to}, []byte(message.String()), cfg.Host)
-	}
-
-	return smtp.SendMail(addr, auth, cfg.FromAddr, []string{to}, []byte(message.String()))
-}
-
-// sendMailSSL 通过 SSL 发送邮件
-func (s *EmailService) sendMailSSL(addr string, auth smtp.Auth, from string, to []string, msg []byte, host string) error {
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: true,
-		ServerName:         host,
-	}
-
-	conn, err := tls.Dial("tcp", addr, tlsConfig)
-	if err != nil {
-		return fmt.Errorf("连接SMTP服务器失败: %v", err)
-	}
-	defer conn.Close()
-
-	client, err := smtp.NewClient(conn, host)
-	if err != nil {
-		return fmt.Errorf("创建SMTP客户端失败: %v", err)
-	}
-	defer client.Close()
-
-	if err := client.Auth(auth); err != nil {
-		return fmt.Errorf("SMTP认证失败: %v", err)
-	}
-
-	if err := client.Mail(from); err != nil {
-		return fmt.Errorf("设置发件人失败: %v", err)
-	}
-
-	for _, addr := range to {
-		if err := client.Rcpt(addr); err != nil {
-			return fmt.Errorf("设置收件人失败: %v", err)
-		}
-	}
-
-	w, err := client.Data()
-	if err != nil {
-		return fmt.Errorf("获取写入器失败: %v", err)
-	}
-
-	if _, err := w.Write(msg); err != nil {
-		return fmt.Errorf("写入邮件内容失败: %v", err)
-	}
-
-	if err := w.Close(); err != nil {
-		return fmt.Errorf("关闭写入器失败: %v", err)
-	}
-
-	return client.Quit()
+	return []byte(message.String())
 }
 
 // SendPasswordResetEmail 发送密码重置邮件
@@ -121,10 +89,23 @@ func (s *EmailService) SendPasswordResetEmail(email, username string, userID uin
 	// 存储 token 到 Redis，设置过期时间
 	ctx := context.Background()
 	key := fmt.Sprintf("password_reset:%s", token)
+	userKey := passwordResetUserKey(userID)
 	expire := time.Duration(cfg.ResetExpire) * time.Minute
 
-	// 存储用户ID
-	if err := database.RDB.Set(ctx, key, userID, expire).Err(); err != nil {
+	// 同一用户只保留最新的重置链接，签发新 token 时删除之前的 token
+	oldToken, err := database.RDB.Get(ctx, userKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("存储重置token失败: %v", err)
+	}
+
+	// 存储用户ID，并记录用户当前有效的 token
+	pipe := database.RDB.TxPipeline()
+	if oldToken != "" {
+		pipe.Del(ctx, fmt.Sprintf("password_reset:%s", oldToken))
+	}
+	pipe.Set(ctx, key, userID, expire)
+	pipe.Set(ctx, userKey, token, expire)
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("存储重置token失败: %v", err)
 	}
 
@@ -184,11 +165,108 @@ func (s *EmailService) VerifyResetToken(token string) (uint, error) {
 	return userID, nil
 }
 
-// DeleteResetToken 删除重置 token
-func (s *EmailService) DeleteResetToken(token string) error {
+// DeleteResetToken 删除重置 token 及用户当前 token 的记录
+func (s *EmailService) DeleteResetToken(token string, userID uint) error {
 	ctx := context.Background()
 	key := fmt.Sprintf("password_reset:%s", token)
-	return database.RDB.Del(ctx, key).Err()
+	return database.RDB.Del(ctx, key, passwordResetUserKey(userID)).Err()
+}
+
+// passwordResetUserKey 用户当前有效的重置 token
+func passwordResetUserKey(userID uint) string {
+	return fmt.Sprintf("password_reset_user:%d", userID)
+}
+
+// SendVerificationEmail 发送邮箱验证邮件
+func (s *EmailService) SendVerificationEmail(email, username string, userID uint) error {
+	cfg := s.getConfig()
+
+	// 生成验证 token 并存储到 Redis
+	token := uuid.New().String()
+	ctx := context.Background()
+	key := fmt.Sprintf("email_verify:%s", token)
+	userKey := emailVerifyUserKey(userID)
+	expire := time.Duration(cfg.VerifyExpire) * time.Minute
+
+	// 同一用户只保留最新的验证链接，重新发送时删除之前的 token
+	oldToken, err := database.RDB.Get(ctx, userKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("存储验证token失败: %v", err)
+	}
+
+	pipe := database.RDB.TxPipeline()
+	if oldToken != "" {
+		pipe.Del(ctx, fmt.Sprintf("email_verify:%s", oldToken))
+	}
+	pipe.Set(ctx, key, userID, expire)
+	pipe.Set(ctx, userKey, token, expire)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("存储验证token失败: %v", err)
+	}
+
+	verifyLink := fmt.Sprintf("%s?token=%s", cfg.VerifyURL, token)
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+    <div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+        <h2 style="color: #2c3e50;">邮箱验证</h2>
+        <p>您好，%s：</p>
+        <p>感谢您的注册。请点击下面的按钮验证您的邮箱：</p>
+        <p style="text-align: center; margin: 30px 0;">
+            <a href="%s" style="background-color: #3498db; color: white; padding: 12px 30px; text-decoration: none; border-radius: 5px; display: inline-block;">验证邮箱</a>
+        </p>
+        <p>或者复制以下链接到浏览器：</p>
+        <p style="word-break: break-all; color: #3498db;">%s</p>
+        <p style="color: #e74c3c;">此链接将在 %d 分钟后失效。</p>
+        <p>如果您没有注册账号，请忽略此邮件。</p>
+        <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
+        <p style="color: #999; font-size: 12px;">此邮件由系统自动发送，请勿回复。</p>
+    </div>
+</body>
+</html>
+`, username, verifyLink, verifyLink, cfg.VerifyExpire)
+
+	// 异步发送邮件
+	go func() {
+		if err := s.SendMail(email, "邮箱验证", body); err != nil {
+			logger.Error("发送邮箱验证邮件失败", slog.String("email", email), slog.Any("error", err))
+		}
+	}()
+
+	return nil
+}
+
+// VerifyEmailToken 验证邮箱验证 token
+func (s *EmailService) VerifyEmailToken(token string) (uint, error) {
+	ctx := context.Background()
+	key := fmt.Sprintf("email_verify:%s", token)
+
+	userIDStr, err := database.RDB.Get(ctx, key).Result()
+	if err != nil {
+		return 0, errors.New("验证链接无效或已过期")
+	}
+
+	var userID uint
+	fmt.Sscanf(userIDStr, "%d", &userID)
+
+	return userID, nil
+}
+
+// DeleteEmailToken 删除邮箱验证 token 及用户当前 token 的记录
+func (s *EmailService) DeleteEmailToken(token string, userID uint) error {
+	ctx := context.Background()
+	key := fmt.Sprintf("email_verify:%s", token)
+	return database.RDB.Del(ctx, key, emailVerifyUserKey(userID)).Err()
+}
+
+// emailVerifyUserKey 用户当前有效的邮箱验证 token
+func emailVerifyUserKey(userID uint) string {
+	return fmt.Sprintf("email_verify_user:%d", userID)
 }
 
 // SendNotificationEmail 发送通知邮件
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+)
+
+// 邮件相关接口的限流动作，同一邮箱或IP在不同动作间分别计数
+const (
+	EmailActionForgotPassword     = "forgot_password"
+	EmailActionResetPassword      = "reset_password"
+	EmailActionResendVerification = "resend_verification"
+)
+
+// emailLimitSettings 读取邮件相关接口的限流配置，次数<=0 表示不限制
+func emailLimitSettings() (emailLimit, ipLimit int, window time.Duration) {
+	configService := GetConfigService()
+	emailLimit = configService.GetInt("security_email_limit_per_email", 3)
+	ipLimit = configService.GetInt("security_email_limit_per_ip", 20)
+	window = time.Duration(configService.GetInt("security_email_limit_window", 60)) * time.Minute
+	return emailLimit, ipLimit, window
+}
+
+// AllowEmailAction 按邮箱和客户端IP检查邮件相关接口是否超过限制，每次调用计数一次，
+// email 为空时只按IP计数；Redis 出错时放行，避免影响服务
+func (s *EmailService) AllowEmailAction(action, email, ip string) bool {
+	emailLimit, ipLimit, window := emailLimitSettings()
+	if window <= 0 {
+		return true
+	}
+
+	allowed := true
+	if email != "" && emailLimit > 0 {
+		key := fmt.Sprintf("ratelimit:%s:email:%s", action, strings.ToLower(email))
+		allowed = hitEmailLimit(key, emailLimit, window) && allowed
+	}
+	if ip != "" && ipLimit > 0 {
+		key := fmt.Sprintf("ratelimit:%s:ip:%s", action, ip)
+		allowed = hitEmailLimit(key, ipLimit, window) && allowed
+	}
+	return allowed
+}
+
+// hitEmailLimit 固定窗口计数，窗口内次数未超过 limit 时返回 true
+func hitEmailLimit(key string, limit int, window time.Duration) bool {
+	ctx := context.Background()
+	count, err := database.RDB.Incr(ctx, key).Result()
+	if err != nil {
+		logger.Warn("邮件接口限流计数失败", slog.String("key", key), slog.Any("error", err))
+		return true
+	}
+	if count == 1 {
+		database.RDB.Expire(ctx, key, window)
+	}
+	return count <= int64(limit)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+	"goboot/pkg/metrics"
+)
+
+// 邮件令牌类型，与 Redis 键前缀相同
+const (
+	EmailTokenPasswordReset = "password_reset"
+	EmailTokenEmailVerify   = "email_verify"
+)
+
+// emailTokenPatterns 各类型令牌的键，以及记录用户当前令牌的键
+var emailTokenPatterns = map[string][]string{
+	EmailTokenPasswordReset: {"password_reset:*", "password_reset_user:*"},
+	EmailTokenEmailVerify:   {"email_verify:*", "email_verify_user:*"},
+}
+
+// EmailTokenStats 未过期的邮件令牌数
+type EmailTokenStats struct {
+	PasswordReset int64 `json:"passwordReset"` // 密码重置令牌数
+	EmailVerify   int64 `json:"emailVerify"`   // 邮箱验证令牌数
+}
+
+// CountTokens 使用 SCAN 统计未过期的密码重置和邮箱验证令牌，同时更新监控指标
+func (s *EmailService) CountTokens(ctx context.Context) (*EmailTokenStats, error) {
+	reset, err := database.CountKeys(ctx, emailTokenPatterns[EmailTokenPasswordReset][0])
+	if err != nil {
+		return nil, errors.New("统计令牌失败")
+	}
+	verify, err := database.CountKeys(ctx, emailTokenPatterns[EmailTokenEmailVerify][0])
+	if err != nil {
+		return nil, errors.New("统计令牌失败")
+	}
+
+	metrics.EmailTokensActive.WithLabelValues(EmailTokenPasswordReset).Set(float64(reset))
+	metrics.EmailTokensActive.WithLabelValues(EmailTokenEmailVerify).Set(float64(verify))
+	return &EmailTokenStats{PasswordReset: reset, EmailVerify: verify}, nil
+}
+
+// PurgeTokens 使所有未过期的邮件令牌立即失效，tokenType 为空时清除全部类型，返回清除的令牌数；
+// 用于安全事件后批量作废已发出的重置链接
+func (s *EmailService) PurgeTokens(ctx context.Context, tokenType string) (*EmailTokenStats, error) {
+	types := []string{EmailTokenPasswordReset, EmailTokenEmailVerify}
+	if tokenType != "" {
+		if _, ok := emailTokenPatterns[tokenType]; !ok {
+			return nil, errors.New("不支持的令牌类型")
+		}
+		types = []string{tokenType}
+	}
+
+	stats := &EmailTokenStats{}
+	for _, t := range types {
+		patterns := emailTokenPatterns[t]
+		deleted, err := database.DeleteKeys(ctx, patterns[0])
+		if err != nil {
+			return nil, errors.New("清除令牌失败")
+		}
+		if _, err := database.DeleteKeys(ctx, patterns[1]); err != nil {
+			return nil, errors.New("清除令牌失败")
+		}
+
+		switch t {
+		case EmailTokenPasswordReset:
+			stats.PasswordReset = deleted
+		case EmailTokenEmailVerify:
+			stats.EmailVerify = deleted
+		}
+		metrics.EmailTokensActive.WithLabelValues(t).Set(0)
+	}
+	return stats, nil
+}
+
+// UpdateEmailTokenMetrics 统计未过期的邮件令牌并更新监控指标，由定时任务调用
+func UpdateEmailTokenMetrics() {
+	if _, err := NewEmailService().CountTokens(context.Background()); err != nil {
+		logger.Warn("统计邮件令牌失败", slog.Any("error", err))
+	}
+}
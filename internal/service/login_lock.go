@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 登录失败计数和锁定标记使用相同的 hash tag，Redis Cluster 下位于同一槽位，可在同一事务中操作
+func loginFailKey(userID uint) string {
+	return fmt.Sprintf("login:fail:{%d}", userID)
+}
+
+func loginLockKey(userID uint) string {
+	return fmt.Sprintf("login:lock:{%d}", userID)
+}
+
+// AccountLockedError 连续登录失败次数达到 security_max_login_attempts 后账号被锁定，RetryAfter 为剩余锁定时间
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	minutes := int((e.RetryAfter + time.Minute - 1) / time.Minute)
+	return fmt.Sprintf("登录失败次数过多，账号已锁定，请 %d 分钟后再试", minutes)
+}
+
+// UnlockUserResult 解锁结果，Locked 表示解锁前是否处于锁定状态，RemainingSeconds 为解除的剩余锁定时间
+type UnlockUserResult struct {
+	Locked           bool  `json:"locked"`
+	RemainingSeconds int64 `json:"remainingSeconds"`
+	FailedAttempts   int64 `json:"failedAttempts"` // 解锁前累计的连续登录失败次数
+}
+
+// loginLockSettings 读取登录锁定配置，maxAttempts<=0 表示不锁定
+func loginLockSettings() (maxAttempts int, duration time.Duration) {
+	configService := GetConfigService()
+	maxAttempts = configService.GetInt("security_max_login_attempts", 5)
+	duration = time.Duration(configService.GetInt("security_lockout_duration", 30)) * time.Minute
+	return maxAttempts, duration
+}
+
+// checkLoginLock 账号处于锁定状态时返回 AccountLockedError，Redis 出错时放行
+func checkLoginLock(userID uint) error {
+	ttl, err := database.RDB.TTL(context.Background(), loginLockKey(userID)).Result()
+	if err != nil {
+		logger.Warn("获取账号锁定状态失败", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return nil
+	}
+	if ttl > 0 {
+		return &AccountLockedError{RetryAfter: ttl}
+	}
+	return nil
+}
+
+// recordLoginFailure 记录一次登录失败，失败次数在锁定时长内累计，达到上限时锁定账号并返回 AccountLockedError
+func recordLoginFailure(userID uint) error {
+	maxAttempts, duration := loginLockSettings()
+	if maxAttempts <= 0 || duration <= 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	failKey := loginFailKey(userID)
+	failures, err := database.RDB.Incr(ctx, failKey).Result()
+	if err != nil {
+		logger.Warn("记录登录失败次数失败", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return nil
+	}
+	if failures == 1 {
+		database.RDB.Expire(ctx, failKey, duration)
+	}
+	if failures < int64(maxAttempts) {
+		return nil
+	}
+
+	pipe := database.RDB.TxPipeline()
+	pipe.Set(ctx, loginLockKey(userID), failures, duration)
+	pipe.Del(ctx, failKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("锁定账号失败", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return nil
+	}
+	logger.Warn("连续登录失败次数过多，账号已锁定", slog.Uint64("userID", uint64(userID)), slog.Duration("duration", duration))
+	return &AccountLockedError{RetryAfter: duration}
+}
+
+// clearLoginFailures 登录成功后清除失败次数
+func clearLoginFailures(userID uint) {
+	if err := database.RDB.Del(context.Background(), loginFailKey(userID)).Err(); err != nil {
+		logger.Warn("清除登录失败次数失败", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+	}
+}
+
+// UnlockUser 解除用户的登录锁定并清除失败次数(管理员)，返回解锁前的锁定状态
+func (s *UserService) UnlockUser(id uint) (*UnlockUserResult, error) {
+	ctx := context.Background()
+	if _, err := s.GetUserByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	lockKey, failKey := loginLockKey(id), loginFailKey(id)
+	pipe := database.RDB.TxPipeline()
+	ttl := pipe.TTL(ctx, lockKey)
+	lockedAttempts := pipe.Get(ctx, lockKey)
+	failures := pipe.Get(ctx, failKey)
+	pipe.Del(ctx, lockKey, failKey)
+	// 未锁定时 GET 返回 redis.Nil，不视为错误
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, errors.New("解锁失败")
+	}
+
+	result := &UnlockUserResult{}
+	if ttl.Val() > 0 {
+		result.Locked = true
+		result.RemainingSeconds = int64(ttl.Val().Seconds())
+		result.FailedAttempts, _ = lockedAttempts.Int64()
+	} else {
+		result.FailedAttempts, _ = failures.Int64()
+	}
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+)
+
+// defaultMaintenanceMessage 未配置 maintenance_message 时的提示信息
+const defaultMaintenanceMessage = "系统维护中，请稍后再试"
+
+// MaintenanceStatus 维护模式状态
+type MaintenanceStatus struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"`
+}
+
+// GetMaintenance 获取维护模式状态，读取系统配置缓存，可在每个请求中调用
+func (s *ConfigService) GetMaintenance() MaintenanceStatus {
+	message := s.Get("maintenance_message", defaultMaintenanceMessage)
+	if message == "" {
+		message = defaultMaintenanceMessage
+	}
+	return MaintenanceStatus{
+		Enabled: s.GetBool("maintenance_enabled", false),
+		Message: message,
+	}
+}
+
+// SetMaintenance 开启或关闭维护模式，message 为空时保留原提示信息；
+// 通过系统配置保存，变更会同步到其他实例并记录变更历史
+func (s *ConfigService) SetMaintenance(enabled bool, message, operator string) (MaintenanceStatus, error) {
+	configs := map[string]string{"maintenance_enabled": strconv.FormatBool(enabled)}
+	if message != "" {
+		configs["maintenance_message"] = message
+	}
+	if err := s.BatchUpdate(configs, operator); err != nil {
+		return MaintenanceStatus{}, errors.New("更新维护模式失败")
+	}
+	return s.GetMaintenance(), nil
+}
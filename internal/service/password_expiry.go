@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"goboot/internal/model"
+	"goboot/pkg/database"
+	"goboot/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// passwordChangeTokenTTL 密码过期后修改密码令牌的有效期
+const passwordChangeTokenTTL = 10 * time.Minute
+
+// PasswordExpiredError 登录时密码已过期，不签发登录令牌，
+// 客户端需使用 ChangeToken 调用修改过期密码接口设置新密码后重新登录
+type PasswordExpiredError struct {
+	ChangeToken string        // 修改过期密码的一次性令牌
+	ExpiresIn   time.Duration // 令牌有效期
+}
+
+func (e *PasswordExpiredError) Error() string {
+	return "密码已过期，请修改密码后重新登录"
+}
+
+func passwordChangeTokenKey(token string) string {
+	return fmt.Sprintf("password_expired:%s", token)
+}
+
+// passwordExpiresAt 计算用户密码的过期时间，未开启密码有效期(security_password_max_age_days<=0)时返回 nil
+// 从未修改过密码的用户以注册时间起算
+func passwordExpiresAt(user *model.User) *time.Time {
+	maxAge := GetConfigService().GetInt("security_password_max_age_days", 0)
+	if maxAge <= 0 {
+		return nil
+	}
+
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+	expiresAt := changedAt.AddDate(0, 0, maxAge)
+	return &expiresAt
+}
+
+// PasswordExpireDays 获取用户密码距离过期的天数，已过期时为0，未开启密码有效期时返回 nil
+func (s *UserService) PasswordExpireDays(user *model.User) *int {
+	expiresAt := passwordExpiresAt(user)
+	if expiresAt == nil {
+		return nil
+	}
+
+	days := int(math.Ceil(time.Until(*expiresAt).Hours() / 24))
+	if days < 0 {
+		days = 0
+	}
+	return &days
+}
+
+// isPasswordExpired 用户密码是否已过期
+func isPasswordExpired(user *model.User) bool {
+	expiresAt := passwordExpiresAt(user)
+	return expiresAt != nil && time.Now().After(*expiresAt)
+}
+
+// newPasswordExpiredError 为密码已过期的用户生成修改密码令牌
+func newPasswordExpiredError(userID uint) error {
+	token := uuid.New().String()
+	ctx := context.Background()
+	if err := database.RDB.Set(ctx, passwordChangeTokenKey(token), userID, passwordChangeTokenTTL).Err(); err != nil {
+		return errors.New("生成修改密码令牌失败")
+	}
+	return &PasswordExpiredError{ChangeToken: token, ExpiresIn: passwordChangeTokenTTL}
+}
+
+// ChangeExpiredPassword 使用登录时返回的修改密码令牌设置新密码，令牌仅能使用一次，
+// 新密码同样受历史密码检查限制
+func (s *UserService) ChangeExpiredPassword(token, newPassword string) (*model.User, error) {
+	ctx := context.Background()
+	userID, err := database.RDB.GetDel(ctx, passwordChangeTokenKey(token)).Uint64()
+	if err != nil {
+		return nil, errors.New("修改密码令牌无效或已过期，请重新登录")
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	historyCount := GetConfigService().GetInt("security_password_history", 5)
+	if err := checkPasswordReuse(&user, newPassword, historyCount); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return nil, errors.New("密码加密失败")
+	}
+	if err := model.UpdateUserPassword(&user, hashedPassword, historyCount); err != nil {
+		return nil, errors.New("修改密码失败")
+	}
+	invalidateUserCache(user.ID)
+	return &user, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+
+	"goboot/internal/model"
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+)
+
+// RBACService 角色权限服务
+type RBACService struct{}
+
+func NewRBACService() *RBACService {
+	return &RBACService{}
+}
+
+// HasPermission 检查用户是否拥有指定权限
+// role=1 的管理员视为超级管理员，拥有全部权限；其余用户按所分配角色的权限判断
+func (s *RBACService) HasPermission(userID uint, role int8, perm string) bool {
+	if role == 1 {
+		return true
+	}
+
+	codes, err := model.GetUserPermissionCodes(userID)
+	if err != nil {
+		logger.Error("获取用户权限失败", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return false
+	}
+
+	for _, code := range codes {
+		if code == perm || code == model.PermAll {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSuperAdmin 检查用户是否为超级管理员(role=1 或拥有全部权限)
+func (s *RBACService) IsSuperAdmin(userID uint, role int8) bool {
+	return s.HasPermission(userID, role, model.PermAll)
+}
+
+// GetUserPermissions 获取用户的有效权限标识
+func (s *RBACService) GetUserPermissions(userID uint, role int8) ([]string, error) {
+	if role == 1 {
+		return []string{model.PermAll}, nil
+	}
+	codes, err := model.GetUserPermissionCodes(userID)
+	if err != nil {
+		return nil, errors.New("获取用户权限失败")
+	}
+	return codes, nil
+}
+
+// GetPermissions 获取所有权限
+func (s *RBACService) GetPermissions() ([]model.Permission, error) {
+	return model.GetAllPermissions()
+}
+
+// GetRoles 获取所有角色
+func (s *RBACService) GetRoles() ([]model.Role, error) {
+	return model.GetAllRoles()
+}
+
+// CreateRole 创建角色，allowAll 表示操作人是否可以授予全部权限
+func (s *RBACService) CreateRole(code, name, remark string, permissionIDs []uint, allowAll bool) (*model.Role, error) {
+	if model.RoleExists(code) {
+		return nil, errors.New("角色标识已存在")
+	}
+
+	perms, err := s.findPermissions(permissionIDs, allowAll)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &model.Role{
+		Code:        code,
+		Name:        name,
+		Remark:      remark,
+		Permissions: perms,
+	}
+	if err := model.CreateRole(role); err != nil {
+		return nil, errors.New("创建角色失败")
+	}
+	return role, nil
+}
+
+// UpdateRole 更新角色名称、备注及权限，allowAll 表示操作人是否可以授予全部权限
+func (s *RBACService) UpdateRole(id uint, name, remark string, permissionIDs []uint, allowAll bool) (*model.Role, error) {
+	role, err := model.GetRoleByID(id)
+	if err != nil {
+		return nil, errors.New("角色不存在")
+	}
+	if role.Code == model.RoleSuperAdmin {
+		return nil, errors.New("不能修改超级管理员角色")
+	}
+
+	perms, err := s.findPermissions(permissionIDs, allowAll)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Name = name
+	role.Remark = remark
+	if err := model.UpdateRole(role, perms); err != nil {
+		return nil, errors.New("更新角色失败")
+	}
+	role.Permissions = perms
+	return role, nil
+}
+
+// DeleteRole 删除角色
+func (s *RBACService) DeleteRole(id uint) error {
+	role, err := model.GetRoleByID(id)
+	if err != nil {
+		return errors.New("角色不存在")
+	}
+	if role.Code == model.RoleSuperAdmin {
+		return errors.New("不能删除超级管理员角色")
+	}
+
+	if err := model.DeleteRole(role); err != nil {
+		return errors.New("删除角色失败")
+	}
+	return nil
+}
+
+// GetUserRoles 获取用户的角色
+func (s *RBACService) GetUserRoles(userID uint) ([]model.Role, error) {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	return model.GetUserRoles(userID)
+}
+
+// AssignUserRoles 替换用户的角色，allowAll 表示操作人是否可以分配拥有全部权限的角色
+func (s *RBACService) AssignUserRoles(userID uint, roleIDs []uint, allowAll bool) error {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+
+	roles, err := model.GetRolesByIDs(roleIDs)
+	if err != nil {
+		return errors.New("分配角色失败")
+	}
+	if len(roles) != len(uniqueIDs(roleIDs)) {
+		return errors.New("角色不存在")
+	}
+	if !allowAll {
+		for _, role := range roles {
+			if hasAllPermission(role.Permissions) {
+				return errors.New("仅超级管理员可分配拥有全部权限的角色")
+			}
+		}
+	}
+
+	if err := model.SetUserRoles(&user, roles); err != nil {
+		return errors.New("分配角色失败")
+	}
+	return nil
+}
+
+// findPermissions 根据ID列表查找权限，存在无效ID或无权授予全部权限时返回错误
+func (s *RBACService) findPermissions(ids []uint, allowAll bool) ([]model.Permission, error) {
+	perms, err := model.GetPermissionsByIDs(ids)
+	if err != nil {
+		return nil, errors.New("获取权限失败")
+	}
+	if len(perms) != len(uniqueIDs(ids)) {
+		return nil, errors.New("权限不存在")
+	}
+	if !allowAll && hasAllPermission(perms) {
+		return nil, errors.New("仅超级管理员可授予全部权限")
+	}
+	return perms, nil
+}
+
+// hasAllPermission 检查权限列表中是否包含全部权限
+func hasAllPermission(perms []model.Permission) bool {
+	for _, perm := range perms {
+		if perm.Code == model.PermAll {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueIDs 对ID列表去重
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
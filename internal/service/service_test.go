@@ -0,0 +1,54 @@
+package service
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"goboot/internal/testutil"
+)
+
+var loadConfigOnce sync.Once
+
+// setupTestEnv 初始化测试环境并加载系统配置到缓存，整个测试进程只执行一次
+func setupTestEnv(t testing.TB) {
+	t.Helper()
+	testutil.Setup(t)
+	loadConfigOnce.Do(func() {
+		GetConfigService().LoadAll()
+	})
+}
+
+// testFile 测试上传的文件
+type testFile struct {
+	name    string
+	content string
+}
+
+// multipartFiles 按顺序构造 multipart 表单中的 files 字段
+func multipartFiles(t testing.TB, files ...testFile) []*multipart.FileHeader {
+	t.Helper()
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, f := range files {
+		fw, err := w.CreateFormFile("files", f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/", &buf)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	if err := req.ParseMultipartForm(32 << 20); err != nil {
+		t.Fatal(err)
+	}
+	return req.MultipartForm.File["files"]
+}
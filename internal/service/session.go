@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"goboot/config"
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+)
+
+// SessionInfo 登录会话信息
+type SessionInfo struct {
+	ID        string    `json:"id"`        // 会话ID
+	IP        string    `json:"ip"`        // 登录IP
+	UserAgent string    `json:"userAgent"` // 登录UA
+	IssuedAt  time.Time `json:"issuedAt"`  // 登录时间
+	ExpiresAt time.Time `json:"expiresAt"` // 过期时间(Refresh Token过期时间)
+	Current   bool      `json:"current"`   // 是否为当前请求所在会话
+}
+
+func userSessionsKey(userID uint) string {
+	return fmt.Sprintf("user:sessions:%d", userID)
+}
+
+func sessionRevokedKey(sessionID string) string {
+	return fmt.Sprintf("session:revoked:%s", sessionID)
+}
+
+// sessionTTL 会话有效期，与Refresh Token一致
+func sessionTTL() time.Duration {
+	return time.Duration(config.Get().JWT.RefreshExpire) * time.Hour
+}
+
+// createSession 记录新的登录会话
+func (s *UserService) createSession(userID uint, sessionID, ip, userAgent string) error {
+	ctx := context.Background()
+	now := time.Now()
+	info := SessionInfo{
+		ID:        sessionID,
+		IP:        ip,
+		UserAgent: userAgent,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(sessionTTL()),
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	key := userSessionsKey(userID)
+	pipe := database.RDB.TxPipeline()
+	pipe.HSet(ctx, key, sessionID, data)
+	pipe.Expire(ctx, key, sessionTTL())
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetSessions 获取用户的活跃会话列表，currentSessionID 用于标记当前会话
+func (s *UserService) GetSessions(userID uint, currentSessionID string) ([]SessionInfo, error) {
+	ctx := context.Background()
+	key := userSessionsKey(userID)
+
+	values, err := database.RDB.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, errors.New("获取会话列表失败")
+	}
+
+	now := time.Now()
+	sessions := make([]SessionInfo, 0, len(values))
+	var expired []string
+	for id, raw := range values {
+		var info SessionInfo
+		if err := json.Unmarshal([]byte(raw), &info); err != nil || now.After(info.ExpiresAt) {
+			expired = append(expired, id)
+			continue
+		}
+		info.Current = id == currentSessionID
+		sessions = append(sessions, info)
+	}
+
+	// 顺带清理已过期的会话记录
+	if len(expired) > 0 {
+		database.RDB.HDel(ctx, key, expired...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].IssuedAt.After(sessions[j].IssuedAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession 撤销用户的指定会话，该会话签发的所有令牌立即失效
+func (s *UserService) RevokeSession(userID uint, sessionID string) error {
+	ctx := context.Background()
+	key := userSessionsKey(userID)
+
+	exists, err := database.RDB.HExists(ctx, key, sessionID).Result()
+	if err != nil {
+		return errors.New("撤销会话失败")
+	}
+	if !exists {
+		return ErrSessionNotFound
+	}
+
+	if err := s.revokeSessions(userID, sessionID); err != nil {
+		return errors.New("撤销会话失败")
+	}
+	return nil
+}
+
+// LogoutAll 撤销用户的全部会话
+func (s *UserService) LogoutAll(userID uint) error {
+	ctx := context.Background()
+	ids, err := database.RDB.HKeys(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return errors.New("退出登录失败")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.revokeSessions(userID, ids...); err != nil {
+		return errors.New("退出登录失败")
+	}
+	return nil
+}
+
+// revokeSessions 将会话加入黑名单并从活跃列表中移除
+func (s *UserService) revokeSessions(userID uint, sessionIDs ...string) error {
+	ctx := context.Background()
+	pipe := database.RDB.TxPipeline()
+	for _, id := range sessionIDs {
+		pipe.Set(ctx, sessionRevokedKey(id), userID, sessionTTL())
+	}
+	pipe.HDel(ctx, userSessionsKey(userID), sessionIDs...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("撤销会话失败", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// IsSessionRevoked 检查会话是否已被撤销，Redis 不可用时按 redis.token_check_fail_closed 处理
+func (s *UserService) IsSessionRevoked(sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+	ctx := context.Background()
+	exists, err := database.RDB.Exists(ctx, sessionRevokedKey(sessionID)).Result()
+	if err != nil {
+		return tokenCheckFailed("检查会话状态失败", err)
+	}
+	return exists > 0
+}
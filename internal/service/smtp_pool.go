@@ -0,0 +1,249 @@
+package service
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"time"
+
+	"goboot/pkg/logger"
+)
+
+// SMTP 连接池的默认参数
+const (
+	defaultSMTPPoolSize    = 2
+	defaultSMTPIdleTimeout = 60 * time.Second
+	smtpDialTimeout        = 10 * time.Second
+	smtpJanitorInterval    = 30 * time.Second
+)
+
+// pooledSMTPClient 连接池中空闲的已认证连接
+type pooledSMTPClient struct {
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// smtpPool SMTP 连接池，复用已认证的连接发送邮件，空闲超时的连接由后台协程关闭
+// 邮件配置变更后旧连接全部丢弃，按新配置重新建立
+type smtpPool struct {
+	mu          sync.Mutex
+	key         string // 建立连接时的配置标识
+	idle        []*pooledSMTPClient
+	size        int
+	idleTimeout time.Duration
+	closed      bool
+}
+
+var (
+	smtpPoolInstance = &smtpPool{}
+	smtpJanitorOnce  sync.Once
+)
+
+// smtpConfigKey 影响连接的配置项，任一项变化时连接不可复用
+func smtpConfigKey(cfg *EmailConfig) string {
+	return fmt.Sprintf("%s:%d|%s|%s|%t", cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.SSL)
+}
+
+// send 从连接池取出连接发送一封邮件，成功后归还连接，失败时关闭该连接
+func (p *smtpPool) send(cfg *EmailConfig, to []string, msg []byte) error {
+	smtpJanitorOnce.Do(func() { go p.janitor() })
+
+	client, err := p.get(cfg)
+	if err != nil {
+		return err
+	}
+	if err := smtpTransaction(client, cfg.FromAddr, to, msg); err != nil {
+		client.Close()
+		return err
+	}
+	p.put(cfg, client)
+	return nil
+}
+
+// get 取出一个可用连接：跳过空闲超时的连接，用 NOOP 检测服务端是否已断开，无可用连接时新建
+func (p *smtpPool) get(cfg *EmailConfig) (*smtp.Client, error) {
+	key := smtpConfigKey(cfg)
+
+	p.mu.Lock()
+	p.size = positiveOr(cfg.PoolSize, defaultSMTPPoolSize)
+	p.idleTimeout = defaultSMTPIdleTimeout
+	if cfg.PoolIdleTimeout > 0 {
+		p.idleTimeout = time.Duration(cfg.PoolIdleTimeout) * time.Second
+	}
+	var stale []*smtp.Client
+	if p.key != key {
+		stale = p.drainLocked()
+		p.key = key
+	}
+	var reuse *smtp.Client
+	for len(p.idle) > 0 {
+		pc := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if time.Since(pc.lastUsed) > p.idleTimeout {
+			stale = append(stale, pc.client)
+			continue
+		}
+		reuse = pc.client
+		break
+	}
+	p.mu.Unlock()
+
+	for _, c := range stale {
+		c.Close()
+	}
+	if reuse != nil {
+		// 服务端可能已关闭空闲连接，检测失败时透明地重新建立连接
+		if err := reuse.Noop(); err == nil {
+			return reuse, nil
+		}
+		reuse.Close()
+	}
+	return dialSMTP(cfg)
+}
+
+// put 归还连接，连接池已满、已关闭或配置已变更时关闭连接
+func (p *smtpPool) put(cfg *EmailConfig, client *smtp.Client) {
+	if err := client.Reset(); err != nil {
+		client.Close()
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed || p.key != smtpConfigKey(cfg) || len(p.idle) >= p.size {
+		p.mu.Unlock()
+		client.Quit()
+		return
+	}
+	p.idle = append(p.idle, &pooledSMTPClient{client: client, lastUsed: time.Now()})
+	p.mu.Unlock()
+}
+
+// drainLocked 清空空闲连接并返回，调用方需持有锁，在锁外关闭返回的连接
+func (p *smtpPool) drainLocked() []*smtp.Client {
+	clients := make([]*smtp.Client, 0, len(p.idle))
+	for _, pc := range p.idle {
+		clients = append(clients, pc.client)
+	}
+	p.idle = nil
+	return clients
+}
+
+// janitor 定期关闭空闲超时的连接
+func (p *smtpPool) janitor() {
+	ticker := time.NewTicker(smtpJanitorInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return
+		}
+		var expired []*smtp.Client
+		kept := p.idle[:0]
+		for _, pc := range p.idle {
+			if time.Since(pc.lastUsed) > p.idleTimeout {
+				expired = append(expired, pc.client)
+			} else {
+				kept = append(kept, pc)
+			}
+		}
+		p.idle = kept
+		p.mu.Unlock()
+
+		for _, c := range expired {
+			c.Quit()
+		}
+		if len(expired) > 0 {
+			logger.Debug("关闭空闲的SMTP连接", slog.Int("count", len(expired)))
+		}
+	}
+}
+
+// CloseSMTPPool 关闭连接池中的所有空闲连接，在服务关闭时调用，之后发送的邮件不再复用连接
+func CloseSMTPPool() {
+	p := smtpPoolInstance
+	p.mu.Lock()
+	p.closed = true
+	clients := p.drainLocked()
+	p.mu.Unlock()
+
+	for _, c := range clients {
+		c.Quit()
+	}
+}
+
+// dialSMTP 建立并认证一个 SMTP 连接，SSL 时使用隐式 TLS，否则在服务端支持时升级为 STARTTLS
+func dialSMTP(cfg *EmailConfig) (*smtp.Client, error) {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	dialer := &net.Dialer{Timeout: smtpDialTimeout}
+
+	var conn net.Conn
+	var err error
+	if cfg.SSL {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+			InsecureSkipVerify: true,
+			ServerName:         cfg.Host,
+		})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("连接SMTP服务器失败: %v", err)
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("创建SMTP客户端失败: %v", err)
+	}
+
+	if !cfg.SSL {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("SMTP启用TLS失败: %v", err)
+			}
+		}
+	}
+
+	if ok, _ := client.Extension("AUTH"); ok {
+		auth := smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP认证失败: %v", err)
+		}
+	}
+	return client, nil
+}
+
+// smtpTransaction 在已认证的连接上发送一封邮件，不关闭连接
+func smtpTransaction(client *smtp.Client, from string, to []string, msg []byte) error {
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("设置发件人失败: %v", err)
+	}
+
+	for _, addr := range to {
+		if err := client.Rcpt(addr); err != nil {
+			return fmt.Errorf("设置收件人失败: %v", err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("获取写入器失败: %v", err)
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("写入邮件内容失败: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("关闭写入器失败: %v", err)
+	}
+	return nil
+}
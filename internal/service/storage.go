@@ -3,6 +3,7 @@ package service
 import (
 	"io"
 	"mime/multipart"
+	"sync"
 	"time"
 )
 
@@ -49,4 +50,53 @@ type Storage interface {
 	// GetInfo 获取文件信息
 	// path: 文件完整路径
 	GetInfo(path string) (*FileInfo, error)
+
+	// Open 打开文件用于读取，调用方负责关闭
+	// path: 文件完整路径
+	Open(path string) (io.ReadCloser, error)
+
+	// PresignURL 生成限时有效的下载链接，对象存储返回原生的预签名URL，客户端可直接下载而无需经过应用转发
+	// path: 文件完整路径
+	// expiry: 有效期
+	PresignURL(path string, expiry time.Duration) (string, error)
+}
+
+var (
+	storageMu     sync.Mutex
+	storageKey    string
+	sharedStorage Storage
+)
+
+// currentStorage 返回按上传配置创建的存储后端，存储类型、本地路径或访问URL变更后重新创建
+func currentStorage(cfg *UploadConfigDB) Storage {
+	key := cfg.StorageType + "|" + cfg.LocalPath + "|" + cfg.BaseURL
+
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	if sharedStorage == nil || storageKey != key {
+		sharedStorage = newStorage(cfg)
+		storageKey = key
+	}
+	return sharedStorage
+}
+
+// ReloadStorage 丢弃当前的存储后端，下次上传时按最新的上传配置重新创建
+func ReloadStorage() {
+	storageMu.Lock()
+	sharedStorage = nil
+	storageMu.Unlock()
+}
+
+// newStorage 根据存储类型创建存储后端
+func newStorage(cfg *UploadConfigDB) Storage {
+	switch cfg.StorageType {
+	case "local":
+		return NewLocalStorage(cfg.LocalPath, cfg.BaseURL)
+	// case "oss":
+	//     return NewOSSStorage()
+	// case "s3":
+	//     return NewS3Storage()
+	default:
+		return NewLocalStorage(cfg.LocalPath, cfg.BaseURL)
+	}
 }
@@ -1,11 +1,18 @@
 package service
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,18 +21,30 @@ import (
 	"github.com/google/uuid"
 )
 
+// localPresignPath 本地存储签名下载链接的处理接口
+const localPresignPath = "/api/upload/signed"
+
+// presignKeyLabel 未配置 upload.sign_secret 时从 jwt.secret 派生签名密钥使用的标签
+const presignKeyLabel = "goboot/upload/presign"
+
 // LocalStorage 本地文件存储实现
 type LocalStorage struct {
-	basePath string // 文件存储根目录
-	baseURL  string // 文件访问URL前缀
+	basePath   string // 文件存储根目录
+	baseURL    string // 文件访问URL前缀
+	signSecret string // 签名下载链接的密钥
 }
 
-// NewLocalStorage 创建本地存储实例
-func NewLocalStorage() *LocalStorage {
-	cfg := &config.AppConfig.Upload
+// NewLocalStorage 创建本地存储实例，basePath 为存储根目录，baseURL 为访问URL前缀
+func NewLocalStorage(basePath, baseURL string) *LocalStorage {
+	cfg := config.Get()
+	signSecret := cfg.Upload.SignSecret
+	if signSecret == "" && cfg.JWT.Secret != "" {
+		signSecret = derivePresignKey(cfg.JWT.Secret)
+	}
 	return &LocalStorage{
-		basePath: cfg.LocalPath,
-		baseURL:  cfg.BaseURL,
+		basePath:   basePath,
+		baseURL:    baseURL,
+		signSecret: signSecret,
 	}
 }
 
@@ -186,6 +205,9 @@ func (s *LocalStorage) GetInfo(path string) (*FileInfo, error) {
 
 	ext := strings.ToLower(filepath.Ext(path))
 	mimeType := getMimeType(ext)
+	if mimeType == "application/octet-stream" && stat.Mode().IsRegular() {
+		mimeType = detectMimeType(fullPath)
+	}
 
 	return &FileInfo{
 		Name:      stat.Name(),
@@ -198,6 +220,60 @@ func (s *LocalStorage) GetInfo(path string) (*FileInfo, error) {
 	}, nil
 }
 
+// Open 打开文件用于读取
+func (s *LocalStorage) Open(path string) (io.ReadCloser, error) {
+	file, err := os.Open(filepath.Join(s.basePath, path))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("文件不存在")
+		}
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	return file, nil
+}
+
+// PresignURL 生成签名下载链接，指向本地的签名下载接口，由 VerifyPresign 校验签名和有效期
+func (s *LocalStorage) PresignURL(path string, expiry time.Duration) (string, error) {
+	expires := time.Now().Add(expiry).Unix()
+	sign, err := s.sign(path, expires)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("path", filepath.ToSlash(path))
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("sign", sign)
+	return localPresignPath + "?" + query.Encode(), nil
+}
+
+// VerifyPresign 校验签名下载链接，expires 为过期时间戳(秒)
+func (s *LocalStorage) VerifyPresign(path string, expires int64, sign string) bool {
+	if time.Now().Unix() > expires {
+		return false
+	}
+	expected, err := s.sign(path, expires)
+	return err == nil && hmac.Equal([]byte(expected), []byte(sign))
+}
+
+// derivePresignKey 以 jwt.secret 为密钥对固定标签做 HMAC-SHA256 派生签名密钥，
+// 下载链接的签名不直接使用 JWT 密钥，二者互不能用于伪造对方的签名
+func derivePresignKey(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(presignKeyLabel))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sign 使用 HMAC-SHA256 对路径和过期时间签名
+func (s *LocalStorage) sign(path string, expires int64) (string, error) {
+	if s.signSecret == "" {
+		return "", errors.New("未配置文件签名密钥")
+	}
+	mac := hmac.New(sha256.New, []byte(s.signSecret))
+	mac.Write([]byte(filepath.ToSlash(path) + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
 // generateFilename 生成唯一文件名
 func (s *LocalStorage) generateFilename(ext string) string {
 	return uuid.New().String() + ext
@@ -244,3 +320,20 @@ func getMimeType(ext string) string {
 	}
 	return "application/octet-stream"
 }
+
+// detectMimeType 读取文件头部按内容识别MIME类型，用于无扩展名或扩展名未知的文件，读取失败时返回 application/octet-stream
+func detectMimeType(fullPath string) string {
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer file.Close()
+
+	// DetectContentType 最多读取前 512 字节
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "application/octet-stream"
+	}
+	return http.DetectContentType(head[:n])
+}
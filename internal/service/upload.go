@@ -1,51 +1,75 @@
 package service
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"mime/multipart"
+	"net/url"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
-	"goboot/config"
+	"goboot/internal/model"
+	"goboot/pkg/imageutil"
+	"goboot/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PublicCategory 公开目录，其中的文件(如头像)可通过 /uploads/public/* 直接访问，
+// 其他目录的文件需登录后通过 /api/upload/download 下载
+const PublicCategory = "public"
+
+const (
+	avatarCategory           = PublicCategory + "/avatars" // 头像存储目录
+	defaultMaxAvatarSize     = 2                           // 未配置时的最大头像大小(MB)
+	defaultUploadConcurrency = 4                           // 未配置时批量上传的并发数
+)
+
+// 签名下载链接的有效期
+const (
+	DefaultPresignExpiry = 5 * time.Minute // 默认有效期
+	MaxPresignExpiry     = 24 * time.Hour  // 最长有效期
+)
+
+var (
+	// ErrFileForbidden 无权访问文件
+	ErrFileForbidden = errors.New("无权访问该文件")
+	// ErrPresignInvalid 签名下载链接无效或已过期
+	ErrPresignInvalid = errors.New("下载链接无效或已过期")
+)
+
+var (
+	errInvalidPath     = errors.New("无效的文件路径")
+	errInvalidCategory = errors.New("无效的分类目录，只能包含字母、数字、下划线和短横线")
+	errPublicCategory  = errors.New("不能上传到公开目录")
 )
 
-// UploadService 文件上传服务
+// categoryRegex 客户端指定的分类目录，只允许单级目录，防止路径穿越
+var categoryRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// UploadService 文件上传服务，上传配置从系统配置读取，通过管理接口修改后立即生效
 type UploadService struct {
-	storage Storage
-	config  *config.UploadConfig
+	storage Storage // 自定义存储后端，为空时使用按当前上传配置创建的存储后端
 }
 
 // NewUploadService 创建上传服务实例
 func NewUploadService() *UploadService {
-	cfg := &config.AppConfig.Upload
-
-	// 根据配置选择存储后端
-	var storage Storage
-	switch cfg.StorageType {
-	case "local":
-		storage = NewLocalStorage()
-	// case "oss":
-	//     storage = NewOSSStorage()
-	// case "s3":
-	//     storage = NewS3Storage()
-	default:
-		storage = NewLocalStorage()
-	}
-
-	return &UploadService{
-		storage: storage,
-		config:  cfg,
-	}
+	return &UploadService{}
 }
 
 // NewUploadServiceWithStorage 使用自定义存储后端创建上传服务
 func NewUploadServiceWithStorage(storage Storage) *UploadService {
-	return &UploadService{
-		storage: storage,
-		config:  &config.AppConfig.Upload,
-	}
+	return &UploadService{storage: storage}
 }
 
 // SetStorage 设置存储后端
@@ -53,21 +77,40 @@ func (s *UploadService) SetStorage(storage Storage) {
 	s.storage = storage
 }
 
-// UploadFile 上传单个文件
-func (s *UploadService) UploadFile(file *multipart.FileHeader, category string) (*FileInfo, error) {
+// config 当前上传配置
+func (s *UploadService) config() *UploadConfigDB {
+	return GetConfigService().GetUploadConfig()
+}
+
+// getStorage 当前使用的存储后端
+func (s *UploadService) getStorage() Storage {
+	if s.storage != nil {
+		return s.storage
+	}
+	return currentStorage(s.config())
+}
+
+// UploadFile 上传单个文件，userID 为上传用户，category 为客户端指定的分类目录
+func (s *UploadService) UploadFile(userID uint, file *multipart.FileHeader, category string) (*FileInfo, error) {
+	cfg := s.config()
+
 	// 检查是否启用
-	if !s.config.Enabled {
+	if !cfg.Enabled {
 		return nil, errors.New("文件上传服务未启用")
 	}
 
+	if err := validateCategory(category); err != nil {
+		return nil, err
+	}
+
 	// 验证文件大小
-	if err := s.validateFileSize(file.Size); err != nil {
+	if err := validateFileSize(cfg, file.Size); err != nil {
 		return nil, err
 	}
 
 	// 验证文件类型
 	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if err := s.validateFileType(ext); err != nil {
+	if err := validateFileType(cfg, ext); err != nil {
 		return nil, err
 	}
 
@@ -75,103 +118,466 @@ func (s *UploadService) UploadFile(file *multipart.FileHeader, category string)
 	path := s.generatePath(category)
 
 	// 上传文件
-	return s.storage.Upload(file, path, "")
+	return s.uploadMultipart(cfg, userID, file, path, ext)
+}
+
+// UploadImage 上传图片(仅允许图片格式)，category 为客户端指定的分类目录
+func (s *UploadService) UploadImage(userID uint, file *multipart.FileHeader, category string) (*FileInfo, error) {
+	if err := validateCategory(category); err != nil {
+		return nil, err
+	}
+	return s.uploadImage(userID, file, category)
 }
 
-// UploadImage 上传图片(仅允许图片格式)
-func (s *UploadService) UploadImage(file *multipart.FileHeader, category string) (*FileInfo, error) {
+// uploadImage 上传图片到指定目录，category 不做校验，只用于服务端确定的目录(如头像)
+func (s *UploadService) uploadImage(userID uint, file *multipart.FileHeader, category string) (*FileInfo, error) {
+	cfg := s.config()
+
 	// 检查是否启用
-	if !s.config.Enabled {
+	if !cfg.Enabled {
 		return nil, errors.New("文件上传服务未启用")
 	}
 
 	// 验证文件大小
-	if err := s.validateImageSize(file.Size); err != nil {
+	if err := validateImageSize(cfg, file.Size); err != nil {
 		return nil, err
 	}
 
 	// 验证是否为图片
 	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !s.isImageExt(ext) {
-		return nil, fmt.Errorf("不支持的图片格式: %s，允许的格式: %v", ext, s.config.ImageExts)
+	if !isImageExt(cfg, ext) {
+		return nil, fmt.Errorf("不支持的图片格式: %s，允许的格式: %v", ext, cfg.ImageExts)
 	}
 
 	// 生成存储路径
 	path := s.generatePath(category)
 
+	if cfg.StripExif || cfg.AutoOrient {
+		return s.uploadProcessedImage(cfg, userID, file, path, ext)
+	}
+
 	// 上传文件
-	return s.storage.Upload(file, path, "")
+	return s.uploadMultipart(cfg, userID, file, path, ext)
 }
 
-// UploadFiles 批量上传文件
-func (s *UploadService) UploadFiles(files []*multipart.FileHeader, category string) ([]*FileInfo, []error) {
-	results := make([]*FileInfo, 0, len(files))
-	errs := make([]error, 0)
+// uploadProcessedImage 按配置去除 JPEG/WebP 图片的元数据并自动旋转后保存，无需处理时保存原文件
+func (s *UploadService) uploadProcessedImage(cfg *UploadConfigDB, userID uint, file *multipart.FileHeader, path, ext string) (*FileInfo, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("打开上传文件失败: %v", err)
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("读取上传文件失败: %v", err)
+	}
 
-	for _, file := range files {
-		info, err := s.UploadFile(file, category)
+	// 内容为 SVG/HTML 时按策略处理，不再作为图片处理
+	if kind := detectActiveContent(data[:min(len(data), activeContentSniffLen)], ext); kind != "" {
+		return s.saveActiveContent(cfg, kind, userID, data, file.Filename, path, ext)
+	}
+
+	processed, changed, err := imageutil.Process(data, ext, imageutil.Options{
+		StripMetadata: cfg.StripExif,
+		AutoOrient:    cfg.AutoOrient,
+		Quality:       cfg.ImageQuality,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("图片处理失败: %v", err)
+	}
+	if !changed {
+		return s.save(userID, bytes.NewReader(data), int64(len(data)), file.Filename, path, ext, file.Header.Get("Content-Type"))
+	}
+	return s.save(userID, bytes.NewReader(processed), int64(len(processed)), file.Filename, path, ext, getMimeType(ext))
+}
+
+// uploadMultipart 保存上传的文件，内容为 SVG/HTML 时按上传配置的策略拒绝、清理或原样保存
+func (s *UploadService) uploadMultipart(cfg *UploadConfigDB, userID uint, file *multipart.FileHeader, path, ext string) (*FileInfo, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("打开上传文件失败: %v", err)
+	}
+	defer src.Close()
+
+	reader := bufio.NewReaderSize(src, activeContentSniffLen)
+	head, err := reader.Peek(activeContentSniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("读取上传文件失败: %v", err)
+	}
+	if kind := detectActiveContent(head, ext); kind != "" {
+		data, err := io.ReadAll(reader)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("%s: %v", file.Filename, err))
+			return nil, fmt.Errorf("读取上传文件失败: %v", err)
+		}
+		return s.saveActiveContent(cfg, kind, userID, data, file.Filename, path, ext)
+	}
+
+	return s.save(userID, reader, file.Size, file.Filename, path, ext, file.Header.Get("Content-Type"))
+}
+
+// saveActiveContent 按策略处理 SVG/HTML 文件后保存
+func (s *UploadService) saveActiveContent(cfg *UploadConfigDB, kind string, userID uint, data []byte, name, path, ext string) (*FileInfo, error) {
+	data, mimeType, err := applyActiveContentPolicy(cfg, kind, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.save(userID, bytes.NewReader(data), int64(len(data)), name, path, ext, mimeType)
+}
+
+// save 写入存储的同时计算内容的 SHA-256，并保存上传记录。
+// 开启去重时，如已有相同内容的文件，删除刚写入的文件，返回已有文件的路径和URL
+func (s *UploadService) save(userID uint, reader io.Reader, size int64, name, path, ext, mimeType string) (*FileInfo, error) {
+	storage := s.getStorage()
+	hasher := sha256.New()
+	info, err := storage.UploadFromReader(io.TeeReader(reader, hasher), size, path, uuid.New().String()+ext, mimeType)
+	if err != nil {
+		return nil, err
+	}
+	info.Name = name
+	info.URL = s.fileURL(info.Path)
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	// 只在公开性相同的文件间去重，避免公开文件引用到需要鉴权的路径
+	deduped := false
+	if s.config().Dedup {
+		existing, err := model.GetUploadedFileByHash(hash, ext)
+		if err == nil && existing.Size == info.Size && isPublicPath(existing.Path) == isPublicPath(info.Path) {
+			if exists, _ := storage.Exists(existing.Path); exists {
+				if err := storage.Delete(info.Path); err != nil {
+					logger.Warn("删除重复文件失败", slog.String("path", info.Path), slog.Any("error", err))
+				}
+				info.Path = existing.Path
+				info.URL = s.fileURL(existing.Path)
+				deduped = true
+			}
+		}
+	}
+
+	record := &model.UploadedFile{
+		UserID:    userID,
+		Name:      info.Name,
+		Path:      info.Path,
+		Hash:      hash,
+		Size:      info.Size,
+		MimeType:  info.MimeType,
+		Extension: info.Extension,
+	}
+	if err := model.CreateUploadedFile(record); err != nil {
+		if !deduped {
+			if delErr := storage.Delete(info.Path); delErr != nil {
+				logger.Warn("删除上传文件失败", slog.String("path", info.Path), slog.Any("error", delErr))
+			}
+		}
+		return nil, fmt.Errorf("保存文件记录失败: %v", err)
+	}
+	return info, nil
+}
+
+// UploadAvatar 上传头像，仅允许图片格式，存放在 avatars 目录，
+// 大小同时受 MaxAvatarSize(默认 2MB) 和图片大小限制
+func (s *UploadService) UploadAvatar(userID uint, file *multipart.FileHeader) (*FileInfo, error) {
+	maxAvatarSize := s.config().MaxAvatarSize
+	if maxAvatarSize <= 0 {
+		maxAvatarSize = defaultMaxAvatarSize
+	}
+	if file.Size > int64(maxAvatarSize)*1024*1024 {
+		return nil, fmt.Errorf("头像大小超出限制，最大允许 %dMB", maxAvatarSize)
+	}
+
+	return s.uploadImage(userID, file, avatarCategory)
+}
+
+// UploadFileError 批量上传中单个文件的失败原因
+type UploadFileError struct {
+	Index    int    `json:"index"`    // 文件在请求中的序号，从 0 开始
+	Filename string `json:"filename"` // 原始文件名
+	Error    string `json:"error"`    // 失败原因
+}
+
+// UploadFiles 批量上传文件，按上传配置的并发数同时上传，单个文件失败不影响其他文件，
+// 成功结果和失败原因均保持输入顺序，失败原因按文件序号区分同名文件。同一批次中内容相同的文件可能不会去重
+func (s *UploadService) UploadFiles(userID uint, files []*multipart.FileHeader, category string) ([]*FileInfo, []UploadFileError) {
+	workers := s.config().Concurrency
+	if workers <= 0 {
+		workers = defaultUploadConcurrency
+	}
+	workers = min(workers, len(files))
+
+	// 每个文件的结果写入各自下标，等待全部完成后再按顺序汇总
+	infos := make([]*FileInfo, len(files))
+	fileErrs := make([]error, len(files))
+
+	var wg sync.WaitGroup
+	indexes := make(chan int)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				infos[i], fileErrs[i] = s.uploadFileSafe(userID, files[i], category)
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	results := make([]*FileInfo, 0, len(files))
+	var errs []UploadFileError
+	for i, file := range files {
+		if fileErrs[i] != nil {
+			errs = append(errs, UploadFileError{Index: i, Filename: file.Filename, Error: fileErrs[i].Error()})
 			continue
 		}
-		results = append(results, info)
+		results = append(results, infos[i])
 	}
 
 	return results, errs
 }
 
-// DeleteFile 删除文件
-func (s *UploadService) DeleteFile(path string) error {
-	return s.storage.Delete(path)
+// uploadFileSafe 上传单个文件，panic 时转为错误，避免影响同批次的其他文件
+func (s *UploadService) uploadFileSafe(userID uint, file *multipart.FileHeader, category string) (info *FileInfo, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("上传文件异常", slog.String("filename", file.Filename), slog.Any("panic", r))
+			info, err = nil, errors.New("上传文件失败")
+		}
+	}()
+	return s.UploadFile(userID, file, category)
+}
+
+// DeleteFile 删除用户上传的文件。删除的是该用户的上传记录，
+// 去重后其他上传记录仍引用同一文件时保留文件，最后一个引用删除时才删除文件；
+// 没有任何上传记录的文件(如启用上传记录前上传的)无法确认上传者，仅管理员或公开目录的文件可以删除
+func (s *UploadService) DeleteFile(userID uint, isAdmin bool, path string) error {
+	path, err := cleanPath(path)
+	if err != nil {
+		return err
+	}
+
+	record, err := model.GetUploadedFileByUser(userID, path)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		refs, err := model.CountUploadedFileRefs(path)
+		if err != nil {
+			return err
+		}
+		if refs > 0 || !(isAdmin || isPublicPath(path)) {
+			return ErrFileForbidden
+		}
+		return s.getStorage().Delete(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := model.DeleteUploadedFile(record.ID); err != nil {
+		return err
+	}
+	refs, err := model.CountUploadedFileRefs(path)
+	if err != nil {
+		return err
+	}
+	if refs > 0 {
+		return nil
+	}
+	return s.getStorage().Delete(path)
+}
+
+// OpenFile 打开文件用于下载，非公开目录的文件仅上传者和管理员可以下载，
+// 返回的 FileInfo.Name 为上传时的原始文件名，调用方负责关闭 reader
+func (s *UploadService) OpenFile(userID uint, isAdmin bool, path string) (io.ReadCloser, *FileInfo, error) {
+	path, err := cleanPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name, err := s.authorize(userID, isAdmin, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.open(path, name)
+}
+
+// PresignURL 生成限时有效的下载链接，权限要求同 OpenFile；
+// expiry<=0 时使用 DefaultPresignExpiry，最长 MaxPresignExpiry
+func (s *UploadService) PresignURL(userID uint, isAdmin bool, path string, expiry time.Duration) (string, time.Time, error) {
+	path, err := cleanPath(path)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if _, err := s.authorize(userID, isAdmin, path); err != nil {
+		return "", time.Time{}, err
+	}
+	storage := s.getStorage()
+	if exists, err := storage.Exists(path); err != nil {
+		return "", time.Time{}, err
+	} else if !exists {
+		return "", time.Time{}, errors.New("文件不存在")
+	}
+
+	if expiry <= 0 {
+		expiry = DefaultPresignExpiry
+	}
+	if expiry > MaxPresignExpiry {
+		expiry = MaxPresignExpiry
+	}
+	expiresAt := time.Now().Add(expiry)
+	signedURL, err := storage.PresignURL(path, expiry)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signedURL, expiresAt, nil
+}
+
+// OpenPresigned 校验本地存储的签名下载链接并打开文件，签名无效或已过期时返回 ErrPresignInvalid
+func (s *UploadService) OpenPresigned(path string, expires int64, sign string) (io.ReadCloser, *FileInfo, error) {
+	path, err := cleanPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	local, ok := s.getStorage().(*LocalStorage)
+	if !ok || !local.VerifyPresign(path, expires, sign) {
+		return nil, nil, ErrPresignInvalid
+	}
+
+	name := filepath.Base(path)
+	if record, err := model.GetUploadedFileByPath(path); err == nil {
+		name = record.Name
+	}
+	return s.open(path, name)
+}
+
+// authorize 检查用户能否访问文件，返回用户上传时的原始文件名
+func (s *UploadService) authorize(userID uint, isAdmin bool, path string) (string, error) {
+	record, err := model.GetUploadedFileByUser(userID, path)
+	switch {
+	case err == nil:
+		return record.Name, nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return "", err
+	case !isAdmin && !isPublicPath(path):
+		return "", ErrFileForbidden
+	}
+	return filepath.Base(path), nil
 }
 
-// GetFileInfo 获取文件信息
-func (s *UploadService) GetFileInfo(path string) (*FileInfo, error) {
-	return s.storage.GetInfo(path)
+// open 打开文件，name 为下载时使用的文件名
+func (s *UploadService) open(path, name string) (io.ReadCloser, *FileInfo, error) {
+	storage := s.getStorage()
+	info, err := storage.GetInfo(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := storage.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info.Name = name
+	info.URL = s.fileURL(path)
+	return reader, info, nil
+}
+
+// GetFileInfo 获取文件信息，权限要求同 OpenFile
+func (s *UploadService) GetFileInfo(userID uint, isAdmin bool, path string) (*FileInfo, error) {
+	path, err := cleanPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := s.authorize(userID, isAdmin, path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.getStorage().GetInfo(path)
+	if err != nil {
+		return nil, err
+	}
+	info.Name = name
+	info.URL = s.fileURL(path)
+	return info, nil
 }
 
 // FileExists 检查文件是否存在
 func (s *UploadService) FileExists(path string) (bool, error) {
-	return s.storage.Exists(path)
+	return s.getStorage().Exists(path)
 }
 
 // GetFileURL 获取文件访问URL
 func (s *UploadService) GetFileURL(path string) string {
-	return s.storage.GetURL(path)
+	return s.fileURL(path)
+}
+
+// fileURL 公开目录的文件返回存储的访问URL，其他文件返回鉴权下载接口的地址
+func (s *UploadService) fileURL(path string) string {
+	if isPublicPath(path) {
+		return s.getStorage().GetURL(path)
+	}
+	return "/api/upload/download?path=" + url.QueryEscape(filepath.ToSlash(path))
+}
+
+// isPublicPath 是否为公开目录下的文件
+func isPublicPath(path string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	return path == PublicCategory || strings.HasPrefix(path, PublicCategory+"/")
+}
+
+// validateCategory 校验客户端指定的分类目录：只能是单级目录，公开目录保留给头像等服务端写入的文件
+func validateCategory(category string) error {
+	if !categoryRegex.MatchString(category) {
+		return errInvalidCategory
+	}
+	if strings.EqualFold(category, PublicCategory) {
+		return errPublicCategory
+	}
+	return nil
+}
+
+// cleanPath 规范化客户端传入的文件路径，拒绝绝对路径和指向存储目录之外的路径
+func cleanPath(path string) (string, error) {
+	cleaned := filepath.Clean(path)
+	if path == "" || cleaned == "." || filepath.IsAbs(cleaned) ||
+		cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", errInvalidPath
+	}
+	return cleaned, nil
 }
 
 // validateFileSize 验证文件大小
-func (s *UploadService) validateFileSize(size int64) error {
-	maxSize := int64(s.config.MaxSize) * 1024 * 1024 // MB转字节
+func validateFileSize(cfg *UploadConfigDB, size int64) error {
+	maxSize := int64(cfg.MaxSize) * 1024 * 1024 // MB转字节
 	if size > maxSize {
-		return fmt.Errorf("文件大小超出限制，最大允许 %dMB", s.config.MaxSize)
+		return fmt.Errorf("文件大小超出限制，最大允许 %dMB", cfg.MaxSize)
 	}
 	return nil
 }
 
 // validateImageSize 验证图片大小
-func (s *UploadService) validateImageSize(size int64) error {
-	maxSize := int64(s.config.MaxImageSize) * 1024 * 1024 // MB转字节
+func validateImageSize(cfg *UploadConfigDB, size int64) error {
+	maxSize := int64(cfg.MaxImageSize) * 1024 * 1024 // MB转字节
 	if size > maxSize {
-		return fmt.Errorf("图片大小超出限制，最大允许 %dMB", s.config.MaxImageSize)
+		return fmt.Errorf("图片大小超出限制，最大允许 %dMB", cfg.MaxImageSize)
 	}
 	return nil
 }
 
 // validateFileType 验证文件类型
-func (s *UploadService) validateFileType(ext string) error {
+func validateFileType(cfg *UploadConfigDB, ext string) error {
 	// 检查是否在允许列表中
-	for _, allowed := range s.config.AllowedExts {
+	for _, allowed := range cfg.AllowedExts {
 		if ext == allowed {
 			return nil
 		}
 	}
-	return fmt.Errorf("不支持的文件格式: %s，允许的格式: %v", ext, s.config.AllowedExts)
+	return fmt.Errorf("不支持的文件格式: %s，允许的格式: %v", ext, cfg.AllowedExts)
 }
 
 // isImageExt 检查是否为图片扩展名
-func (s *UploadService) isImageExt(ext string) bool {
-	for _, imgExt := range s.config.ImageExts {
+func isImageExt(cfg *UploadConfigDB, ext string) bool {
+	for _, imgExt := range cfg.ImageExts {
 		if ext == imgExt {
 			return true
 		}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"goboot/pkg/imageutil"
+)
+
+// 上传 SVG/HTML 等可执行脚本的文件时的处理策略
+const (
+	ActiveContentReject   = "reject"   // 拒绝上传
+	ActiveContentSanitize = "sanitize" // 清理 SVG 中的脚本后保存，HTML 仍拒绝
+	ActiveContentAllow    = "allow"    // 原样保存
+)
+
+// 可执行脚本的文件类型
+const (
+	activeContentSVG  = "svg"
+	activeContentHTML = "html"
+)
+
+// activeContentSniffLen 识别文件类型时读取的文件头长度
+const activeContentSniffLen = 1024
+
+// detectActiveContent 按扩展名和文件内容识别 SVG/HTML，内容优先于扩展名，
+// 扩展名为 .png 但内容为 SVG/HTML 的文件同样识别；不是这两种类型时返回空
+func detectActiveContent(head []byte, ext string) string {
+	contentType := http.DetectContentType(head)
+	if strings.HasPrefix(contentType, "text/html") {
+		return activeContentHTML
+	}
+	// 只检查以标签开头的文本文件，避免二进制图片或普通文本中恰好出现 <svg 被误判
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\xEF\xBB\xBF")), " \t\r\n")
+	if strings.HasPrefix(contentType, "text/") && bytes.HasPrefix(trimmed, []byte("<")) {
+		lower := bytes.ToLower(trimmed)
+		switch {
+		case bytes.Contains(lower, []byte("<svg")):
+			return activeContentSVG
+		case bytes.Contains(lower, []byte("<html")), bytes.Contains(lower, []byte("<script")):
+			return activeContentHTML
+		}
+	}
+
+	switch ext {
+	case ".svg", ".svgz":
+		return activeContentSVG
+	case ".html", ".htm", ".xhtml", ".shtml":
+		return activeContentHTML
+	}
+	return ""
+}
+
+// applyActiveContentPolicy 按上传配置处理 SVG/HTML 文件，返回需要保存的内容和MIME类型
+func applyActiveContentPolicy(cfg *UploadConfigDB, kind string, data []byte) ([]byte, string, error) {
+	mimeType := "text/html"
+	if kind == activeContentSVG {
+		mimeType = "image/svg+xml"
+	}
+
+	switch cfg.ActiveContentPolicy {
+	case ActiveContentAllow:
+		return data, mimeType, nil
+	case ActiveContentSanitize:
+		if kind != activeContentSVG {
+			return nil, "", errors.New("不允许上传 HTML 文件")
+		}
+		sanitized, err := imageutil.SanitizeSVG(data)
+		if err != nil {
+			return nil, "", err
+		}
+		return sanitized, mimeType, nil
+	default:
+		return nil, "", errors.New("不允许上传 SVG/HTML 文件")
+	}
+}
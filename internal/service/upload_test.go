@@ -0,0 +1,295 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goboot/config"
+	"goboot/internal/testutil"
+)
+
+func TestValidateCategory(t *testing.T) {
+	tests := []struct {
+		category string
+		wantErr  error
+	}{
+		{"files", nil},
+		{"images", nil},
+		{"user_docs-2024", nil},
+		{"", errInvalidCategory},
+		{"..", errInvalidCategory},
+		{"../../x", errInvalidCategory},
+		{"a/b", errInvalidCategory},
+		{`a\b`, errInvalidCategory},
+		{"/etc", errInvalidCategory},
+		{"files.", errInvalidCategory},
+		{"文件", errInvalidCategory},
+		{"public", errPublicCategory},
+		{"Public", errPublicCategory},
+		{"public/avatars", errInvalidCategory},
+	}
+	for _, tt := range tests {
+		if err := validateCategory(tt.category); !errors.Is(err, tt.wantErr) {
+			t.Errorf("validateCategory(%q) = %v, want %v", tt.category, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUploadRejectsInvalidCategory(t *testing.T) {
+	setupTestEnv(t)
+	root := t.TempDir()
+	storageDir := filepath.Join(root, "uploads")
+	s := NewUploadServiceWithStorage(NewLocalStorage(storageDir, "/uploads"))
+	files := multipartFiles(t, testFile{"a.png", "\x89PNG\r\n\x1a\n0000"})
+
+	for _, category := range []string{"../escape", "public", "public/x", "/abs"} {
+		if _, err := s.UploadFile(1, files[0], category); err == nil {
+			t.Errorf("UploadFile category=%q 应失败", category)
+		}
+		if _, err := s.UploadImage(1, files[0], category); err == nil {
+			t.Errorf("UploadImage category=%q 应失败", category)
+		}
+	}
+
+	// 存储目录之外不应写入任何文件
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "uploads" {
+			t.Errorf("存储目录之外出现文件: %s", e.Name())
+		}
+	}
+
+	// 头像仍写入公开目录
+	info, err := s.UploadAvatar(1, files[0])
+	if err != nil {
+		t.Fatalf("UploadAvatar: %v", err)
+	}
+	if !isPublicPath(info.Path) {
+		t.Errorf("头像应保存在公开目录，实际: %s", info.Path)
+	}
+}
+
+func TestGetFileInfoAuthorization(t *testing.T) {
+	setupTestEnv(t)
+	s := NewUploadServiceWithStorage(NewLocalStorage(t.TempDir(), "/uploads"))
+	files := multipartFiles(t, testFile{"report.pdf", "%PDF-1.4 private"})
+
+	const owner, other = 101, 102
+	uploaded, err := s.UploadFile(owner, files[0], "files")
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		userID  uint
+		isAdmin bool
+		wantErr error
+	}{
+		{"owner", owner, false, nil},
+		{"other user", other, false, ErrFileForbidden},
+		{"admin", other, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := s.GetFileInfo(tt.userID, tt.isAdmin, uploaded.Path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetFileInfo err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && info.Size != int64(len("%PDF-1.4 private")) {
+				t.Errorf("文件大小 = %d", info.Size)
+			}
+		})
+	}
+
+	if info, _ := s.GetFileInfo(owner, false, uploaded.Path); info.Name != "report.pdf" {
+		t.Errorf("上传者应看到原始文件名，实际: %s", info.Name)
+	}
+	if _, err := s.GetFileInfo(owner, false, "../"+uploaded.Path); !errors.Is(err, errInvalidPath) {
+		t.Errorf("路径穿越应返回 errInvalidPath，实际: %v", err)
+	}
+}
+
+func TestUploadFilesDuplicateNames(t *testing.T) {
+	setupTestEnv(t)
+	s := NewUploadServiceWithStorage(NewLocalStorage(t.TempDir(), "/uploads"))
+	const svg = `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`
+	files := multipartFiles(t,
+		testFile{"a.png", "\x89PNG\r\n\x1a\n0001"},
+		testFile{"a.png", svg},
+		testFile{"a.png", "\x89PNG\r\n\x1a\n0002"},
+		testFile{"a.png", svg},
+	)
+
+	results, errs := s.UploadFiles(1, files, "files")
+	if len(results) != 2 {
+		t.Fatalf("成功数量 = %d, want 2", len(results))
+	}
+	if len(errs) != 2 {
+		t.Fatalf("失败数量 = %d, want 2: %+v", len(errs), errs)
+	}
+	for i, want := range []int{1, 3} {
+		if errs[i].Index != want || errs[i].Filename != "a.png" || errs[i].Error == "" {
+			t.Errorf("errs[%d] = %+v, want index %d", i, errs[i], want)
+		}
+	}
+}
+
+// slowStorage 每次写入增加固定延迟，模拟对象存储等远程后端的网络耗时
+type slowStorage struct {
+	Storage
+	delay time.Duration
+}
+
+func (s slowStorage) Upload(file *multipart.FileHeader, path string, filename string) (*FileInfo, error) {
+	time.Sleep(s.delay)
+	return s.Storage.Upload(file, path, filename)
+}
+
+func (s slowStorage) UploadFromReader(reader io.Reader, size int64, path string, filename string, mimeType string) (*FileInfo, error) {
+	time.Sleep(s.delay)
+	return s.Storage.UploadFromReader(reader, size, path, filename, mimeType)
+}
+
+// BenchmarkUploadFiles 比较批量上传 50 个文件时顺序上传(并发数 1)与工作池并发上传的耗时
+func BenchmarkUploadFiles(b *testing.B) {
+	setupTestEnv(b)
+	s := NewUploadServiceWithStorage(slowStorage{NewLocalStorage(b.TempDir(), "/uploads"), 5 * time.Millisecond})
+
+	files := make([]testFile, 50)
+	for i := range files {
+		files[i] = testFile{fmt.Sprintf("doc-%d.pdf", i), fmt.Sprintf("%%PDF-1.4 file %d", i)}
+	}
+	headers := multipartFiles(b, files...)
+
+	defer GetConfigService().SetInt("upload_concurrency", defaultUploadConcurrency)
+	for _, bc := range []struct {
+		name        string
+		concurrency int
+	}{
+		{"sequential", 1},
+		{"pool-4", 4},
+		{"pool-16", 16},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			if err := GetConfigService().SetInt("upload_concurrency", bc.concurrency); err != nil {
+				b.Fatal(err)
+			}
+			for b.Loop() {
+				if _, errs := s.UploadFiles(1, headers, "files"); len(errs) > 0 {
+					b.Fatalf("上传失败: %+v", errs[0])
+				}
+			}
+		})
+	}
+}
+
+func TestLocalStoragePresignKey(t *testing.T) {
+	setupTestEnv(t)
+	const path = "files/a.txt"
+	expires := time.Now().Add(time.Minute).Unix()
+
+	// 未配置 sign_secret 时使用派生密钥，JWT 密钥本身不能伪造签名
+	storage := NewLocalStorage(t.TempDir(), "/uploads")
+	if storage.signSecret == "" || storage.signSecret == config.Get().JWT.Secret {
+		t.Fatalf("未配置 sign_secret 时应派生独立密钥，实际: %q", storage.signSecret)
+	}
+	sign, err := storage.sign(path, expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !storage.VerifyPresign(path, expires, sign) {
+		t.Error("派生密钥生成的签名应校验通过")
+	}
+	forged := &LocalStorage{signSecret: config.Get().JWT.Secret}
+	forgedSign, err := forged.sign(path, expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if storage.VerifyPresign(path, expires, forgedSign) {
+		t.Error("使用 jwt.secret 生成的签名不应校验通过")
+	}
+
+	// 配置 sign_secret 时直接使用
+	testutil.SetConfig(t, func(cfg *config.Config) {
+		cfg.Upload.SignSecret = "upload-sign-secret"
+	})
+	if storage := NewLocalStorage(t.TempDir(), "/uploads"); storage.signSecret != "upload-sign-secret" {
+		t.Errorf("应使用 upload.sign_secret，实际: %q", storage.signSecret)
+	}
+
+	// 使用 RS256 未配置 jwt.secret 和 sign_secret 时无法生成签名链接
+	testutil.SetConfig(t, func(cfg *config.Config) {
+		cfg.Upload.SignSecret = ""
+		cfg.JWT.Secret = ""
+	})
+	if _, err := NewLocalStorage(t.TempDir(), "/uploads").PresignURL(path, time.Minute); err == nil {
+		t.Error("未配置签名密钥时生成签名链接应失败")
+	}
+}
+
+func TestDeleteFileWithoutRecord(t *testing.T) {
+	setupTestEnv(t)
+	root := t.TempDir()
+	s := NewUploadServiceWithStorage(NewLocalStorage(root, "/uploads"))
+
+	// 没有上传记录的文件
+	write := func(path string) {
+		t.Helper()
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("legacy"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exists := func(path string) bool {
+		_, err := os.Stat(filepath.Join(root, path))
+		return err == nil
+	}
+
+	const private, public = "files/legacy.pdf", "public/avatars/legacy.png"
+	write(private)
+	write(public)
+
+	if err := s.DeleteFile(201, false, private); !errors.Is(err, ErrFileForbidden) {
+		t.Errorf("普通用户删除无记录的文件应返回 ErrFileForbidden，实际: %v", err)
+	}
+	if !exists(private) {
+		t.Fatal("无权删除时文件不应被删除")
+	}
+	if err := s.DeleteFile(201, true, private); err != nil {
+		t.Errorf("管理员删除无记录的文件: %v", err)
+	}
+	if exists(private) {
+		t.Error("管理员删除后文件应不存在")
+	}
+	if err := s.DeleteFile(201, false, public); err != nil {
+		t.Errorf("删除公开目录中无记录的文件: %v", err)
+	}
+	if exists(public) {
+		t.Error("公开目录的文件删除后应不存在")
+	}
+
+	// 有其他用户的上传记录时只能由上传者删除
+	uploaded, err := s.UploadFile(202, multipartFiles(t, testFile{"owned.pdf", "%PDF-1.4 owned"})[0], "files")
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if err := s.DeleteFile(201, true, uploaded.Path); !errors.Is(err, ErrFileForbidden) {
+		t.Errorf("删除他人上传的文件应返回 ErrFileForbidden，实际: %v", err)
+	}
+	if err := s.DeleteFile(202, false, uploaded.Path); err != nil {
+		t.Errorf("上传者删除文件: %v", err)
+	}
+}
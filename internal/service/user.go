@@ -7,21 +7,56 @@ import (
 	"goboot/config"
 	"goboot/internal/model"
 	"goboot/pkg/database"
+	"goboot/pkg/logger"
+	"goboot/pkg/response"
 	"goboot/pkg/utils"
+	"log/slog"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type UserService struct{}
 
+// 用户相关的业务错误，前端可根据错误码区分处理
+var (
+	ErrUserExists           = response.NewBizError(response.USER_EXISTS, "用户名已存在")
+	ErrInvalidCredentials   = response.NewBizError(response.INVALID_CREDENTIALS, "密码错误")
+	ErrUserNotFound         = response.NewBizError(response.USER_NOT_FOUND, "用户不存在")
+	ErrUserDisabled         = response.NewBizError(response.USER_DISABLED, "账号已被禁用")
+	ErrEmailNotVerified     = response.NewBizError(response.EMAIL_NOT_VERIFIED, "邮箱未验证，请先点击验证邮件中的链接完成验证")
+	ErrPhoneTaken           = response.NewBizError(response.PHONE_TAKEN, "手机号已被占用")
+	ErrEmailTaken           = response.NewBizError(response.EMAIL_TAKEN, "邮箱已被占用")
+	ErrOldPasswordIncorrect = response.NewBizError(response.OLD_PASSWORD_INCORRECT, "原密码错误")
+	ErrPasswordReused       = response.NewBizError(response.PASSWORD_REUSED, "新密码不能与最近使用过的密码相同")
+	ErrAccountAmbiguous     = response.NewBizError(response.ACCOUNT_AMBIGUOUS, "账号不唯一，请使用用户名登录")
+	ErrRefreshTokenInvalid  = response.NewBizError(response.REFRESH_TOKEN_INVALID, "token已失效，请重新登录")
+	ErrEmailRequired        = response.NewBizError(response.EMAIL_REQUIRED, "请填写邮箱以完成验证")
+	ErrAdminUndeletable     = response.NewBizError(response.ADMIN_UNDELETABLE, "不能删除管理员账号")
+	ErrSessionNotFound      = response.NewBizError(response.SESSION_NOT_FOUND, "会话不存在或已失效")
+)
+
 func NewUserService() *UserService {
 	return &UserService{}
 }
 
+// Register 用户注册，启用邮件服务且开启 email_verify_required 时，
+// 新用户处于待验证状态，需点击验证邮件中的链接激活后才能登录
 func (s *UserService) Register(username, password, nickname, phone, email string) (*model.User, error) {
+	email = model.NormalizeEmail(email)
+	emailCfg := GetConfigService().GetEmailConfig()
+	verifyRequired := emailCfg.Enabled && emailCfg.VerifyRequired
+	if verifyRequired && email == "" {
+		return nil, ErrEmailRequired
+	}
+
 	var count int64
 	database.DB.Model(&model.User{}).Where("username = ?", username).Count(&count)
 	if count > 0 {
-		return nil, errors.New("用户名已存在")
+		return nil, ErrUserExists
+	}
+	if err := checkContactUnique(database.DB, phone, email, 0); err != nil {
+		return nil, err
 	}
 
 	hashedPassword, err := utils.HashPassword(password)
@@ -35,29 +70,84 @@ func (s *UserService) Register(username, password, nickname, phone, email string
 		Nickname: nickname,
 		Phone:    phone,
 		Email:    email,
-		Status:   1,
+		Status:   model.UserStatusActive,
 		Role:     0,
 	}
+	if verifyRequired {
+		user.Status = model.UserStatusPending
+	}
 
 	if err := database.DB.Create(user).Error; err != nil {
 		return nil, errors.New("注册失败")
 	}
 
+	// 未强制验证时也发送验证邮件，方便用户确认邮箱，发送失败不影响注册
+	if emailCfg.Enabled && email != "" {
+		if err := NewEmailService().SendVerificationEmail(email, username, user.ID); err != nil {
+			logger.Error("发送邮箱验证邮件失败", slog.Uint64("userID", uint64(user.ID)), slog.Any("error", err))
+		}
+	}
+
+	DispatchWebhook(model.ActionRegister, newWebhookUser(user))
 	return user, nil
 }
 
-func (s *UserService) Login(username, password string) (*utils.TokenPair, *model.User, error) {
+// VerifyEmail 标记用户邮箱已验证，待验证的用户同时激活
+func (s *UserService) VerifyEmail(userID uint) (*model.User, error) {
 	var user model.User
-	if err := database.DB.Where("username = ?", username).First(&user).Error; err != nil {
-		return nil, nil, errors.New("用户不存在")
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"email_verified_at": now}
+	if user.Status == model.UserStatusPending {
+		updates["status"] = model.UserStatusActive
+	}
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		return nil, errors.New("邮箱验证失败")
+	}
+	invalidateUserCache(user.ID)
+
+	return &user, nil
+}
+
+// Login 用户登录，account 可以是用户名、邮箱或手机号，ip/userAgent 用于记录登录会话
+// ctx 通常为请求上下文，数据库查询在请求结束或超过 database.query_timeout 时取消
+func (s *UserService) Login(ctx context.Context, account, password, ip, userAgent string) (*utils.TokenPair, *model.User, error) {
+	user, err := s.findByAccount(ctx, account)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if user.Status == 0 {
-		return nil, nil, errors.New("账号已被禁用")
+		return nil, nil, ErrUserDisabled
+	}
+
+	// 连续登录失败次数过多时锁定账号，锁定期间不再校验密码
+	if err := checkLoginLock(user.ID); err != nil {
+		return nil, nil, err
 	}
 
 	if !utils.CheckPassword(password, user.Password) {
-		return nil, nil, errors.New("密码错误")
+		if err := recordLoginFailure(user.ID); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrInvalidCredentials
+	}
+	clearLoginFailures(user.ID)
+
+	// 哈希算法或参数与当前配置不一致时在后台用明文重新生成，不增加登录耗时，失败不影响登录
+	if utils.NeedsRehash(user.Password) {
+		go rehashPassword(user.ID, user.Password, password)
+	}
+
+	if user.Status == model.UserStatusPending {
+		return nil, nil, ErrEmailNotVerified
+	}
+
+	if isPasswordExpired(user) {
+		return nil, user, newPasswordExpiredError(user.ID)
 	}
 
 	tokenPair, err := utils.GenerateTokenPair(user.ID, user.Username, user.Role)
@@ -65,35 +155,110 @@ func (s *UserService) Login(username, password string) (*utils.TokenPair, *model
 		return nil, nil, errors.New("生成token失败")
 	}
 
-	return tokenPair, &user, nil
+	if err := s.createSession(user.ID, tokenPair.SessionID, ip, userAgent); err != nil {
+		return nil, nil, errors.New("创建登录会话失败")
+	}
+
+	// 记录最后登录信息，失败不影响登录
+	now := time.Now()
+	db, cancel := database.WithTimeout(ctx)
+	defer cancel()
+	if err := db.Model(user).Updates(map[string]interface{}{
+		"last_login_at": now,
+		"last_login_ip": ip,
+	}).Error; err != nil {
+		logger.Warn("更新最后登录信息失败", slog.Uint64("userID", uint64(user.ID)), slog.Any("error", err))
+	} else {
+		user.LastLoginAt = &now
+		user.LastLoginIP = ip
+		invalidateUserCache(user.ID)
+	}
+
+	return tokenPair, user, nil
+}
+
+// rehashPassword 按当前配置重新生成密码哈希，只替换哈希，不视为修改密码
+// 仅在数据库中仍是 oldHash 时更新，避免覆盖期间修改的新密码；并发登录时只有第一次更新生效
+func rehashPassword(userID uint, oldHash, password string) {
+	hashedPassword, err := utils.HashPassword(password)
+	if err == nil {
+		err = database.DB.Model(&model.User{}).
+			Where("id = ? AND password = ?", userID, oldHash).
+			Update("password", hashedPassword).Error
+	}
+	if err != nil {
+		logger.Warn("升级密码哈希失败", slog.Uint64("userID", uint64(userID)), slog.Any("error", err))
+	}
+}
+
+// findByAccount 根据用户名、邮箱或手机号查找用户
+// 优先精确匹配用户名，避免某用户的邮箱恰好等于另一用户的用户名时登录到错误账号；
+// 邮箱或手机号匹配到多个用户时视为不唯一，要求使用用户名登录
+func (s *UserService) findByAccount(ctx context.Context, account string) (*model.User, error) {
+	db, cancel := database.WithTimeout(ctx)
+	defer cancel()
+
+	var users []model.User
+	email := model.NormalizeEmail(account)
+	if err := db.Where("username = ? OR email = ? OR phone = ?", account, email, account).Find(&users).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var byEmail, byPhone []*model.User
+	for i := range users {
+		switch {
+		case users[i].Username == account:
+			return &users[i], nil
+		case users[i].Email == email:
+			byEmail = append(byEmail, &users[i])
+		case users[i].Phone == account:
+			byPhone = append(byPhone, &users[i])
+		}
+	}
+
+	for _, matched := range [][]*model.User{byEmail, byPhone} {
+		if len(matched) == 1 {
+			return matched[0], nil
+		}
+		if len(matched) > 1 {
+			return nil, ErrAccountAmbiguous
+		}
+	}
+	return nil, ErrUserNotFound
 }
 
 func (s *UserService) RefreshToken(refreshToken string) (*utils.TokenPair, error) {
-	// 检查refresh token是否在黑名单
-	if s.IsTokenBlacklisted(refreshToken) {
-		return nil, errors.New("token已失效，请重新登录")
+	claims, err := utils.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	// 检查refresh token及其会话是否已失效
+	if s.IsTokenBlacklisted(claims.ID) || s.IsSessionRevoked(claims.SessionID) {
+		return nil, ErrRefreshTokenInvalid
 	}
 
 	tokenPair, err := utils.RefreshAccessToken(refreshToken)
 	if err != nil {
-		return nil, errors.New("刷新token失败，请重新登录")
+		return nil, ErrRefreshTokenInvalid
 	}
 
 	return tokenPair, nil
 }
 
-func (s *UserService) GetUserByID(id uint) (*model.User, error) {
-	var user model.User
-	if err := database.DB.First(&user, id).Error; err != nil {
-		return nil, errors.New("用户不存在")
+// GetUserByID 根据ID获取用户，结果会缓存 redis.user_cache_ttl 秒，返回的用户不含密码
+func (s *UserService) GetUserByID(ctx context.Context, id uint) (*model.User, error) {
+	user, err := loadUser(ctx, id)
+	if err != nil {
+		return nil, ErrUserNotFound
 	}
-	return &user, nil
+	return user, nil
 }
 
 func (s *UserService) GetUserByEmail(email string) (*model.User, error) {
 	var user model.User
-	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, errors.New("用户不存在")
+	if err := database.DB.Where("email = ?", model.NormalizeEmail(email)).First(&user).Error; err != nil {
+		return nil, ErrUserNotFound
 	}
 	return &user, nil
 }
@@ -101,7 +266,12 @@ func (s *UserService) GetUserByEmail(email string) (*model.User, error) {
 func (s *UserService) UpdateProfile(id uint, nickname, phone, email, avatar string) (*model.User, error) {
 	var user model.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, ErrUserNotFound
+	}
+
+	email = model.NormalizeEmail(email)
+	if err := checkContactUnique(database.DB, phone, email, user.ID); err != nil {
+		return nil, err
 	}
 
 	updates := map[string]interface{}{}
@@ -122,6 +292,7 @@ func (s *UserService) UpdateProfile(id uint, nickname, phone, email, avatar stri
 		if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
 			return nil, errors.New("更新失败")
 		}
+		invalidateUserCache(user.ID)
 	}
 
 	return &user, nil
@@ -130,11 +301,16 @@ func (s *UserService) UpdateProfile(id uint, nickname, phone, email, avatar stri
 func (s *UserService) ChangePassword(id uint, oldPassword, newPassword string) error {
 	var user model.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		return errors.New("用户不存在")
+		return ErrUserNotFound
 	}
 
 	if !utils.CheckPassword(oldPassword, user.Password) {
-		return errors.New("原密码错误")
+		return ErrOldPasswordIncorrect
+	}
+
+	historyCount := GetConfigService().GetInt("security_password_history", 5)
+	if err := checkPasswordReuse(&user, newPassword, historyCount); err != nil {
+		return err
 	}
 
 	hashedPassword, err := utils.HashPassword(newPassword)
@@ -142,53 +318,126 @@ func (s *UserService) ChangePassword(id uint, oldPassword, newPassword string) e
 		return errors.New("密码加密失败")
 	}
 
-	if err := database.DB.Model(&user).Update("password", hashedPassword).Error; err != nil {
+	if err := model.UpdateUserPassword(&user, hashedPassword, historyCount); err != nil {
 		return errors.New("修改密码失败")
 	}
+	invalidateUserCache(user.ID)
 
 	return nil
 }
 
-func tokenBlacklistKey(token string) string {
-	return fmt.Sprintf("token:blacklist:%s", token)
+// checkPasswordReuse 检查新密码是否与当前密码或最近 historyCount 个历史密码相同，historyCount<=0 时不检查
+func checkPasswordReuse(user *model.User, newPassword string, historyCount int) error {
+	if historyCount <= 0 {
+		return nil
+	}
+	if utils.CheckPassword(newPassword, user.Password) {
+		return ErrPasswordReused
+	}
+
+	hashes, err := model.GetRecentPasswordHashes(user.ID, historyCount)
+	if err != nil {
+		return errors.New("检查历史密码失败")
+	}
+	for _, hash := range hashes {
+		if utils.CheckPassword(newPassword, hash) {
+			return ErrPasswordReused
+		}
+	}
+	return nil
 }
 
-func (s *UserService) Logout(userID uint, accessToken, refreshToken string) error {
-	ctx := context.Background()
-	cfg := config.AppConfig.JWT
+func tokenBlacklistKey(jti string) string {
+	return fmt.Sprintf("token:blacklist:%s", jti)
+}
+
+// Logout 退出登录，将传入的令牌加入黑名单并结束当前会话。
+// Redis 不可用时只记录日志，对用户仍视为退出成功，客户端丢弃令牌后令牌在过期前仍然有效
+func (s *UserService) Logout(accessToken, refreshToken string) {
+	// 将access token加入黑名单，已过期或无效的token无需处理
+	if claims, err := utils.ParseAccessToken(accessToken); err == nil {
+		if err := s.blacklistToken(claims); err != nil {
+			logger.Error("access token加入黑名单失败", slog.Uint64("userID", uint64(claims.UserID)), slog.Any("error", err))
+		}
 
-	// 将access token加入黑名单
-	accessExpiration := time.Duration(cfg.AccessExpire) * time.Hour
-	if err := database.RDB.Set(ctx, tokenBlacklistKey(accessToken), userID, accessExpiration).Err(); err != nil {
-		return errors.New("退出登录失败")
+		// 结束当前登录会话，失败时 revokeSessions 已记录日志
+		if claims.SessionID != "" {
+			_ = s.revokeSessions(claims.UserID, claims.SessionID)
+		}
 	}
 
 	// 将refresh token加入黑名单
 	if refreshToken != "" {
-		refreshExpiration := time.Duration(cfg.RefreshExpire) * time.Hour
-		if err := database.RDB.Set(ctx, tokenBlacklistKey(refreshToken), userID, refreshExpiration).Err(); err != nil {
-			return errors.New("退出登录失败")
+		if claims, err := utils.ParseRefreshToken(refreshToken); err == nil {
+			if err := s.blacklistToken(claims); err != nil {
+				logger.Error("refresh token加入黑名单失败", slog.Uint64("userID", uint64(claims.UserID)), slog.Any("error", err))
+			}
 		}
 	}
+}
 
-	return nil
+// blacklistToken 按JTI将token加入黑名单，过期时间为token的剩余有效期
+func (s *UserService) blacklistToken(claims *utils.Claims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	return database.RDB.Set(ctx, tokenBlacklistKey(claims.ID), claims.UserID, ttl).Err()
 }
 
-func (s *UserService) IsTokenBlacklisted(token string) bool {
+// IsTokenBlacklisted 检查token(JTI)是否在黑名单中，Redis 不可用时按 redis.token_check_fail_closed 处理
+func (s *UserService) IsTokenBlacklisted(jti string) bool {
+	if jti == "" {
+		return false
+	}
 	ctx := context.Background()
-	exists, _ := database.RDB.Exists(ctx, tokenBlacklistKey(token)).Result()
+	exists, err := database.RDB.Exists(ctx, tokenBlacklistKey(jti)).Result()
+	if err != nil {
+		return tokenCheckFailed("检查token黑名单失败", err)
+	}
 	return exists > 0
 }
 
+// tokenCheckFailed 黑名单或会话撤销检查时 Redis 出错，记录日志并按配置返回检查结果：
+// 开启 redis.token_check_fail_closed 时视为已失效(拒绝访问)，否则视为有效(放行)
+func tokenCheckFailed(msg string, err error) bool {
+	failClosed := config.Get().Redis.TokenCheckFailClosed
+	logger.Warn(msg, slog.Bool("failClosed", failClosed), slog.Any("error", err))
+	return failClosed
+}
+
 // ==================== 管理员用户管理 ====================
 
 // AdminGetUserList 获取用户列表(管理员)
-func (s *UserService) AdminGetUserList(page, pageSize int, username, phone, email string, status int8) ([]model.User, int64, error) {
+// includeDeleted 为 true 时包含已软删除的用户，已删除用户的用户名按删除前的原用户名匹配
+func (s *UserService) AdminGetUserList(page, pageSize int, username, phone, email string, status int8, includeDeleted bool) ([]model.User, int64, error) {
 	var users []model.User
-	var total int64
 
 	query := database.DB.Model(&model.User{})
+	if includeDeleted {
+		query = query.Unscoped()
+		if username != "" {
+			query = query.Where("username LIKE ? OR original_username LIKE ?", "%"+username+"%", "%"+username+"%")
+			username = ""
+		}
+	}
+	query = filterUsers(query, username, phone, email, status)
+	total, err := database.Paginate(query.Order("id desc"), page, pageSize, &users)
+	if err != nil {
+		return nil, 0, errors.New("获取用户列表失败")
+	}
+
+	return users, total, nil
+}
 
+// filterUsers 按用户名、手机号、邮箱模糊匹配及状态筛选用户，status<0 表示不限状态
+func filterUsers(query *gorm.DB, username, phone, email string, status int8) *gorm.DB {
 	if username != "" {
 		query = query.Where("username LIKE ?", "%"+username+"%")
 	}
@@ -201,25 +450,31 @@ func (s *UserService) AdminGetUserList(page, pageSize int, username, phone, emai
 	if status >= 0 {
 		query = query.Where("status = ?", status)
 	}
+	return query
+}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, errors.New("获取用户列表失败")
-	}
-
-	offset := (page - 1) * pageSize
-	if err := query.Order("id desc").Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
-		return nil, 0, errors.New("获取用户列表失败")
+// AdminCreateUser 创建用户(管理员)
+func (s *UserService) AdminCreateUser(username, password, nickname, phone, email string, role int8, status int8) (*model.User, error) {
+	user, err := s.adminCreateUser(database.DB, username, password, nickname, phone, email, role, status)
+	if err != nil {
+		return nil, err
 	}
 
-	return users, total, nil
+	DispatchWebhook(model.ActionCreateUser, newWebhookUser(user))
+	return user, nil
 }
 
-// AdminCreateUser 创建用户(管理员)
-func (s *UserService) AdminCreateUser(username, password, nickname, phone, email string, role int8, status int8) (*model.User, error) {
+// adminCreateUser 使用指定的数据库连接创建用户，db 可以是事务
+func (s *UserService) adminCreateUser(db *gorm.DB, username, password, nickname, phone, email string, role int8, status int8) (*model.User, error) {
 	var count int64
-	database.DB.Model(&model.User{}).Where("username = ?", username).Count(&count)
+	db.Model(&model.User{}).Where("username = ?", username).Count(&count)
 	if count > 0 {
-		return nil, errors.New("用户名已存在")
+		return nil, ErrUserExists
+	}
+
+	email = model.NormalizeEmail(email)
+	if err := checkContactUnique(db, phone, email, 0); err != nil {
+		return nil, err
 	}
 
 	hashedPassword, err := utils.HashPassword(password)
@@ -237,7 +492,7 @@ func (s *UserService) AdminCreateUser(username, password, nickname, phone, email
 		Role:     role,
 	}
 
-	if err := database.DB.Create(user).Error; err != nil {
+	if err := db.Create(user).Error; err != nil {
 		return nil, errors.New("创建用户失败")
 	}
 
@@ -248,13 +503,18 @@ func (s *UserService) AdminCreateUser(username, password, nickname, phone, email
 func (s *UserService) AdminUpdateUser(id uint, nickname, phone, email, avatar string, role int8, status int8) (*model.User, error) {
 	var user model.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		return nil, errors.New("用户不存在")
+		return nil, ErrUserNotFound
+	}
+
+	email = model.NormalizeEmail(email)
+	if err := checkContactUnique(database.DB, phone, email, user.ID); err != nil {
+		return nil, err
 	}
 
 	updates := map[string]interface{}{
 		"nickname": nickname,
-		"phone":    phone,
-		"email":    email,
+		"phone":    model.NullableContact(phone),
+		"email":    model.NullableContact(email),
 		"avatar":   avatar,
 		"role":     role,
 		"status":   status,
@@ -263,40 +523,144 @@ func (s *UserService) AdminUpdateUser(id uint, nickname, phone, email, avatar st
 	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
 		return nil, errors.New("更新用户失败")
 	}
+	invalidateUserCache(user.ID)
 
+	DispatchWebhook(model.ActionUpdateUser, newWebhookUser(&user))
 	return &user, nil
 }
 
-// AdminDeleteUser 删除用户(管理员)
+// AdminDeleteUser 删除用户(管理员)，软删除，可通过 AdminRestoreUser 恢复
 func (s *UserService) AdminDeleteUser(id uint) error {
 	var user model.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		return errors.New("用户不存在")
+		return ErrUserNotFound
 	}
 
 	// 不允许删除管理员
 	if user.Role == 1 {
-		return errors.New("不能删除管理员账号")
+		return ErrAdminUndeletable
 	}
 
-	// 修改用户名，释放原用户名供重新注册
-	deletedUsername := fmt.Sprintf("%s_deleted_%d", user.Username, time.Now().Unix())
-	if err := database.DB.Model(&user).Update("username", deletedUsername).Error; err != nil {
-		return errors.New("删除用户失败")
+	// 删除时会改名并清空联系方式，事件数据使用删除前的信息
+	webhookData := newWebhookUser(&user)
+
+	// 用户名、手机号和邮箱有唯一索引且包含已删除记录，改名并清空手机号和邮箱以释放给其他用户使用，原值保留用于恢复
+	updates := map[string]interface{}{
+		"username":          fmt.Sprintf("deleted_%d_%d", user.ID, time.Now().Unix()),
+		"original_username": user.Username,
+		"phone":             nil,
+		"original_phone":    user.Phone,
+		"email":             nil,
+		"original_email":    user.Email,
 	}
 
-	if err := database.DB.Delete(&user).Error; err != nil {
+	err := database.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&user).Error
+	})
+	if err != nil {
 		return errors.New("删除用户失败")
 	}
+	invalidateUserCache(user.ID)
+
+	// 已删除用户的会话全部失效
+	if err := s.LogoutAll(user.ID); err != nil {
+		logger.Warn("撤销已删除用户的会话失败", slog.Uint64("userID", uint64(user.ID)), slog.Any("error", err))
+	}
+
+	DispatchWebhook(model.ActionDeleteUser, webhookData)
+	return nil
+}
+
+// AdminRestoreUser 恢复已删除的用户(管理员)，原用户名未被占用时恢复原用户名，否则保留删除时的用户名；
+// 原手机号、邮箱未被占用时同样恢复，否则置空
+func (s *UserService) AdminRestoreUser(id uint) (*model.User, error) {
+	var user model.User
+	if err := database.DB.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error; err != nil {
+		return nil, errors.New("已删除的用户不存在")
+	}
+
+	updates := map[string]interface{}{
+		"deleted_at":        nil,
+		"original_username": "",
+		"original_phone":    "",
+		"original_email":    "",
+	}
+	if user.OriginalUsername != "" {
+		var count int64
+		database.DB.Model(&model.User{}).Where("username = ?", user.OriginalUsername).Count(&count)
+		if count == 0 {
+			updates["username"] = user.OriginalUsername
+		}
+	}
+	if user.OriginalPhone != "" && !contactTaken(database.DB, "phone", user.OriginalPhone, user.ID) {
+		updates["phone"] = user.OriginalPhone
+	}
+	if user.OriginalEmail != "" && !contactTaken(database.DB, "email", user.OriginalEmail, user.ID) {
+		updates["email"] = user.OriginalEmail
+	}
+
+	if err := database.DB.Unscoped().Model(&user).Updates(updates).Error; err != nil {
+		return nil, errors.New("恢复用户失败")
+	}
+	invalidateUserCache(user.ID)
+
+	if err := database.Primary().First(&user, id).Error; err != nil {
+		return nil, errors.New("恢复用户失败")
+	}
 
+	DispatchWebhook(model.ActionRestoreUser, newWebhookUser(&user))
+	return &user, nil
+}
+
+// UpdateAvatar 更新用户头像
+func (s *UserService) UpdateAvatar(id uint, avatar string) (*model.User, error) {
+	var user model.User
+	if err := database.DB.First(&user, id).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := database.DB.Model(&user).Update("avatar", avatar).Error; err != nil {
+		return nil, errors.New("更新头像失败")
+	}
+	invalidateUserCache(user.ID)
+	return &user, nil
+}
+
+// checkContactUnique 检查手机号、邮箱是否已被其他用户占用，excludeID 为当前用户ID，创建用户时传 0
+func checkContactUnique(db *gorm.DB, phone, email string, excludeID uint) error {
+	if phone != "" && contactTaken(db, "phone", phone, excludeID) {
+		return ErrPhoneTaken
+	}
+	if email != "" && contactTaken(db, "email", email, excludeID) {
+		return ErrEmailTaken
+	}
 	return nil
 }
 
+// contactTaken 检查手机号或邮箱是否已被除 excludeID 外的用户使用
+func contactTaken(db *gorm.DB, column, value string, excludeID uint) bool {
+	var count int64
+	db.Model(&model.User{}).Where(column+" = ? AND id <> ?", value, excludeID).Count(&count)
+	return count > 0
+}
+
 // AdminResetPassword 重置用户密码(管理员)
 func (s *UserService) AdminResetPassword(id uint, newPassword string) error {
 	var user model.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		return errors.New("用户不存在")
+		return ErrUserNotFound
+	}
+
+	// 管理员重置及邮件找回密码默认不检查历史密码，由 security_password_history_admin 控制
+	configService := GetConfigService()
+	historyCount := configService.GetInt("security_password_history", 5)
+	if configService.GetBool("security_password_history_admin", false) {
+		if err := checkPasswordReuse(&user, newPassword, historyCount); err != nil {
+			return err
+		}
 	}
 
 	hashedPassword, err := utils.HashPassword(newPassword)
@@ -304,9 +668,10 @@ func (s *UserService) AdminResetPassword(id uint, newPassword string) error {
 		return errors.New("密码加密失败")
 	}
 
-	if err := database.DB.Model(&user).Update("password", hashedPassword).Error; err != nil {
+	if err := model.UpdateUserPassword(&user, hashedPassword, historyCount); err != nil {
 		return errors.New("重置密码失败")
 	}
+	invalidateUserCache(user.ID)
 
 	return nil
 }
@@ -315,12 +680,14 @@ func (s *UserService) AdminResetPassword(id uint, newPassword string) error {
 func (s *UserService) AdminUpdateUserStatus(id uint, status int8) error {
 	var user model.User
 	if err := database.DB.First(&user, id).Error; err != nil {
-		return errors.New("用户不存在")
+		return ErrUserNotFound
 	}
 
 	if err := database.DB.Model(&user).Update("status", status).Error; err != nil {
 		return errors.New("更新状态失败")
 	}
+	invalidateUserCache(user.ID)
 
+	DispatchWebhook(model.ActionUpdateStatus, newWebhookUser(&user))
 	return nil
 }
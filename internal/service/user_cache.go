@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"goboot/config"
+	"goboot/internal/model"
+	"goboot/pkg/cache"
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// defaultUserCacheTTL 未配置 redis.user_cache_ttl 时用户信息的缓存时间
+const defaultUserCacheTTL = 60 * time.Second
+
+// userCacheKey 用户信息的缓存键
+func userCacheKey(id uint) string {
+	return cache.Key("user", strconv.FormatUint(uint64(id), 10))
+}
+
+// userCacheTTL 用户信息缓存时间，由 redis.user_cache_ttl 配置，小于 0 时不缓存
+func userCacheTTL() time.Duration {
+	seconds := config.Get().Redis.UserCacheTTL
+	if seconds == 0 {
+		return defaultUserCacheTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// loadUser 按ID读取用户，优先读缓存；缓存的用户不含密码等 json:"-" 字段，需要这些字段时应直接查库
+func loadUser(ctx context.Context, id uint) (*model.User, error) {
+	find := func() (*model.User, error) {
+		db, cancel := database.WithTimeout(ctx)
+		defer cancel()
+
+		var user model.User
+		if err := db.First(&user, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, cache.ErrNotFound
+			}
+			return nil, err
+		}
+		return &user, nil
+	}
+
+	ttl := userCacheTTL()
+	if ttl < 0 {
+		return find()
+	}
+	return cache.GetOrSet(userCacheKey(id), ttl, find)
+}
+
+// invalidateUserCache 用户信息变更后删除缓存，禁用、删除等操作需要立即生效
+func invalidateUserCache(id uint) {
+	if err := cache.Delete(userCacheKey(id)); err != nil {
+		logger.Warn("删除用户缓存失败", slog.Uint64("userID", uint64(id)), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"goboot/internal/model"
+	"goboot/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// userExportBatchSize 导出时每批读取的用户数
+const userExportBatchSize = 500
+
+// userCSVHeader 用户导出的CSV表头，不包含密码
+var userCSVHeader = []string{"ID", "用户名", "昵称", "手机号", "邮箱", "角色", "状态", "邮箱验证时间", "最后登录时间", "最后登录IP", "注册时间"}
+
+// userStatusText 用户状态名称
+var userStatusText = map[int8]string{
+	model.UserStatusDisabled: "禁用",
+	model.UserStatusActive:   "正常",
+	model.UserStatusPending:  "待验证",
+}
+
+// ExportUsersCSV 将符合条件的用户(筛选条件与 AdminGetUserList 一致)以CSV格式写入 w，
+// 分批读取数据库，每批写完后刷新，文件以 UTF-8 BOM 开头，保证 Excel 正确识别中文
+func (s *UserService) ExportUsersCSV(username, phone, email string, status int8, w io.Writer) error {
+	if _, err := io.WriteString(w, "\xEF\xBB\xBF"); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(userCSVHeader); err != nil {
+		return err
+	}
+
+	var users []model.User
+	query := filterUsers(database.DB.Model(&model.User{}), username, phone, email, status)
+	err := query.FindInBatches(&users, userExportBatchSize, func(tx *gorm.DB, batch int) error {
+		for _, user := range users {
+			role := "普通用户"
+			if user.Role == 1 {
+				role = "管理员"
+			}
+			if err := writer.Write([]string{
+				strconv.FormatUint(uint64(user.ID), 10),
+				csvSafe(user.Username),
+				csvSafe(user.Nickname),
+				csvSafe(user.Phone),
+				csvSafe(user.Email),
+				role,
+				userStatusText[user.Status],
+				formatOptionalTime(user.EmailVerifiedAt),
+				formatOptionalTime(user.LastLoginAt),
+				user.LastLoginIP,
+				user.CreatedAt.Format("2006-01-02 15:04:05"),
+			}); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatOptionalTime 格式化可为空的时间，为空时返回空字符串
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
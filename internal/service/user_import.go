@@ -0,0 +1,183 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"goboot/internal/model"
+	"goboot/pkg/database"
+	"goboot/pkg/validator"
+
+	"gorm.io/gorm"
+)
+
+// maxUserImportRows 单次导入的最大用户数
+const maxUserImportRows = 1000
+
+// userImportColumns 导入CSV支持的列，首行须为表头，列顺序不限
+var userImportColumns = []string{"username", "password", "nickname", "phone", "email", "role"}
+
+// UserImportRow 导入的单行用户数据，校验规则与管理员创建用户一致
+type UserImportRow struct {
+	Username string `validate:"required,min=3,max=50" label:"用户名"`
+	Password string `validate:"required,min=6,max=20" label:"密码"`
+	Nickname string `label:"昵称"`
+	Phone    string `validate:"phone" label:"手机号"`
+	Email    string `validate:"email" label:"邮箱"`
+	Role     int8   `validate:"oneof=0 1" label:"角色"`
+}
+
+// userImportRecord 解析出的用户数据及其所在行号
+type userImportRecord struct {
+	line int
+	row  *UserImportRow
+}
+
+// UserImportRowResult 单行导入结果
+type UserImportRowResult struct {
+	Row      int    `json:"row"` // CSV中的行号(表头为第1行)
+	Username string `json:"username"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+// UserImportResult 批量导入结果
+type UserImportResult struct {
+	Total     int                   `json:"total"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Rows      []UserImportRowResult `json:"rows"`
+}
+
+// ImportUsersCSV 从CSV批量导入用户，全部行在同一事务中创建，
+// 单行失败(校验不通过、用户名重复等)只跳过该行并记录原因，不影响其他行；
+// allowAdmin 表示操作人是否可以创建管理员(role=1)
+func (s *UserService) ImportUsersCSV(r io.Reader, allowAdmin bool) (*UserImportResult, error) {
+	records, err := parseUserImportCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UserImportResult{Total: len(records), Rows: make([]UserImportRowResult, 0, len(records))}
+	err = database.Transaction(func(tx *gorm.DB) error {
+		for _, record := range records {
+			rowResult := UserImportRowResult{Row: record.line, Username: record.row.Username}
+			if err := s.importUserRow(tx, record.row, allowAdmin); err != nil {
+				rowResult.Error = err.Error()
+				result.Failed++
+			} else {
+				rowResult.Success = true
+				result.Succeeded++
+			}
+			result.Rows = append(result.Rows, rowResult)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New("导入用户失败")
+	}
+	return result, nil
+}
+
+// importUserRow 校验并创建单个用户，使用保存点隔离，失败时只回滚该行
+func (s *UserService) importUserRow(tx *gorm.DB, row *UserImportRow, allowAdmin bool) error {
+	if err := validator.Validate(row); err != nil {
+		return err
+	}
+	if row.Role == 1 && !allowAdmin {
+		return errors.New("仅超级管理员可设置管理员身份")
+	}
+
+	return tx.Transaction(func(rowTx *gorm.DB) error {
+		_, err := s.adminCreateUser(rowTx, row.Username, row.Password, row.Nickname, row.Phone, row.Email, row.Role, model.UserStatusActive)
+		return err
+	})
+}
+
+// parseUserImportCSV 解析导入文件，首行为表头，必须包含 username 和 password 列
+func parseUserImportCSV(r io.Reader) ([]userImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("导入文件为空")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析CSV失败: %w", err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\xEF\xBB\xBF")
+		}
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"username", "password"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("缺少 %s 列，表头应为: %s", required, strings.Join(userImportColumns, ","))
+		}
+	}
+
+	var records []userImportRecord
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析CSV失败: %w", err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(records) >= maxUserImportRows {
+			return nil, fmt.Errorf("单次最多导入 %d 个用户", maxUserImportRows)
+		}
+
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := &UserImportRow{
+			Username: field("username"),
+			Password: field("password"),
+			Nickname: field("nickname"),
+			Phone:    field("phone"),
+			Email:    field("email"),
+		}
+		if role := field("role"); role != "" {
+			// 无法解析的角色交给校验规则报错
+			value, err := strconv.ParseInt(role, 10, 8)
+			if err != nil {
+				value = -1
+			}
+			row.Role = int8(value)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, userImportRecord{line: line, row: row})
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("导入文件中没有用户数据")
+	}
+	return records, nil
+}
+
+// isBlankRecord 是否为空行
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
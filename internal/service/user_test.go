@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"goboot/internal/testutil"
+	"goboot/pkg/utils"
+)
+
+func TestLoginByAccount(t *testing.T) {
+	setupTestEnv(t)
+	testutil.UseMiniRedis(t)
+
+	s := NewUserService()
+	const password = "Passw0rd!login"
+	alice, err := s.Register("login_alice", password, "Alice", "13900000001", "Alice@Example.com")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	// 用户名恰好等于另一个用户的邮箱
+	bob, err := s.Register("login_bob", password, "Bob", "13900000002", "login_carol@example.com")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	carol, err := s.Register("login_carol@example.com", password, "Carol", "", "")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		account  string
+		password string
+		wantUser uint
+		wantErr  error
+	}{
+		{"username", "login_alice", password, alice.ID, nil},
+		{"email", "alice@example.com", password, alice.ID, nil},
+		{"email case insensitive", "ALICE@example.COM", password, alice.ID, nil},
+		{"phone", "13900000001", password, alice.ID, nil},
+		{"other user phone", "13900000002", password, bob.ID, nil},
+		{"username preferred over email", "login_carol@example.com", password, carol.ID, nil},
+		{"wrong password", "alice@example.com", "wrong", 0, ErrInvalidCredentials},
+		{"unknown account", "nobody@example.com", password, 0, ErrUserNotFound},
+		{"username case sensitive", "LOGIN_ALICE", password, 0, ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, user, err := s.Login(context.Background(), tt.account, tt.password, "127.0.0.1", "go-test")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Login(%q) err = %v, want %v", tt.account, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if user.ID != tt.wantUser {
+				t.Errorf("Login(%q) 登录到用户 %d, want %d", tt.account, user.ID, tt.wantUser)
+			}
+			claims, err := utils.ParseAccessToken(tokens.AccessToken)
+			if err != nil {
+				t.Fatalf("ParseAccessToken: %v", err)
+			}
+			if claims.UserID != tt.wantUser || claims.SessionID == "" {
+				t.Errorf("token claims = user %d session %q", claims.UserID, claims.SessionID)
+			}
+		})
+	}
+}
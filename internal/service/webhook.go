@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"goboot/config"
+	"goboot/internal/model"
+	"goboot/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// 事件通知的默认参数
+const (
+	defaultWebhookTimeout    = 5 * time.Second
+	defaultWebhookMaxRetries = 3
+	webhookRetryInterval     = time.Second
+	webhookMaxErrorLen       = 500
+)
+
+// WebhookAllEvents 订阅所有事件时使用的事件名
+const WebhookAllEvents = "*"
+
+// WebhookPayload 事件通知的请求体
+type WebhookPayload struct {
+	ID        string `json:"id"`        // 投递ID，重试时不变，接收方可据此去重
+	Event     string `json:"event"`     // 事件名，与审计日志的操作类型相同
+	Timestamp int64  `json:"timestamp"` // 事件发生时间(Unix秒)，接收方可据此拒绝过旧的请求
+	Data      any    `json:"data"`      // 事件数据
+}
+
+// WebhookUser 用户相关事件的数据
+type WebhookUser struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Role     int8   `json:"role"`
+	Status   int8   `json:"status"`
+}
+
+// newWebhookUser 从用户生成事件数据，不含密码等敏感字段
+func newWebhookUser(user *model.User) WebhookUser {
+	return WebhookUser{
+		ID:       user.ID,
+		Username: user.Username,
+		Nickname: user.Nickname,
+		Email:    user.Email,
+		Phone:    user.Phone,
+		Role:     user.Role,
+		Status:   user.Status,
+	}
+}
+
+var (
+	webhookClient = &http.Client{}
+	webhookWG     sync.WaitGroup
+)
+
+// DispatchWebhook 异步向订阅 event 的所有地址投递事件通知，未启用或无人订阅时直接返回
+// 投递失败按 webhook.max_retries 重试，结束后写入投递记录，不影响调用方的业务流程
+func DispatchWebhook(event string, data any) {
+	cfg := config.Get().Webhook
+	if !cfg.Enabled {
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, url := range webhookTargets(cfg, event) {
+		payload := WebhookPayload{
+			ID:        uuid.NewString(),
+			Event:     event,
+			Timestamp: now,
+			Data:      data,
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			logger.Error("序列化事件通知失败", slog.String("event", event), slog.Any("error", err))
+			return
+		}
+
+		webhookWG.Add(1)
+		go func() {
+			defer webhookWG.Done()
+			deliverWebhook(cfg, url, payload.ID, event, body)
+		}()
+	}
+}
+
+// webhookTargets 返回订阅 event 的地址，包括订阅所有事件的地址，同一地址只投递一次
+func webhookTargets(cfg config.WebhookConfig, event string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, key := range []string{event, WebhookAllEvents} {
+		for _, url := range cfg.Endpoints[key] {
+			if url != "" && !seen[url] {
+				seen[url] = true
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+// deliverWebhook 投递一次事件通知，失败时按指数退避重试，结束后记录投递结果
+func deliverWebhook(cfg config.WebhookConfig, url, id, event string, body []byte) {
+	timeout := defaultWebhookTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	retries := cfg.MaxRetries
+	if retries == 0 {
+		retries = defaultWebhookMaxRetries
+	}
+
+	delivery := &model.WebhookDelivery{
+		DeliveryID: id,
+		Event:      event,
+		URL:        url,
+		Payload:    string(body),
+		Status:     model.WebhookDeliveryFailed,
+	}
+	interval := webhookRetryInterval
+	for attempt := 1; ; attempt++ {
+		delivery.Attempts = attempt
+		statusCode, err := postWebhook(url, id, event, cfg.Secret, body, timeout)
+		delivery.StatusCode = statusCode
+		if err == nil {
+			delivery.Status = model.WebhookDeliverySuccess
+			delivery.Error = ""
+			break
+		}
+
+		delivery.Error = err.Error()
+		if len(delivery.Error) > webhookMaxErrorLen {
+			delivery.Error = delivery.Error[:webhookMaxErrorLen]
+		}
+		if attempt > retries {
+			logger.Warn("事件通知投递失败",
+				slog.String("event", event),
+				slog.String("url", url),
+				slog.Int("attempts", attempt),
+				slog.Any("error", err),
+			)
+			break
+		}
+		time.Sleep(interval)
+		interval *= 2
+	}
+
+	if err := model.CreateWebhookDelivery(delivery); err != nil {
+		logger.Error("记录事件通知投递结果失败", slog.String("deliveryID", id), slog.Any("error", err))
+	}
+}
+
+// postWebhook 发送一次通知请求，返回 HTTP 状态码，非 2xx 视为失败
+func postWebhook(url, id, event, secret string, body []byte, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "goboot-webhook")
+	req.Header.Set("X-Webhook-ID", id)
+	req.Header.Set("X-Webhook-Event", event)
+	if secret != "" {
+		req.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(secret, body))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 读完响应体以复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// signWebhook 计算请求体的 HMAC-SHA256 签名(十六进制)
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WaitWebhooks 等待正在投递(含重试)的事件通知完成，最多等待 timeout，用于优雅关闭
+func WaitWebhooks(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		webhookWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		logger.Warn("等待事件通知投递超时，未完成的投递将被放弃", slog.Duration("timeout", timeout))
+	}
+}
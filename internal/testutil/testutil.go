@@ -0,0 +1,84 @@
+// Package testutil 测试共用的初始化函数：内存 SQLite 数据库、配置替换和 Redis 客户端替换
+package testutil
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"goboot/config"
+	"goboot/internal/model"
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+	"goboot/pkg/utils"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var setupOnce sync.Once
+
+// Setup 初始化测试使用的配置、日志、JWT 和内存 SQLite 数据库，迁移表结构并写入默认系统配置和角色，
+// 整个测试进程只执行一次；Redis 需另外通过 UseMiniRedis 或 UseUnreachableRedis 设置
+func Setup(tb testing.TB) {
+	tb.Helper()
+	setupOnce.Do(func() {
+		config.Set(&config.Config{
+			Database: config.DatabaseConfig{Driver: "sqlite", SQLitePath: ":memory:"},
+			JWT:      config.JWTConfig{Secret: "test-secret", RefreshSecret: "test-refresh-secret", AccessExpire: 1, RefreshExpire: 1},
+		})
+		steps := []func() error{
+			func() error { return logger.InitLogger(&logger.Config{Level: "error", Console: true}) },
+			database.InitDB,
+			model.AutoMigrate,
+			model.InitDefaultConfigs,
+			model.InitDefaultRBAC,
+			utils.InitJWT,
+		}
+		for _, step := range steps {
+			if err := step(); err != nil {
+				panic(err)
+			}
+		}
+	})
+}
+
+// SetConfig 复制当前配置，按 update 修改后替换，测试结束时恢复原配置；
+// 复制为浅拷贝，update 中不应修改切片等引用类型的元素
+func SetConfig(tb testing.TB, update func(cfg *config.Config)) {
+	tb.Helper()
+	prev := config.Get()
+	cfg := *prev
+	update(&cfg)
+	config.Set(&cfg)
+	tb.Cleanup(func() { config.Set(prev) })
+}
+
+// UseRedis 将 database.RDB 替换为 client，测试结束时关闭并恢复原客户端
+func UseRedis(tb testing.TB, client redis.UniversalClient) {
+	tb.Helper()
+	prev := database.RDB
+	database.RDB = client
+	tb.Cleanup(func() {
+		_ = client.Close()
+		database.RDB = prev
+	})
+}
+
+// UseMiniRedis 启动内存中的 miniredis 并将 database.RDB 指向它
+func UseMiniRedis(tb testing.TB) *miniredis.Miniredis {
+	tb.Helper()
+	mr := miniredis.RunT(tb)
+	UseRedis(tb, redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	return mr
+}
+
+// UseUnreachableRedis 将 database.RDB 指向一个无法连接的地址，用于测试 Redis 不可用时的降级行为
+func UseUnreachableRedis(tb testing.TB) {
+	tb.Helper()
+	UseRedis(tb, redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 100 * time.Millisecond,
+		MaxRetries:  -1,
+	}))
+}
@@ -1,37 +1,67 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"goboot/config"
 	"goboot/internal/model"
 	"goboot/internal/service"
 	"goboot/pkg/database"
 	"goboot/pkg/logger"
+	"goboot/pkg/metrics"
+	"goboot/pkg/response"
+	"goboot/pkg/utils"
+	"goboot/pkg/version"
 	"goboot/router"
 	"log"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 )
 
+// @title Goboot API
+// @version 1.0
+// @description Goboot 后端接口文档。响应统一为 {code, message, data}，code=0 表示成功。
+// @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description 格式: Bearer {accessToken}
+// @securityDefinitions.apikey APIKeyAuth
+// @in header
+// @name X-API-Key
+// @description API Key，适用于服务账号调用
 func main() {
+	configFile := flag.String("config", "", "config file path (yaml/json/toml), defaults to $CONFIG_FILE or ./config.yaml")
+	flag.Parse()
+
 	// Load config
-	if err := config.InitConfig(); err != nil {
+	if err := config.InitConfig(*configFile); err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	cfg := config.Get()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	response.SetRequestIDInResponse(cfg.Server.RequestIDInResponse)
 
 	// Initialize logger
 	logCfg := &logger.Config{
-		Level:      config.AppConfig.Log.Level,
-		Filename:   config.AppConfig.Log.Filename,
-		MaxSize:    config.AppConfig.Log.MaxSize,
-		MaxBackups: config.AppConfig.Log.MaxBackups,
-		MaxAge:     config.AppConfig.Log.MaxAge,
-		Compress:   config.AppConfig.Log.Compress,
-		Console:    config.AppConfig.Log.Console,
+		Level:      cfg.Log.Level,
+		Filename:   cfg.Log.Filename,
+		MaxSize:    cfg.Log.MaxSize,
+		MaxBackups: cfg.Log.MaxBackups,
+		MaxAge:     cfg.Log.MaxAge,
+		Compress:   cfg.Log.Compress,
+		Console:    cfg.Log.Console,
+		Format:     cfg.Log.Format,
+		Color:      cfg.Log.Color,
+		AddSource:  cfg.Log.AddSource,
+		TimeFormat: cfg.Log.TimeFormat,
 	}
 	if err := logger.InitLogger(logCfg); err != nil {
 		log.Fatalf("Failed to init logger: %v", err)
@@ -39,19 +69,33 @@ func main() {
 
 	logger.Info("Config loaded successfully")
 
-	// Initialize MySQL
-	if err := database.InitMySQL(); err != nil {
-		logger.Error("Failed to connect to MySQL", slog.Any("error", err))
+	// Initialize JWT signing keys
+	if err := utils.InitJWT(); err != nil {
+		logger.Error("Failed to init JWT", slog.Any("error", err))
+		return
+	}
+
+	// Initialize database (MySQL or SQLite)
+	if err := database.InitDB(); err != nil {
+		logger.Error("Failed to connect to database", slog.String("driver", database.Driver()), slog.Any("error", err))
 		return
 	}
-	logger.Info("MySQL connected successfully")
+	logger.Info("Database connected successfully", slog.String("driver", database.Driver()))
 
 	// Initialize Redis
 	if err := database.InitRedis(); err != nil {
-		logger.Error("Failed to connect to Redis", slog.Any("error", err))
+		logger.Error("Failed to connect to Redis", slog.String("mode", database.RedisMode()), slog.Any("error", err))
 		return
 	}
-	logger.Info("Redis connected successfully")
+	logger.Info("Redis connected successfully", slog.String("mode", database.RedisMode()))
+
+	// Register Prometheus metrics
+	if cfg.Metrics.Enabled {
+		if err := metrics.Init(); err != nil {
+			logger.Error("Failed to init metrics", slog.Any("error", err))
+			return
+		}
+	}
 
 	// Auto migrate database tables
 	if err := model.AutoMigrate(); err != nil {
@@ -65,11 +109,16 @@ func main() {
 		logger.Error("Failed to init default configs", slog.Any("error", err))
 	}
 
+	// Initialize default roles and permissions
+	if err := model.InitDefaultRBAC(); err != nil {
+		logger.Error("Failed to init default roles", slog.Any("error", err))
+	}
+
 	// Load system configs to cache
 	service.GetConfigService()
 
 	// Create Fiber app
-	app := fiber.New()
+	app := fiber.New(newFiberConfig())
 
 	// Setup router
 	router.SetupRouter(app)
@@ -80,10 +129,14 @@ func main() {
 	cronSvc.Start()
 
 	// Start server in goroutine
-	addr := fmt.Sprintf("%s:%d", config.AppConfig.Server.Host, config.AppConfig.Server.Port)
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	serverErr := make(chan error, 1)
 	go func() {
-		logger.Info("Server starting", slog.String("addr", addr))
+		logger.Info("Server starting",
+			slog.String("addr", addr),
+			slog.String("version", version.Version),
+			slog.String("commit", version.GitCommit),
+		)
 		if err := app.Listen(addr); err != nil {
 			logger.Error("Failed to start server", slog.Any("error", err))
 			serverErr <- err
@@ -103,19 +156,74 @@ func main() {
 		os.Exit(1)
 	}
 
-	logger.Info("Shutting down server...")
+	shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeout) * time.Second
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = 30 * time.Second
+	}
+	deadline := time.Now().Add(shutdownTimeout)
+	logger.Info("Shutting down server...", slog.Duration("timeout", shutdownTimeout))
 
-	// Stop cron scheduler and wait for running jobs
-	cronSvc.Stop()
+	// Stop cron scheduler (no new runs), running jobs are awaited below
+	cronDone := cronSvc.Stop()
 
-	// Graceful shutdown
-	if err := app.Shutdown(); err != nil {
+	// Graceful shutdown: wait for in-flight requests until the deadline
+	clean := true
+	if err := app.ShutdownWithTimeout(time.Until(deadline)); err != nil {
+		clean = false
 		logger.Error("Server forced to shutdown", slog.Any("error", err))
 	}
 
+	// Wait for running cron jobs within the same budget
+	select {
+	case <-cronDone.Done():
+	case <-time.After(time.Until(deadline)):
+		clean = false
+		logger.Warn("Timed out waiting for running cron jobs")
+	}
+
+	// Wait for in-flight webhook deliveries
+	service.WaitWebhooks(max(time.Until(deadline), time.Second))
+
+	// Flush buffered audit logs
+	service.FlushAuditLogs(max(time.Until(deadline), time.Second))
+
+	// Close pooled SMTP connections
+	service.CloseSMTPPool()
+
+	if clean {
+		logger.Info("Server shutdown completed cleanly")
+	} else {
+		logger.Warn("Server shutdown timed out", slog.Duration("timeout", shutdownTimeout))
+	}
 	logger.Info("Server exited")
 }
 
+// newFiberConfig 生成 Fiber 配置，配置了可信代理时仅信任来自这些代理的 ProxyHeader，
+// 使 c.IP() 返回真实客户端IP，日志、审计、限流和IP访问控制均依赖该IP；未配置时始终使用连接的对端IP。
+// Fiber 取 ProxyHeader 中第一个合法IP，由 middleware.ClientIP 预先将其改写为最右侧不属于可信代理的IP，防止伪造
+func newFiberConfig() fiber.Config {
+	fiberCfg := fiber.Config{
+		ErrorHandler: response.ErrorHandler,
+	}
+
+	cfg := config.Get().Server
+	if len(cfg.TrustedProxies) == 0 {
+		return fiberCfg
+	}
+
+	proxyHeader := cfg.ProxyHeader
+	if proxyHeader == "" {
+		proxyHeader = fiber.HeaderXForwardedFor
+	}
+	fiberCfg.TrustProxy = true
+	fiberCfg.TrustProxyConfig = fiber.TrustProxyConfig{
+		Proxies: cfg.TrustedProxies,
+	}
+	fiberCfg.ProxyHeader = proxyHeader
+	fiberCfg.EnableIPValidation = true
+	return fiberCfg
+}
+
 // registerCronJobs 注册所有定时任务
 func registerCronJobs(cronSvc *service.CronService) {
 	// 示例：每分钟执行一次的健康检查任务
@@ -130,6 +238,18 @@ func registerCronJobs(cronSvc *service.CronService) {
 		// TODO: 在此添加清理过期令牌、日志等逻辑
 	})
 
+	// 按保留天数清理过期审计日志
+	auditCleanupSpec := config.Get().Audit.CleanupSpec
+	if auditCleanupSpec == "" {
+		auditCleanupSpec = "0 30 3 * * *"
+	}
+	_ = cronSvc.AddJob("audit-log-cleanup", auditCleanupSpec, service.NewAuditService().CleanupExpiredLogs)
+
+	// 开启监控指标时每5分钟统计未过期的邮件令牌
+	if config.Get().Metrics.Enabled {
+		_ = cronSvc.AddJob("email-token-stats", "0 */5 * * * *", service.UpdateEmailTokenMetrics)
+	}
+
 	// 示例：每小时执行一次的统计任务
 	_ = cronSvc.AddJob("hourly-stats", "0 0 * * * *", func() {
 		logger.Info("Hourly stats job executed")
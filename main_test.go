@@ -0,0 +1,114 @@
+package main
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"goboot/config"
+	"goboot/internal/middleware"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// newIPTestApp 按服务器配置创建返回 c.IP() 的应用，app.Test 的对端地址为 0.0.0.0
+func newIPTestApp(t *testing.T, server config.ServerConfig) *fiber.App {
+	t.Helper()
+	prev := config.Get()
+	config.Set(&config.Config{Server: server})
+	t.Cleanup(func() { config.Set(prev) })
+
+	app := fiber.New(newFiberConfig())
+	app.Use(middleware.ClientIP())
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString(c.IP())
+	})
+	return app
+}
+
+func TestClientIP(t *testing.T) {
+	const proxy = "0.0.0.0"
+	tests := []struct {
+		name    string
+		server  config.ServerConfig
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "no trusted proxies",
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			want:    proxy,
+		},
+		{
+			name:    "untrusted peer",
+			server:  config.ServerConfig{TrustedProxies: []string{"10.0.0.0/8"}},
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			want:    proxy,
+		},
+		{
+			name:    "single hop",
+			server:  config.ServerConfig{TrustedProxies: []string{proxy}},
+			headers: map[string]string{"X-Forwarded-For": "203.0.113.7"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "spoofed leftmost",
+			server:  config.ServerConfig{TrustedProxies: []string{proxy}},
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.7"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "multiple trusted hops",
+			server:  config.ServerConfig{TrustedProxies: []string{proxy, "10.0.0.0/8"}},
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.7, 10.0.0.5"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "all hops trusted",
+			server:  config.ServerConfig{TrustedProxies: []string{proxy, "10.0.0.0/8"}},
+			headers: map[string]string{"X-Forwarded-For": "10.0.0.1, 10.0.0.2"},
+			want:    "10.0.0.1",
+		},
+		{
+			name:    "invalid rightmost",
+			server:  config.ServerConfig{TrustedProxies: []string{proxy}},
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4, unknown"},
+			want:    proxy,
+		},
+		{
+			name:   "missing header",
+			server: config.ServerConfig{TrustedProxies: []string{proxy}},
+			want:   proxy,
+		},
+		{
+			name:   "x-real-ip",
+			server: config.ServerConfig{TrustedProxies: []string{proxy}, ProxyHeader: "X-Real-IP"},
+			headers: map[string]string{
+				"X-Real-IP":       "203.0.113.9",
+				"X-Forwarded-For": "1.2.3.4",
+			},
+			want: "203.0.113.9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newIPTestApp(t, tt.server)
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(body); got != tt.want {
+				t.Errorf("c.IP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
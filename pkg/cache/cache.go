@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"goboot/pkg/database"
+	"goboot/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNotFound 缓存不存在，或缓存的是空值标记；
+// GetOrSet 的 loader 返回 ErrNotFound 表示数据不存在，会缓存空值标记防止缓存穿透
+var ErrNotFound = errors.New("cache: not found")
+
+const (
+	keyPrefix = "cache:"
+
+	// nullValue 空值标记，不是合法的 JSON，不会与正常缓存值冲突
+	nullValue = "<null>"
+	// nullTTL 空值标记的最长缓存时间
+	nullTTL = time.Minute
+)
+
+// group 合并同一个键的并发回源请求，防止缓存击穿
+var group singleflight.Group
+
+// Key 拼接带命名空间的缓存键，如 Key("user", "1") 得到 cache:user:1
+func Key(namespace string, parts ...string) string {
+	return keyPrefix + namespace + ":" + strings.Join(parts, ":")
+}
+
+// Get 读取缓存并反序列化，未命中或命中空值标记时返回 ErrNotFound
+func Get[T any](key string) (T, error) {
+	var value T
+	data, err := get(key)
+	if err != nil {
+		return value, err
+	}
+	if data == nullValue {
+		return value, ErrNotFound
+	}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return value, err
+	}
+	return value, nil
+}
+
+// get 读取原始缓存值，未命中或 Redis 未初始化时返回 ErrNotFound
+func get(key string) (string, error) {
+	if database.RDB == nil {
+		return "", ErrNotFound
+	}
+	data, err := database.RDB.Get(context.Background(), key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+	return data, err
+}
+
+// Set 序列化后写入缓存，ttl<=0 表示不过期；Redis 未初始化时忽略
+func Set(key string, value any, ttl time.Duration) error {
+	if database.RDB == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return database.RDB.Set(context.Background(), key, data, ttl).Err()
+}
+
+// Delete 删除缓存，Redis 未初始化时忽略
+func Delete(keys ...string) error {
+	if database.RDB == nil || len(keys) == 0 {
+		return nil
+	}
+	return database.RDB.Del(context.Background(), keys...).Err()
+}
+
+// GetOrSet 读取缓存，未命中时调用 loader 回源并写入缓存，同一个键的并发回源只执行一次。
+// loader 返回 ErrNotFound 时缓存空值标记(最长 1 分钟)并返回 ErrNotFound；
+// Redis 读写失败只记录日志，不影响回源结果
+func GetOrSet[T any](key string, ttl time.Duration, loader func() (T, error)) (T, error) {
+	var value T
+	data, err := get(key)
+	switch {
+	case err == nil && data == nullValue:
+		return value, ErrNotFound
+	case err == nil:
+		unmarshalErr := json.Unmarshal([]byte(data), &value)
+		if unmarshalErr == nil {
+			return value, nil
+		}
+		value = *new(T)
+		logger.Warn("解析缓存失败", slog.String("key", key), slog.Any("error", unmarshalErr))
+	case !errors.Is(err, ErrNotFound):
+		logger.Warn("读取缓存失败", slog.String("key", key), slog.Any("error", err))
+	}
+
+	result, err, _ := group.Do(key, func() (interface{}, error) {
+		loaded, err := loader()
+		if errors.Is(err, ErrNotFound) {
+			setNull(key, ttl)
+			return loaded, err
+		}
+		if err != nil {
+			return loaded, err
+		}
+		if err := Set(key, loaded, ttl); err != nil {
+			logger.Warn("写入缓存失败", slog.String("key", key), slog.Any("error", err))
+		}
+		return loaded, nil
+	})
+	if err != nil {
+		return value, err
+	}
+	value, _ = result.(T)
+	return value, nil
+}
+
+// setNull 缓存空值标记，有效期不超过 nullTTL
+func setNull(key string, ttl time.Duration) {
+	if database.RDB == nil {
+		return
+	}
+	if ttl <= 0 || ttl > nullTTL {
+		ttl = nullTTL
+	}
+	if err := database.RDB.Set(context.Background(), key, nullValue, ttl).Err(); err != nil {
+		logger.Warn("写入缓存失败", slog.String("key", key), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"goboot/config"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// defaultQueryTimeout 未配置 query_timeout 时单次数据库操作的超时时间
+const defaultQueryTimeout = 5 * time.Second
+
+// QueryTimeout 单次数据库操作的超时时间，由 database.query_timeout 配置
+func QueryTimeout() time.Duration {
+	if seconds := config.Get().Database.QueryTimeout; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultQueryTimeout
+}
+
+// WithTimeout 返回绑定 ctx 和单次操作超时的 DB 会话，超时或 ctx 取消(如请求结束)后查询被中断，
+// 避免数据库卡住时请求无限堆积；ctx 的截止时间更早时以 ctx 为准，操作完成后必须调用 cancel
+//
+//	db, cancel := database.WithTimeout(c.Context())
+//	defer cancel()
+//	err := db.First(&user, id).Error
+func WithTimeout(ctx context.Context) (*gorm.DB, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout())
+	return DB.WithContext(ctx), cancel
+}
+
+// PrimaryWithTimeout 与 WithTimeout 相同，但强制走主库
+func PrimaryWithTimeout(ctx context.Context) (*gorm.DB, context.CancelFunc) {
+	db, cancel := WithTimeout(ctx)
+	return db.Clauses(dbresolver.Write), cancel
+}
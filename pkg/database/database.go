@@ -0,0 +1,43 @@
+package database
+
+import (
+	"goboot/config"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 数据库驱动
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite"
+)
+
+// Driver 当前使用的数据库驱动，未配置时为 mysql
+func Driver() string {
+	if config.Get().Database.Driver == DriverSQLite {
+		return DriverSQLite
+	}
+	return DriverMySQL
+}
+
+// InitDB 根据 database.driver 配置初始化数据库连接
+func InitDB() error {
+	if Driver() == DriverSQLite {
+		return InitSQLite()
+	}
+	return InitMySQL()
+}
+
+// gormConfig 各驱动共用的 GORM 配置
+// debug 模式下记录全部 SQL，其他模式只记录慢查询和执行错误
+func gormConfig() *gorm.Config {
+	logMode := logger.Warn
+	if config.Get().Server.Mode == "debug" {
+		logMode = logger.Info
+	}
+
+	return &gorm.Config{
+		Logger: newGormLogger(slowThreshold()).LogMode(logMode),
+	}
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"goboot/config"
+	applogger "goboot/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// defaultSlowThreshold 未配置 slow_threshold 时的慢查询阈值
+const defaultSlowThreshold = 200 * time.Millisecond
+
+// slowThreshold 读取配置的慢查询阈值
+func slowThreshold() time.Duration {
+	if ms := config.Get().Database.SlowThreshold; ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return defaultSlowThreshold
+}
+
+// gormLogger 将 GORM 日志输出到 pkg/logger，与应用的结构化日志统一
+type gormLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newGormLogger(slowThreshold time.Duration) *gormLogger {
+	return &gormLogger{
+		level:         logger.Warn,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	newLogger := *l
+	newLogger.level = level
+	return &newLogger
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		applogger.InfoContext(ctx, fmt.Sprintf(msg, args...), slog.String("caller", utils.FileWithLineNum()))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		applogger.WarnContext(ctx, fmt.Sprintf(msg, args...), slog.String("caller", utils.FileWithLineNum()))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		applogger.ErrorContext(ctx, fmt.Sprintf(msg, args...), slog.String("caller", utils.FileWithLineNum()))
+	}
+}
+
+// Trace 记录 SQL 执行情况：执行出错记录错误，超过慢查询阈值记录警告，Info 级别下记录全部 SQL
+// 记录不存在属于正常的业务分支，不作为错误记录
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	attrs := func() []any {
+		sql, rows := fc()
+		return []any{
+			slog.String("sql", sql),
+			slog.Duration("duration", elapsed),
+			slog.Int64("rows", rows),
+			slog.String("caller", utils.FileWithLineNum()),
+		}
+	}
+
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		applogger.ErrorContext(ctx, "SQL执行失败", append(attrs(), slog.Any("error", err))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		applogger.WarnContext(ctx, "慢查询", append(attrs(), slog.Duration("threshold", l.slowThreshold))...)
+	case l.level >= logger.Info:
+		applogger.InfoContext(ctx, "SQL", attrs()...)
+	}
+}
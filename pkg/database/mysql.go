@@ -8,41 +8,31 @@ import (
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
 func InitMySQL() error {
-	cfg := config.AppConfig.MySQL
-	// DSN 添加连接参数:
-	// - timeout: 连接超时时间
-	// - readTimeout: 读取超时
-	// - writeTimeout: 写入超时
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local&timeout=10s&readTimeout=30s&writeTimeout=30s",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Database,
-		cfg.Charset,
-	)
+	cfg := config.Get().MySQL
+	dsn := mysqlDSN(cfg.User, cfg.Password, cfg.Host, cfg.Port)
 
-	var logMode logger.LogLevel
-	if config.AppConfig.Server.Mode == "debug" {
-		logMode = logger.Info
-	} else {
-		logMode = logger.Silent
-	}
-
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logMode),
+	// gorm.Open 会 Ping 数据库，连接失败时按配置重试
+	retries, interval := connectBackoff(cfg.ConnectRetries, cfg.ConnectInterval)
+	err := retryConnect(DriverMySQL, retries, interval, func() error {
+		var err error
+		DB, err = gorm.Open(mysql.Open(dsn), gormConfig())
+		return err
 	})
 	if err != nil {
 		return err
 	}
 
+	if len(cfg.Replicas) > 0 {
+		if err := registerReplicas(cfg); err != nil {
+			return err
+		}
+	}
+
 	sqlDB, err := DB.DB()
 	if err != nil {
 		return err
@@ -62,3 +52,20 @@ func InitMySQL() error {
 
 	return nil
 }
+
+// mysqlDSN 生成 MySQL 连接串，数据库名和字符集取自主库配置
+// DSN 添加连接参数:
+// - timeout: 连接超时时间
+// - readTimeout: 读取超时
+// - writeTimeout: 写入超时
+func mysqlDSN(user, password, host string, port int) string {
+	cfg := config.Get().MySQL
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local&timeout=10s&readTimeout=30s&writeTimeout=30s",
+		user,
+		password,
+		host,
+		port,
+		cfg.Database,
+		cfg.Charset,
+	)
+}
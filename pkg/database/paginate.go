@@ -0,0 +1,56 @@
+package database
+
+import (
+	"goboot/config"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageSize    = 10  // 未指定每页条数时的默认值
+	defaultMaxPageSize = 100 // 未配置 max_page_size 时每页最大条数
+)
+
+// MaxPageSize 每页最大条数，由 database.max_page_size 配置
+func MaxPageSize() int {
+	if size := config.Get().Database.MaxPageSize; size > 0 {
+		return size
+	}
+	return defaultMaxPageSize
+}
+
+// ClampPageSize 规范化每页条数：非正数时为 DefaultPageSize，超过 MaxPageSize 时截断为最大值
+func ClampPageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return DefaultPageSize
+	}
+	if maxSize := MaxPageSize(); pageSize > maxSize {
+		return maxSize
+	}
+	return pageSize
+}
+
+// NormalizePage 规范化分页参数，page 非正数时为 1，pageSize 按 ClampPageSize 处理，
+// 列表接口应使用返回的实际值查询并在响应中返回
+func NormalizePage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	return page, ClampPageSize(pageSize)
+}
+
+// Paginate 分页查询，统计符合条件的总数后查询第 page 页的数据写入 dest
+// db 需已设置 Model、筛选条件和排序(统计总数时 GORM 会忽略排序)；page、pageSize 按 NormalizePage 规范化
+func Paginate(db *gorm.DB, page, pageSize int, dest any) (total int64, err error) {
+	page, pageSize = NormalizePage(page, pageSize)
+
+	if err := db.Count(&total).Error; err != nil {
+		return 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := db.Offset(offset).Limit(pageSize).Find(dest).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -8,24 +8,60 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-var RDB *redis.Client
+// RDB Redis 客户端，根据配置可能是单机、哨兵或集群客户端
+var RDB redis.UniversalClient
 
 type Z = redis.Z
 
-func InitRedis() error {
-	cfg := config.AppConfig.Redis
-	RDB = redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		Password: cfg.Password,
-		DB:       cfg.DB,
-		PoolSize: cfg.PoolSize,
-	})
+// Redis 部署模式
+const (
+	RedisModeStandalone = "standalone"
+	RedisModeSentinel   = "sentinel"
+	RedisModeCluster    = "cluster"
+)
+
+// RedisMode 当前使用的 Redis 部署模式，集群优先于哨兵，均未配置时为单机
+func RedisMode() string {
+	cfg := config.Get().Redis
+	switch {
+	case len(cfg.ClusterAddrs) > 0:
+		return RedisModeCluster
+	case cfg.MasterName != "":
+		return RedisModeSentinel
+	default:
+		return RedisModeStandalone
+	}
+}
 
-	ctx := context.Background()
-	_, err := RDB.Ping(ctx).Result()
-	if err != nil {
-		return err
+func InitRedis() error {
+	cfg := config.Get().Redis
+	switch RedisMode() {
+	case RedisModeCluster:
+		RDB = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    cfg.ClusterAddrs,
+			Password: cfg.Password,
+			PoolSize: cfg.PoolSize,
+		})
+	case RedisModeSentinel:
+		RDB = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.SentinelAddrs,
+			SentinelPassword: cfg.SentinelPassword,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			PoolSize:         cfg.PoolSize,
+		})
+	default:
+		RDB = redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+			Password: cfg.Password,
+			DB:       cfg.DB,
+			PoolSize: cfg.PoolSize,
+		})
 	}
 
-	return nil
+	retries, interval := connectBackoff(cfg.ConnectRetries, cfg.ConnectInterval)
+	return retryConnect("redis", retries, interval, func() error {
+		return RDB.Ping(context.Background()).Err()
+	})
 }
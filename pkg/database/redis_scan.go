@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// scanBatchSize 每次 SCAN 建议返回的键数量
+const scanBatchSize = 500
+
+// ScanKeys 使用 SCAN 分批遍历匹配 pattern 的键，每批调用一次 fn，不会像 KEYS 一样阻塞 Redis；
+// 集群模式下并发遍历所有主节点，fn 需要并发安全。遍历期间新增或删除的键可能遗漏或重复
+func ScanKeys(ctx context.Context, pattern string, fn func(keys []string) error) error {
+	scan := func(ctx context.Context, client redis.Cmdable) error {
+		var cursor uint64
+		for {
+			keys, next, err := client.Scan(ctx, cursor, pattern, scanBatchSize).Result()
+			if err != nil {
+				return err
+			}
+			if len(keys) > 0 {
+				if err := fn(keys); err != nil {
+					return err
+				}
+			}
+			if next == 0 {
+				return nil
+			}
+			cursor = next
+		}
+	}
+
+	if cluster, ok := RDB.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return scan(ctx, client)
+		})
+	}
+	return scan(ctx, RDB)
+}
+
+// CountKeys 统计匹配 pattern 的键数量
+func CountKeys(ctx context.Context, pattern string) (int64, error) {
+	var count atomic.Int64
+	err := ScanKeys(ctx, pattern, func(keys []string) error {
+		count.Add(int64(len(keys)))
+		return nil
+	})
+	return count.Load(), err
+}
+
+// DeleteKeys 删除匹配 pattern 的键，返回删除的数量；使用 UNLINK 在后台释放内存，
+// 逐个键删除以兼容集群模式下键分布在不同槽位的情况
+func DeleteKeys(ctx context.Context, pattern string) (int64, error) {
+	var deleted atomic.Int64
+	err := ScanKeys(ctx, pattern, func(keys []string) error {
+		pipe := RDB.Pipeline()
+		cmds := make([]*redis.IntCmd, len(keys))
+		for i, key := range keys {
+			cmds[i] = pipe.Unlink(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			deleted.Add(cmd.Val())
+		}
+		return nil
+	})
+	return deleted.Load(), err
+}
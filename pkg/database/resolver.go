@@ -0,0 +1,43 @@
+package database
+
+import (
+	"time"
+
+	"goboot/config"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// registerReplicas 注册只读从库，查询(Find/First 等)走从库，写入和事务走主库
+func registerReplicas(cfg config.MySQLConfig) error {
+	replicas := make([]gorm.Dialector, 0, len(cfg.Replicas))
+	for _, r := range cfg.Replicas {
+		user, password, port := r.User, r.Password, r.Port
+		if user == "" {
+			user, password = cfg.User, cfg.Password
+		}
+		if port == 0 {
+			port = cfg.Port
+		}
+		replicas = append(replicas, mysql.Open(mysqlDSN(user, password, r.Host, port)))
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetMaxOpenConns(cfg.MaxOpenConns).
+		SetConnMaxLifetime(time.Hour * 1).
+		SetConnMaxIdleTime(time.Minute * 30)
+
+	return DB.Use(resolver)
+}
+
+// Primary 返回强制走主库的查询，用于写入后立即读取等不能容忍复制延迟的场景
+// 未配置从库时与 DB 等价
+func Primary() *gorm.DB {
+	return DB.Clauses(dbresolver.Write)
+}
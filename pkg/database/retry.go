@@ -0,0 +1,53 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	applogger "goboot/pkg/logger"
+)
+
+// 启动时连接依赖的默认重试参数
+const (
+	defaultConnectRetries  = 5
+	defaultConnectInterval = time.Second
+	maxConnectInterval     = 30 * time.Second
+)
+
+// connectBackoff 返回连接重试的最大尝试次数和首次重试间隔，未配置时使用默认值
+func connectBackoff(retries, intervalSeconds int) (int, time.Duration) {
+	if retries <= 0 {
+		retries = defaultConnectRetries
+	}
+	interval := defaultConnectInterval
+	if intervalSeconds > 0 {
+		interval = time.Duration(intervalSeconds) * time.Second
+	}
+	return retries, interval
+}
+
+// retryConnect 启动时连接依赖，失败后按指数退避重试(间隔每次翻倍，最长30秒)，
+// 用于编排环境中依赖晚于应用启动的场景；达到最大尝试次数仍失败时返回最后一次的错误
+func retryConnect(name string, retries int, interval time.Duration, connect func() error) error {
+	var err error
+	for attempt := 1; attempt <= retries; attempt++ {
+		if err = connect(); err == nil {
+			return nil
+		}
+		if attempt == retries {
+			break
+		}
+
+		applogger.Warn("连接失败，等待重试",
+			slog.String("target", name),
+			slog.Int("attempt", attempt),
+			slog.Int("max_attempts", retries),
+			slog.Duration("wait", interval),
+			slog.Any("error", err),
+		)
+		time.Sleep(interval)
+		interval = min(interval*2, maxConnectInterval)
+	}
+	return fmt.Errorf("连接 %s 失败(已尝试%d次): %w", name, retries, err)
+}
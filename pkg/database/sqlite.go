@@ -0,0 +1,55 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+
+	"goboot/config"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// defaultSQLitePath 未配置 sqlite_path 时使用的数据库文件
+const defaultSQLitePath = "data/goboot.db"
+
+// InitSQLite 初始化 SQLite 连接，用于本地开发和测试
+// sqlite_path 为 ":memory:" 时使用内存数据库，进程退出后数据丢失
+func InitSQLite() error {
+	path := config.Get().Database.SQLitePath
+	if path == "" {
+		path = defaultSQLitePath
+	}
+
+	memory := path == ":memory:"
+	var dsn string
+	if memory {
+		dsn = ":memory:"
+	} else {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		// 写锁冲突时等待而不是立即返回 database is locked
+		dsn = path + "?_busy_timeout=5000&_journal_mode=WAL"
+	}
+
+	var err error
+	DB, err = gorm.Open(sqlite.Open(dsn), gormConfig())
+	if err != nil {
+		return err
+	}
+
+	if memory {
+		sqlDB, err := DB.DB()
+		if err != nil {
+			return err
+		}
+		// 每个连接都是独立的内存数据库，只保留一个永不过期的连接
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+
+	return nil
+}
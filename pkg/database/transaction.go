@@ -0,0 +1,9 @@
+package database
+
+import "gorm.io/gorm"
+
+// Transaction 在事务中执行多步写操作，fn 返回错误或发生 panic 时回滚，否则提交
+// fn 内的所有读写都必须使用传入的 tx，事务内的查询始终走主库
+func Transaction(fn func(tx *gorm.DB) error) error {
+	return DB.Transaction(fn)
+}
@@ -0,0 +1,213 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+)
+
+// JPEG 段标记
+const (
+	markerSOI  = 0xD8
+	markerEOI  = 0xD9
+	markerSOS  = 0xDA
+	markerAPP1 = 0xE1 // EXIF、XMP
+	markerAPPD = 0xED // Photoshop IRB、IPTC
+	markerCOM  = 0xFE // 注释
+)
+
+// exifOrientationTag EXIF 中的方向标记
+const exifOrientationTag = 0x0112
+
+var errInvalidJPEG = errors.New("无效的JPEG图片")
+
+// jpegSegment JPEG 文件头中 SOS 之前的一个段
+type jpegSegment struct {
+	marker byte
+	data   []byte // 含标记和长度的完整段内容
+}
+
+// processJPEG 处理 JPEG 图片
+func processJPEG(data []byte, opts Options) ([]byte, bool, error) {
+	if !opts.StripMetadata && !opts.AutoOrient {
+		return data, false, nil
+	}
+
+	segments, body, err := splitJPEG(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if opts.AutoOrient {
+		if orientation := jpegOrientation(segments); orientation > 1 && orientation <= 8 {
+			img, err := jpeg.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil, false, err
+			}
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, orient(img, orientation), &jpeg.Options{Quality: opts.quality()}); err != nil {
+				return nil, false, err
+			}
+			return buf.Bytes(), true, nil
+		}
+	}
+
+	if !opts.StripMetadata {
+		return data, false, nil
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	buf.Write([]byte{0xFF, markerSOI})
+	stripped := false
+	for _, seg := range segments {
+		switch seg.marker {
+		case markerAPP1, markerAPPD, markerCOM:
+			stripped = true
+		default:
+			buf.Write(seg.data)
+		}
+	}
+	if !stripped {
+		return data, false, nil
+	}
+	buf.Write(body)
+	return buf.Bytes(), true, nil
+}
+
+// splitJPEG 拆分 SOS 之前的各个段，body 为从 SOS 开始的剩余内容(图像数据)
+func splitJPEG(data []byte) (segments []jpegSegment, body []byte, err error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil, nil, errInvalidJPEG
+	}
+
+	pos := 2
+	for pos < len(data) {
+		if data[pos] != 0xFF {
+			return nil, nil, errInvalidJPEG
+		}
+		// 段之间允许有填充的 0xFF
+		for pos < len(data) && data[pos] == 0xFF {
+			pos++
+		}
+		if pos >= len(data) {
+			break
+		}
+		marker := data[pos]
+		start := pos - 1
+		pos++
+
+		if marker == markerSOS || marker == markerEOI {
+			return segments, data[start:], nil
+		}
+		// RSTn 和 TEM 没有长度字段
+		if (marker >= 0xD0 && marker <= 0xD7) || marker == 0x01 {
+			segments = append(segments, jpegSegment{marker: marker, data: data[start:pos]})
+			continue
+		}
+
+		if pos+2 > len(data) {
+			return nil, nil, errInvalidJPEG
+		}
+		length := int(binary.BigEndian.Uint16(data[pos:]))
+		if length < 2 || pos+length > len(data) {
+			return nil, nil, errInvalidJPEG
+		}
+		pos += length
+		segments = append(segments, jpegSegment{marker: marker, data: data[start:pos]})
+	}
+	return nil, nil, errInvalidJPEG
+}
+
+// jpegOrientation 读取 EXIF 方向标记，没有或无法解析时返回 1(正常方向)
+func jpegOrientation(segments []jpegSegment) int {
+	for _, seg := range segments {
+		if seg.marker != markerAPP1 || len(seg.data) < 4 {
+			continue
+		}
+		payload := seg.data[4:]
+		if !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			continue
+		}
+		if orientation, ok := exifOrientation(payload[6:]); ok {
+			return orientation
+		}
+	}
+	return 1
+}
+
+// exifOrientation 从 TIFF 结构的 IFD0 中读取方向标记
+func exifOrientation(tiff []byte) (int, bool) {
+	if len(tiff) < 8 {
+		return 0, false
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0, false
+	}
+	if order.Uint16(tiff[2:]) != 0x002A {
+		return 0, false
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0, false
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0, false
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8:])), true
+		}
+	}
+	return 0, false
+}
+
+// orient 按 EXIF 方向标记(2-8)变换图片，使其以正常方向显示
+func orient(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // 水平翻转
+				sx, sy = w-1-x, y
+			case 3: // 旋转180度
+				sx, sy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				sx, sy = x, h-1-y
+			case 5: // 沿主对角线翻转
+				sx, sy = y, x
+			case 6: // 顺时针旋转90度
+				sx, sy = y, h-1-x
+			case 7: // 沿副对角线翻转
+				sx, sy = w-1-y, h-1-x
+			case 8: // 逆时针旋转90度
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,40 @@
+package imageutil
+
+import "strings"
+
+// DefaultQuality 未配置时 JPEG 重新编码的质量
+const DefaultQuality = 85
+
+// Options 图片处理选项
+type Options struct {
+	StripMetadata bool // 去除 EXIF(含GPS定位)、XMP 等元数据
+	AutoOrient    bool // 按 EXIF 方向标记旋转图片，仅 JPEG 支持
+	Quality       int  // JPEG 重新编码质量(1-100)，<=0 时使用 DefaultQuality
+}
+
+// Process 按选项处理图片，仅处理 JPEG 和 WebP，其他格式原样返回；
+// changed 为 false 表示无需处理，调用方可直接保存原文件。
+//
+// JPEG 需要旋转时解码后重新编码，元数据随之全部去除；只去除元数据时直接删除对应段，不重新编码。
+// WebP 只去除元数据(EXIF、XMP 块)，标准库没有 WebP 编码器，不做旋转。
+func Process(data []byte, ext string, opts Options) (result []byte, changed bool, err error) {
+	switch strings.ToLower(ext) {
+	case ".jpg", ".jpeg":
+		return processJPEG(data, opts)
+	case ".webp":
+		if !opts.StripMetadata {
+			return data, false, nil
+		}
+		return stripWebPMetadata(data)
+	default:
+		return data, false, nil
+	}
+}
+
+// quality 规范化 JPEG 编码质量
+func (o Options) quality() int {
+	if o.Quality <= 0 || o.Quality > 100 {
+		return DefaultQuality
+	}
+	return o.Quality
+}
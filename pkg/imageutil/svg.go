@@ -0,0 +1,138 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+var errInvalidSVG = errors.New("无效的SVG图片")
+
+// svgUnsafeElements 会执行脚本或嵌入外部文档的元素，连同子元素一起删除
+var svgUnsafeElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"handler":       true,
+	"listener":      true,
+}
+
+// SanitizeSVG 清理 SVG 中可执行脚本的内容：删除 script、foreignObject 等元素，
+// 删除 on* 事件属性和值中含 javascript: 或 data:text/html 的属性(包括 animate/set 设置的 href)，
+// 同时删除 DOCTYPE(防止实体扩展)、处理指令和注释。根元素不是 svg 或无法解析时返回错误
+func SanitizeSVG(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+
+	depth := 0     // 当前元素嵌套深度
+	skipDepth := 0 // 正在删除的元素深度，0 表示未在删除
+	hasRoot := false
+	for {
+		// RawToken 不解析命名空间，保持原始的前缀和 xmlns 声明
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errInvalidSVG
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if skipDepth > 0 {
+				continue
+			}
+			name := rawName(t.Name)
+			if depth == 1 {
+				if strings.ToLower(localName(name)) != "svg" || hasRoot {
+					return nil, errInvalidSVG
+				}
+				hasRoot = true
+			}
+			if svgUnsafeElements[strings.ToLower(localName(name))] {
+				skipDepth = depth
+				continue
+			}
+			start := xml.StartElement{Name: xml.Name{Local: name}}
+			for _, attr := range t.Attr {
+				attrName := rawName(attr.Name)
+				if isUnsafeSVGAttr(attrName, attr.Value) {
+					continue
+				}
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attrName}, Value: attr.Value})
+			}
+			if err := encoder.EncodeToken(start); err != nil {
+				return nil, errInvalidSVG
+			}
+		case xml.EndElement:
+			if skipDepth > 0 {
+				if depth == skipDepth {
+					skipDepth = 0
+				}
+				depth--
+				continue
+			}
+			depth--
+			if err := encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: rawName(t.Name)}}); err != nil {
+				return nil, errInvalidSVG
+			}
+		case xml.CharData:
+			if skipDepth > 0 || depth == 0 {
+				continue
+			}
+			if err := encoder.EncodeToken(t); err != nil {
+				return nil, errInvalidSVG
+			}
+		}
+	}
+
+	if !hasRoot {
+		return nil, errInvalidSVG
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, errInvalidSVG
+	}
+	return buf.Bytes(), nil
+}
+
+// rawName 还原带前缀的原始名称，如 xlink:href
+func rawName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// localName 去掉名称中的前缀
+func localName(name string) string {
+	if i := strings.LastIndexByte(name, ':'); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// isUnsafeSVGAttr 是否为事件属性，或值为脚本、HTML 的属性
+func isUnsafeSVGAttr(name, value string) bool {
+	if strings.HasPrefix(strings.ToLower(localName(name)), "on") {
+		return true
+	}
+	// 去掉空白和控制字符，防止 "java\tscript:" 之类的绕过
+	compact := strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(value))
+	return strings.Contains(compact, "javascript:") ||
+		strings.Contains(compact, "vbscript:") ||
+		strings.Contains(compact, "data:text/html")
+}
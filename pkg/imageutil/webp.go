@@ -0,0 +1,62 @@
+package imageutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// VP8X 扩展格式中元数据的标志位
+const (
+	vp8xFlagEXIF = 0x08
+	vp8xFlagXMP  = 0x04
+)
+
+var errInvalidWebP = errors.New("无效的WebP图片")
+
+// stripWebPMetadata 删除 WebP 中的 EXIF、XMP 块并清除 VP8X 中对应的标志位，图像数据不变
+func stripWebPMetadata(data []byte) ([]byte, bool, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, false, errInvalidWebP
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(data))
+	buf.Write(data[:12])
+	stripped := false
+
+	pos := 12
+	for pos+8 <= len(data) {
+		fourCC := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2 // 块长度为奇数时有一个填充字节
+		if end > len(data) {
+			if pos+8+size == len(data) {
+				end = len(data) // 容忍末尾缺少填充字节
+			} else {
+				return nil, false, errInvalidWebP
+			}
+		}
+
+		switch fourCC {
+		case "EXIF", "XMP ":
+			stripped = true
+		case "VP8X":
+			chunk := append([]byte(nil), data[pos:end]...)
+			if size > 0 {
+				chunk[8] &^= vp8xFlagEXIF | vp8xFlagXMP
+			}
+			buf.Write(chunk)
+		default:
+			buf.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	if !stripped {
+		return data, false, nil
+	}
+	result := buf.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+	return result, true, nil
+}
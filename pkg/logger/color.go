@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+)
+
+// ANSI 颜色
+const (
+	colorReset  = "\033[0m"
+	colorGray   = "\033[90m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+// colorWriter 按日志级别为 text 格式的每行日志着色，仅用于控制台输出
+// slog 的 Handler 每条记录调用一次 Write，因此可以按整行处理
+type colorWriter struct {
+	w io.Writer
+}
+
+func (cw *colorWriter) Write(p []byte) (int, error) {
+	color := levelColor(p)
+	if color == "" {
+		return cw.w.Write(p)
+	}
+
+	line := bytes.TrimSuffix(p, []byte("\n"))
+	buf := make([]byte, 0, len(p)+len(color)+len(colorReset))
+	buf = append(buf, color...)
+	buf = append(buf, line...)
+	buf = append(buf, colorReset...)
+	buf = append(buf, '\n')
+	if _, err := cw.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// levelColor 根据日志行中的 level 字段选择颜色，INFO 级别保持默认颜色
+// level 字段位于 msg 之前，取第一次出现的位置，避免被消息内容干扰
+func levelColor(line []byte) string {
+	i := bytes.Index(line, []byte("level="))
+	if i < 0 {
+		return ""
+	}
+	level := line[i+len("level="):]
+	switch {
+	case bytes.HasPrefix(level, []byte("ERROR")):
+		return colorRed
+	case bytes.HasPrefix(level, []byte("WARN")):
+		return colorYellow
+	case bytes.HasPrefix(level, []byte("DEBUG")):
+		return colorGray
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,35 @@
+package logger
+
+import "context"
+
+type requestIDKey struct{}
+
+type traceIDKey struct{}
+
+// WithRequestID 将请求ID写入 context，通过 *Context 系列方法输出的日志会自动带上 request_id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext 从 context 中获取请求ID
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithTraceID 将链路追踪ID写入 context，通过 *Context 系列方法输出的日志会自动带上 trace_id
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext 从 context 中获取链路追踪ID
+func TraceIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
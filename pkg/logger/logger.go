@@ -2,11 +2,13 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -14,6 +16,15 @@ import (
 
 var Log *slog.Logger
 
+// levelVar 全局日志级别，可在运行时通过 SetLevel 调整
+var levelVar = new(slog.LevelVar)
+
+// 日志格式
+const (
+	FormatJSON = "json" // JSON 格式，便于日志采集系统解析
+	FormatText = "text" // key=value 文本格式，便于本地开发阅读
+)
+
 type Config struct {
 	Level      string // debug, info, warn, error
 	Filename   string // 日志文件路径
@@ -22,6 +33,10 @@ type Config struct {
 	MaxAge     int    // 保留旧日志文件的最大天数
 	Compress   bool   // 是否压缩旧日志文件
 	Console    bool   // 是否同时输出到控制台
+	Format     string // 日志格式: json(默认), text
+	Color      bool   // text 格式下控制台输出是否按级别着色(文件输出不着色)
+	AddSource  bool   // 是否输出调用位置(source)，有一定性能开销
+	TimeFormat string // 时间格式(Go layout，如 2006-01-02 15:04:05.000)，为空时使用 RFC3339Nano
 }
 
 func InitLogger(cfg *Config) error {
@@ -34,6 +49,8 @@ func InitLogger(cfg *Config) error {
 			MaxAge:     30,
 			Compress:   true,
 			Console:    true,
+			Format:     FormatJSON,
+			AddSource:  true,
 		}
 	}
 
@@ -43,20 +60,12 @@ func InitLogger(cfg *Config) error {
 		return err
 	}
 
-	// 解析日志级别
-	var level slog.Level
-	switch cfg.Level {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
+	// 解析日志级别，无效值使用 info
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
 		level = slog.LevelInfo
 	}
+	levelVar.Set(level)
 
 	// 文件写入器
 	fileWriter := &lumberjack.Logger{
@@ -70,24 +79,77 @@ func InitLogger(cfg *Config) error {
 	// 构建writer
 	var writer io.Writer
 	if cfg.Console {
-		writer = io.MultiWriter(os.Stdout, fileWriter)
+		var console io.Writer = os.Stdout
+		if cfg.Format == FormatText && cfg.Color {
+			console = &colorWriter{w: os.Stdout}
+		}
+		writer = io.MultiWriter(console, fileWriter)
 	} else {
 		writer = fileWriter
 	}
 
 	// 创建handler
 	opts := &slog.HandlerOptions{
-		Level:     level,
-		AddSource: true,
+		Level:     levelVar,
+		AddSource: cfg.AddSource,
+	}
+	if cfg.TimeFormat != "" {
+		opts.ReplaceAttr = formatTime(cfg.TimeFormat)
 	}
 
-	handler := slog.NewJSONHandler(writer, opts)
+	var handler slog.Handler
+	if cfg.Format == FormatText {
+		handler = slog.NewTextHandler(writer, opts)
+	} else {
+		handler = slog.NewJSONHandler(writer, opts)
+	}
 	Log = slog.New(handler)
 	slog.SetDefault(Log)
 
 	return nil
 }
 
+// ParseLevel 解析日志级别字符串: debug, info, warn, error
+func ParseLevel(s string) (slog.Level, error) {
+	switch s {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("无效的日志级别: %s", s)
+	}
+}
+
+// SetLevel 运行时调整日志级别，立即对所有日志生效
+func SetLevel(s string) error {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	levelVar.Set(level)
+	return nil
+}
+
+// GetLevel 获取当前日志级别(小写)
+func GetLevel() string {
+	return strings.ToLower(levelVar.Level().String())
+}
+
+// formatTime 返回按指定格式输出顶层 time 属性的 ReplaceAttr 函数
+func formatTime(layout string) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if a.Key == slog.TimeKey && len(groups) == 0 && a.Value.Kind() == slog.KindTime {
+			return slog.String(slog.TimeKey, a.Value.Time().Format(layout))
+		}
+		return a
+	}
+}
+
 // log 内部日志方法，skip 用于指定跳过的调用栈层数
 func log(ctx context.Context, level slog.Level, skip int, msg string, args ...any) {
 	if !Log.Enabled(ctx, level) {
@@ -98,6 +160,12 @@ func log(ctx context.Context, level slog.Level, skip int, msg string, args ...an
 	runtime.Callers(skip, pcs[:])
 	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
 	r.Add(args...)
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		r.AddAttrs(slog.String("request_id", requestID))
+	}
+	if traceID := TraceIDFromContext(ctx); traceID != "" {
+		r.AddAttrs(slog.String("trace_id", traceID))
+	}
 	_ = Log.Handler().Handle(ctx, r)
 }
 
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"goboot/pkg/database"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "goboot"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	// RequestsTotal HTTP请求总数
+	RequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests.",
+	}, []string{"method", "path", "status"})
+
+	// RequestDuration HTTP请求耗时分布(秒)
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency in seconds.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "path", "status"})
+
+	// RequestsInFlight 正在处理的HTTP请求数
+	// 路由在请求处理完成前无法确定，因此只按请求方法区分
+	RequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being served.",
+	}, []string{"method"})
+
+	// EmailTokensActive 未过期的密码重置和邮箱验证令牌数，由定时任务及管理接口统计时更新
+	EmailTokensActive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "email",
+		Name:      "tokens_active",
+		Help:      "Number of outstanding email tokens by type.",
+	}, []string{"type"})
+)
+
+// Init 注册所有指标，需在 MySQL 和 Redis 初始化之后调用
+func Init() error {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RequestsTotal,
+		RequestDuration,
+		RequestsInFlight,
+		EmailTokensActive,
+	)
+
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		return err
+	}
+	registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, database.Driver()))
+	registry.MustRegister(newRedisPoolCollector())
+
+	return nil
+}
+
+// Handler 以 Prometheus 文本格式输出指标
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,47 @@
+package response
+
+import "fmt"
+
+// 业务错误码，按模块划分号段：100xx 用户
+const (
+	USER_EXISTS            = 10001 // 用户名已存在
+	INVALID_CREDENTIALS    = 10002 // 登录密码错误
+	USER_NOT_FOUND         = 10003 // 用户不存在
+	USER_DISABLED          = 10004 // 账号已被禁用
+	EMAIL_NOT_VERIFIED     = 10005 // 邮箱未验证
+	PHONE_TAKEN            = 10006 // 手机号已被占用
+	EMAIL_TAKEN            = 10007 // 邮箱已被占用
+	OLD_PASSWORD_INCORRECT = 10008 // 原密码错误
+	PASSWORD_REUSED        = 10009 // 新密码与最近使用过的密码相同
+	ACCOUNT_AMBIGUOUS      = 10010 // 邮箱或手机号对应多个账号
+	REFRESH_TOKEN_INVALID  = 10011 // 刷新token已失效
+	EMAIL_REQUIRED         = 10012 // 需要填写邮箱以完成验证
+	ADMIN_UNDELETABLE      = 10013 // 不能删除管理员账号
+	SESSION_NOT_FOUND      = 10014 // 会话不存在或已失效
+)
+
+// BizError 业务错误，携带业务错误码和提示信息，service 层直接作为 error 返回，
+// handler 通过 FailWithError 或 Error 把错误码写入响应
+type BizError struct {
+	Code    int
+	Message string
+}
+
+func (e *BizError) Error() string {
+	return e.Message
+}
+
+// bizErrors 已登记的业务错误，保证错误码不重复
+var bizErrors = make(map[int]*BizError)
+
+// NewBizError 创建并登记业务错误，message 同时作为该错误码的中文消息，
+// 其他语言通过 RegisterMessages 注册；应在包级变量中声明，错误码重复时 panic
+func NewBizError(code int, message string) *BizError {
+	if exist, ok := bizErrors[code]; ok {
+		panic(fmt.Sprintf("业务错误码 %d 重复登记: %s / %s", code, exist.Message, message))
+	}
+	err := &BizError{Code: code, Message: message}
+	bizErrors[code] = err
+	RegisterMessages(LangZH, map[int]string{code: message})
+	return err
+}
@@ -0,0 +1,257 @@
+package response
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// 响应消息支持的语言，未指定或不支持时使用中文
+const (
+	LangZH      = "zh"
+	LangEN      = "en"
+	DefaultLang = LangZH
+)
+
+var (
+	catalogs      = map[string]map[int]string{}
+	texts         = map[string]map[string]string{}
+	catalogsMutex sync.RWMutex
+)
+
+func init() {
+	RegisterMessages(LangZH, map[int]string{
+		PASSWORD_EXPIRED: "密码已过期，请修改密码后重新登录",
+	})
+	RegisterMessages(LangEN, englishMessages())
+	RegisterTexts(LangEN, englishTexts())
+}
+
+// RegisterMessages 注册某种语言下错误码对应的消息，已存在的错误码会被覆盖；
+// 中文消息在 NewBizError 登记错误码时自动注册
+func RegisterMessages(lang string, messages map[int]string) {
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+
+	catalog, ok := catalogs[lang]
+	if !ok {
+		catalog = make(map[int]string, len(messages))
+		catalogs[lang] = catalog
+	}
+	for code, message := range messages {
+		catalog[code] = message
+	}
+}
+
+// RegisterTexts 注册某种语言下通用消息的翻译，键为中文原文，已存在的消息会被覆盖；
+// 用于 Fail、Unauthorized 等直接传入消息的响应，形如 "参数错误: 详情" 的消息以冒号前的部分为键
+func RegisterTexts(lang string, messages map[string]string) {
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+
+	catalog, ok := texts[lang]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		texts[lang] = catalog
+	}
+	for text, message := range messages {
+		catalog[text] = message
+	}
+}
+
+// Lang 根据 Accept-Language 请求头选择响应语言，按 q 值取第一个已注册的语言，
+// 只比较主语言标签(en-US 视为 en)，没有匹配时返回 DefaultLang
+func Lang(c fiber.Ctx) string {
+	header := c.Get(fiber.HeaderAcceptLanguage)
+	if header == "" {
+		return DefaultLang
+	}
+
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	best, bestQ := DefaultLang, 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, q := parseLanguageRange(part)
+		if q <= bestQ {
+			continue
+		}
+		if _, ok := catalogs[tag]; ok {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+// parseLanguageRange 解析 Accept-Language 中的单个语言范围，返回小写主语言标签和 q 值
+func parseLanguageRange(part string) (string, float64) {
+	tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+	q := 1.0
+	if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", 0
+		}
+		q = parsed
+	}
+	primary, _, _ := strings.Cut(tag, "-")
+	return strings.ToLower(strings.TrimSpace(primary)), q
+}
+
+// Message 按请求语言获取错误码对应的消息，该语言未翻译此错误码时返回 fallback
+func Message(c fiber.Ctx, code int, fallback string) string {
+	lang := Lang(c)
+
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	if message, ok := catalogs[lang][code]; ok {
+		return message
+	}
+	return fallback
+}
+
+// Translate 按请求语言翻译通用消息，整条消息已登记时直接替换；
+// 形如 "参数错误: 详情" 的消息分别翻译冒号前后两部分，未登记的部分保持原文
+func Translate(c fiber.Ctx, message string) string {
+	lang := Lang(c)
+	if lang == DefaultLang || message == "" {
+		return message
+	}
+
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+
+	catalog := texts[lang]
+	if translated, ok := catalog[message]; ok {
+		return translated
+	}
+	prefix, detail, found := strings.Cut(message, ": ")
+	if !found {
+		return message
+	}
+	translated, ok := catalog[prefix]
+	if !ok {
+		return message
+	}
+	if detailTranslated, ok := catalog[detail]; ok {
+		detail = detailTranslated
+	}
+	return translated + ": " + detail
+}
+
+// englishMessages 英文错误消息
+func englishMessages() map[int]string {
+	return map[int]string{
+		PASSWORD_EXPIRED:       "Password has expired, please change it and log in again",
+		USER_EXISTS:            "Username already exists",
+		INVALID_CREDENTIALS:    "Incorrect password",
+		USER_NOT_FOUND:         "User not found",
+		USER_DISABLED:          "Account has been disabled",
+		EMAIL_NOT_VERIFIED:     "Email is not verified, please follow the link in the verification email",
+		PHONE_TAKEN:            "Phone number is already in use",
+		EMAIL_TAKEN:            "Email is already in use",
+		OLD_PASSWORD_INCORRECT: "Current password is incorrect",
+		PASSWORD_REUSED:        "New password must not match a recently used password",
+		ACCOUNT_AMBIGUOUS:      "Account is ambiguous, please log in with your username",
+		REFRESH_TOKEN_INVALID:  "Token is no longer valid, please log in again",
+		EMAIL_REQUIRED:         "Email is required for verification",
+		ADMIN_UNDELETABLE:      "Administrator accounts cannot be deleted",
+		SESSION_NOT_FOUND:      "Session does not exist or has expired",
+	}
+}
+
+// englishTexts 处理器和中间件中通用消息的英文翻译，业务层返回的普通 error 不在此列
+func englishTexts() map[string]string {
+	return map[string]string{
+		// 参数绑定和验证，验证规则的消息由 validator 按语言生成
+		"参数错误":   "Invalid parameters",
+		"参数格式错误": "Malformed parameters",
+
+		// 认证和访问控制
+		"请先登录":                "Please log in first",
+		"无效的认证格式":             "Invalid authorization format",
+		"无效的token":            "Invalid token",
+		"token已失效，请重新登录":      "Token is no longer valid, please log in again",
+		"登录会话已失效，请重新登录":       "Login session is no longer valid, please log in again",
+		"无权限访问":               "Access denied",
+		"API Key无权访问":         "API key is not allowed to access this resource",
+		"该接口不支持API Key访问":     "This endpoint does not support API key access",
+		"当前IP禁止访问":            "Access from this IP address is forbidden",
+		"请求过于频繁，请稍后再试":        "Too many requests, please try again later",
+		"token不能为空":           "Token is required",
+		"userId必须为有效数字":       "userId must be a valid number",
+		"密码长度必须在6-20位之间":      "Password must be 6 to 20 characters long",
+		"邮箱不能为空":              "Email is required",
+		"邮箱格式不正确":             "Invalid email address",
+		"注册成功":                "Registered successfully",
+		"注册成功，请前往邮箱完成验证后登录":   "Registered successfully, please verify your email before logging in",
+		"邮箱验证成功":              "Email verified successfully",
+		"退出成功":                "Logged out successfully",
+		"已退出全部会话":             "Logged out of all sessions",
+		"会话已下线":               "Session has been logged out",
+		"密码修改成功":              "Password changed successfully",
+		"密码修改成功，请重新登录":        "Password changed successfully, please log in again",
+		"密码重置成功":              "Password reset successfully",
+		"重置密码失败":              "Failed to reset password",
+		"如果该邮箱已注册，您将收到密码重置邮件": "If the email is registered, you will receive a password reset email",
+		"发送邮件失败，请稍后重试":        "Failed to send email, please try again later",
+		"解锁成功":                "Account unlocked",
+		"该用户未被锁定":             "User is not locked",
+		"令牌已清除":               "Tokens have been cleared",
+		"创建成功，请妥善保存密钥，关闭后将无法再次查看": "Created successfully, please store the key safely; it will not be shown again",
+
+		// 通用操作结果
+		"创建成功":   "Created successfully",
+		"更新成功":   "Updated successfully",
+		"删除成功":   "Deleted successfully",
+		"恢复成功":   "Restored successfully",
+		"分配成功":   "Assigned successfully",
+		"状态更新成功": "Status updated successfully",
+
+		// 文件上传
+		"请选择要上传的文件": "Please select files to upload",
+		"文件上传失败":    "File upload failed",
+		"文件路径不能为空":  "File path is required",
+		"解析表单失败":    "Failed to parse form",
+		"获取上传文件失败":  "Failed to get uploaded file",
+		"读取上传文件失败":  "Failed to read uploaded file",
+		"删除文件失败":    "Failed to delete file",
+		"允许的文件类型":   "Allowed file types",
+		"允许的图片类型":   "Allowed image types",
+
+		// 系统配置
+		"配置ID不能为空":     "Config ID is required",
+		"配置键不能为空":      "Config key is required",
+		"配置版本号不能为空":    "Config version is required",
+		"配置数据不能为空":     "Config data is required",
+		"分组参数不能为空":     "Group is required",
+		"变更记录ID不能为空":   "Change record ID is required",
+		"本地存储路径不能为空":   "Local storage path is required",
+		"获取配置失败":       "Failed to get config",
+		"创建配置失败":       "Failed to create config",
+		"更新配置失败":       "Failed to update config",
+		"删除配置失败":       "Failed to delete config",
+		"批量更新失败":       "Batch update failed",
+		"导入配置失败":       "Failed to import config",
+		"导出配置失败":       "Failed to export config",
+		"回滚配置失败":       "Failed to roll back config",
+		"获取变更历史失败":     "Failed to get change history",
+		"刷新缓存失败":       "Failed to refresh cache",
+		"更新上传配置失败":     "Failed to update upload config",
+		"更新邮件配置失败":     "Failed to update email config",
+		"发送测试邮件失败":     "Failed to send test email",
+		"导入成功":         "Imported successfully",
+		"导入完成，部分配置已跳过": "Import completed, some configs were skipped",
+		"回滚成功":         "Rolled back successfully",
+		"缓存刷新成功":       "Cache refreshed successfully",
+		"上传配置更新成功":     "Upload config updated successfully",
+		"邮件配置更新成功":     "Email config updated successfully",
+		"测试邮件已发送":      "Test email sent",
+		"日志级别已更新":      "Log level updated",
+		"获取角色列表失败":     "Failed to get role list",
+		"获取权限列表失败":     "Failed to get permission list",
+	}
+}
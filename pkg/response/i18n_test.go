@@ -0,0 +1,102 @@
+package response
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// get 发送携带 Accept-Language 的请求，返回响应体
+func get(t *testing.T, app *fiber.App, path, acceptLanguage string) []byte {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	if acceptLanguage != "" {
+		req.Header.Set(fiber.HeaderAcceptLanguage, acceptLanguage)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestLang(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c fiber.Ctx) error {
+		return c.SendString(Lang(c))
+	})
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", LangZH},
+		{"en", LangEN},
+		{"en-US,en;q=0.9", LangEN},
+		{"zh-CN,zh;q=0.9,en;q=0.8", LangZH},
+		{"fr-FR, en;q=0.5", LangEN},
+		{"fr", LangZH},
+		{"en;q=0.2, zh;q=0.8", LangZH},
+		{"en;q=abc", LangZH},
+	}
+	for _, tt := range tests {
+		if got := string(get(t, app, "/", tt.header)); got != tt.want {
+			t.Errorf("Lang(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateMessages(t *testing.T) {
+	app := fiber.New()
+	app.Get("/fail", func(c fiber.Ctx) error {
+		return Fail(c, "参数错误: 邮箱不能为空")
+	})
+	app.Get("/detail", func(c fiber.Ctx) error {
+		return Fail(c, "参数错误: 用户名不能为空")
+	})
+	app.Get("/unauthorized", func(c fiber.Ctx) error {
+		return Unauthorized(c, "请先登录")
+	})
+	app.Get("/success", func(c fiber.Ctx) error {
+		return SuccessWithMessage(c, "删除成功", nil)
+	})
+	app.Get("/plain", func(c fiber.Ctx) error {
+		return Fail(c, "未登记的消息")
+	})
+	app.Get("/biz", func(c fiber.Ctx) error {
+		return Error(c, &BizError{Code: PASSWORD_EXPIRED, Message: "密码已过期"})
+	})
+
+	tests := []struct {
+		path string
+		lang string
+		want string
+	}{
+		{"/fail", "", "参数错误: 邮箱不能为空"},
+		{"/fail", "en", "Invalid parameters: Email is required"},
+		{"/detail", "en-US", "Invalid parameters: 用户名不能为空"},
+		{"/unauthorized", "zh-CN", "请先登录"},
+		{"/unauthorized", "en", "Please log in first"},
+		{"/success", "en", "Deleted successfully"},
+		{"/plain", "en", "未登记的消息"},
+		{"/biz", "", "密码已过期，请修改密码后重新登录"},
+		{"/biz", "en", "Password has expired, please change it and log in again"},
+	}
+	for _, tt := range tests {
+		var resp Response
+		if err := json.Unmarshal(get(t, app, tt.path, tt.lang), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Message != tt.want {
+			t.Errorf("%s lang=%q message = %q, want %q", tt.path, tt.lang, resp.Message, tt.want)
+		}
+	}
+}
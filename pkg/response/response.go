@@ -1,26 +1,56 @@
 package response
 
 import (
+	"errors"
+	"sync/atomic"
+
 	"github.com/gofiber/fiber/v3"
 )
 
 type Response struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data,omitempty"`
+	RequestID string      `json:"requestId,omitempty"` // 请求ID，开启 server.request_id_in_response 时返回
+}
+
+// ErrAborted 表示失败响应已写入，处理器返回该错误以中止后续处理；
+// ErrorHandler 忽略该错误，不会覆盖已写入的响应，中间件也不将其视为服务端错误
+var ErrAborted = errors.New("response: request aborted")
+
+// requestIDInResponse 是否在响应体中返回请求ID，由 server.request_id_in_response 配置
+var requestIDInResponse atomic.Bool
+
+// SetRequestIDInResponse 设置是否在响应体中返回请求ID，加载配置后调用
+func SetRequestIDInResponse(enabled bool) {
+	requestIDInResponse.Store(enabled)
 }
 
 const (
-	SUCCESS = 0
-	ERROR   = 1
+	SUCCESS         = 0
+	ERROR           = 1
+	PARTIAL_SUCCESS = 2 // 部分成功，如批量操作中部分项失败，失败详情在 data 中
+
+	PASSWORD_EXPIRED = 1001 // 密码已过期，需修改密码后重新登录
 )
 
-func Result(c fiber.Ctx, code int, message string, data interface{}) error {
-	return c.JSON(Response{
+// newResponse 构建响应体，消息按 Accept-Language 翻译，按配置附带请求ID
+func newResponse(c fiber.Ctx, code int, message string, data interface{}) Response {
+	resp := Response{
 		Code:    code,
-		Message: message,
+		Message: Translate(c, message),
 		Data:    data,
-	})
+	}
+	if requestIDInResponse.Load() {
+		if id, ok := c.Locals("requestID").(string); ok {
+			resp.RequestID = id
+		}
+	}
+	return resp
+}
+
+func Result(c fiber.Ctx, code int, message string, data interface{}) error {
+	return c.JSON(newResponse(c, code, message, data))
 }
 
 func Success(c fiber.Ctx, data interface{}) error {
@@ -31,6 +61,11 @@ func SuccessWithMessage(c fiber.Ctx, message string, data interface{}) error {
 	return Result(c, SUCCESS, message, data)
 }
 
+// PartialSuccess 部分成功，data 中应包含成功和失败的明细
+func PartialSuccess(c fiber.Ctx, message string, data interface{}) error {
+	return Result(c, PARTIAL_SUCCESS, message, data)
+}
+
 func Fail(c fiber.Ctx, message string) error {
 	return Result(c, ERROR, message, nil)
 }
@@ -39,31 +74,54 @@ func FailWithCode(c fiber.Ctx, code int, message string) error {
 	return Result(c, code, message, nil)
 }
 
+// FailWithError 按业务错误的错误码返回失败响应，消息按 Accept-Language 翻译
+func FailWithError(c fiber.Ctx, err *BizError) error {
+	return Result(c, err.Code, Message(c, err.Code, err.Message), nil)
+}
+
+// Error 返回失败响应，err 为 BizError(含包装)时使用其错误码，否则按 ERROR 返回错误信息
+func Error(c fiber.Ctx, err error) error {
+	var bizErr *BizError
+	if errors.As(err, &bizErr) {
+		return FailWithError(c, bizErr)
+	}
+	return Fail(c, err.Error())
+}
+
+// Abort 写入失败响应并返回 ErrAborted，用于需要调用方中止处理的辅助函数(如参数验证)
+func Abort(c fiber.Ctx, message string) error {
+	if err := Fail(c, message); err != nil {
+		return err
+	}
+	return ErrAborted
+}
+
+// ErrorHandler Fiber 全局错误处理，ErrAborted 表示响应已写入，其余错误交给默认处理
+func ErrorHandler(c fiber.Ctx, err error) error {
+	if errors.Is(err, ErrAborted) {
+		return nil
+	}
+	return fiber.DefaultErrorHandler(c, err)
+}
+
 // Unauthorized 认证失败 HTTP 401
 func Unauthorized(c fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusUnauthorized).JSON(Response{
-		Code:    fiber.StatusUnauthorized,
-		Message: message,
-		Data:    nil,
-	})
+	return c.Status(fiber.StatusUnauthorized).JSON(newResponse(c, fiber.StatusUnauthorized, message, nil))
 }
 
 // Forbidden 权限不足 HTTP 403
 func Forbidden(c fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusForbidden).JSON(Response{
-		Code:    fiber.StatusForbidden,
-		Message: message,
-		Data:    nil,
-	})
+	return c.Status(fiber.StatusForbidden).JSON(newResponse(c, fiber.StatusForbidden, message, nil))
 }
 
 // TooManyRequests 请求过于频繁 HTTP 429
 func TooManyRequests(c fiber.Ctx, message string) error {
-	return c.Status(fiber.StatusTooManyRequests).JSON(Response{
-		Code:    fiber.StatusTooManyRequests,
-		Message: message,
-		Data:    nil,
-	})
+	return c.Status(fiber.StatusTooManyRequests).JSON(newResponse(c, fiber.StatusTooManyRequests, message, nil))
+}
+
+// ServiceUnavailable 服务暂不可用 HTTP 503
+func ServiceUnavailable(c fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusServiceUnavailable).JSON(newResponse(c, fiber.StatusServiceUnavailable, message, nil))
 }
 
 type PageResult struct {
@@ -81,3 +139,20 @@ func SuccessWithPage(c fiber.Ctx, items interface{}, total int64, page, pageSize
 		PageSize: pageSize,
 	})
 }
+
+// CursorResult 游标分页结果，NextCursor 为下一页请求应携带的 cursor，HasMore 为 false 时表示已无更多数据
+type CursorResult struct {
+	Items      interface{} `json:"items"`
+	NextCursor uint        `json:"nextCursor"`
+	HasMore    bool        `json:"hasMore"`
+	Limit      int         `json:"limit"`
+}
+
+func SuccessWithCursor(c fiber.Ctx, items interface{}, nextCursor uint, hasMore bool, limit int) error {
+	return Success(c, CursorResult{
+		Items:      items,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+		Limit:      limit,
+	})
+}
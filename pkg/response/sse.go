@@ -0,0 +1,103 @@
+package response
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// sseKeepAlive 无事件时发送注释行的间隔，防止代理因空闲断开连接，同时用于探测客户端是否断开
+const sseKeepAlive = 15 * time.Second
+
+// Event 服务端推送事件(Server-Sent Events)
+type Event struct {
+	ID    string      // 事件ID，客户端重连时通过 Last-Event-ID 带回
+	Event string      // 事件类型，为空时客户端按 message 事件处理
+	Data  interface{} // 事件数据，字符串原样输出，其他类型输出JSON
+	Retry int         // 客户端重连间隔(毫秒)，0 表示不设置
+}
+
+// SSE 以 text/event-stream 格式推送事件，每个事件写完立即刷新
+// 通道关闭或客户端断开时结束推送，之后调用 onClose 释放事件源(如取消订阅)
+func SSE(c fiber.Ctx, events <-chan Event, onClose ...func()) error {
+	c.Set(fiber.HeaderContentType, "text/event-stream; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// 禁止 Nginx 缓冲响应，保证事件实时到达
+	c.Set("X-Accel-Buffering", "no")
+
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		defer func() {
+			for _, fn := range onClose {
+				fn()
+			}
+		}()
+
+		ticker := time.NewTicker(sseKeepAlive)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if err := writeEvent(w, event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+			}
+			// 客户端断开时 Flush 返回错误
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// writeEvent 按 SSE 格式写入一个事件，多行数据拆分为多个 data 行
+func writeEvent(w *bufio.Writer, event Event) error {
+	var data string
+	switch v := event.Data.(type) {
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+
+	var sb strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&sb, "id: %s\n", sanitizeSSEField(event.ID))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&sb, "event: %s\n", sanitizeSSEField(event.Event))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&sb, "retry: %d\n", event.Retry)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&sb, "data: %s\n", strings.TrimSuffix(line, "\r"))
+	}
+	sb.WriteString("\n")
+
+	_, err := w.WriteString(sb.String())
+	return err
+}
+
+// sanitizeSSEField 去掉单行字段中的换行，避免破坏事件格式
+func sanitizeSSEField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DiskUsage 磁盘空间
+type DiskUsage struct {
+	Path  string // 实际统计的目录
+	Total uint64 // 总空间(字节)
+	Free  uint64 // 非特权用户可用空间(字节)
+}
+
+// FreePercent 可用空间百分比
+func (d *DiskUsage) FreePercent() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return float64(d.Free) * 100 / float64(d.Total)
+}
+
+// GetDiskUsage 获取 path 所在磁盘的空间，path 不存在时统计最近的已存在的上级目录
+func GetDiskUsage(path string) (*DiskUsage, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	total, free, err := statfs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskUsage{Path: dir, Total: total, Free: free}, nil
+}
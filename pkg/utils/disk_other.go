@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd)
+
+package utils
+
+import "errors"
+
+// statfs 当前平台不支持统计磁盘空间
+func statfs(path string) (total, free uint64, err error) {
+	return 0, 0, errors.New("当前平台不支持统计磁盘空间")
+}
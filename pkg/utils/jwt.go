@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type TokenType string
@@ -20,6 +21,7 @@ type Claims struct {
 	Username  string    `json:"username"`
 	Role      int8      `json:"role"`
 	TokenType TokenType `json:"tokenType"`
+	SessionID string    `json:"sid,omitempty"` // 登录会话ID，刷新令牌时保持不变
 	jwt.RegisteredClaims
 }
 
@@ -27,16 +29,22 @@ type TokenPair struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
 	ExpiresIn    int64  `json:"expiresIn"` // Access Token过期时间(秒)
+	SessionID    string `json:"-"`         // 登录会话ID
 }
 
-// GenerateTokenPair 生成双Token
+// GenerateTokenPair 生成双Token，并开启新的登录会话
 func GenerateTokenPair(userID uint, username string, role int8) (*TokenPair, error) {
-	accessToken, err := generateToken(userID, username, role, AccessToken)
+	return GenerateTokenPairWithSession(userID, username, role, uuid.New().String())
+}
+
+// GenerateTokenPairWithSession 为指定会话生成双Token
+func GenerateTokenPairWithSession(userID uint, username string, role int8, sessionID string) (*TokenPair, error) {
+	accessToken, err := generateToken(userID, username, role, sessionID, AccessToken)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := generateToken(userID, username, role, RefreshToken)
+	refreshToken, err := generateToken(userID, username, role, sessionID, RefreshToken)
 	if err != nil {
 		return nil, err
 	}
@@ -44,22 +52,19 @@ func GenerateTokenPair(userID uint, username string, role int8) (*TokenPair, err
 	return &TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-		ExpiresIn:    int64(config.AppConfig.JWT.AccessExpire) * 3600,
+		ExpiresIn:    int64(config.Get().JWT.AccessExpire) * 3600,
+		SessionID:    sessionID,
 	}, nil
 }
 
-func generateToken(userID uint, username string, role int8, tokenType TokenType) (string, error) {
-	cfg := config.AppConfig.JWT
+func generateToken(userID uint, username string, role int8, sessionID string, tokenType TokenType) (string, error) {
+	cfg := config.Get().JWT
 
 	var expire int
-	var secret string
-
 	if tokenType == AccessToken {
 		expire = cfg.AccessExpire
-		secret = cfg.Secret
 	} else {
 		expire = cfg.RefreshExpire
-		secret = cfg.RefreshSecret
 	}
 
 	claims := Claims{
@@ -67,31 +72,57 @@ func generateToken(userID uint, username string, role int8, tokenType TokenType)
 		Username:  username,
 		Role:      role,
 		TokenType: tokenType,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Issuer:    cfg.Issuer,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expire) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if cfg.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{cfg.Audience}
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	method, key, err := signingKey(tokenType)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	return token.SignedString(key)
 }
 
 // ParseAccessToken 解析Access Token
 func ParseAccessToken(tokenString string) (*Claims, error) {
-	return parseToken(tokenString, config.AppConfig.JWT.Secret, AccessToken)
+	return parseToken(tokenString, AccessToken)
 }
 
 // ParseRefreshToken 解析Refresh Token
 func ParseRefreshToken(tokenString string) (*Claims, error) {
-	return parseToken(tokenString, config.AppConfig.JWT.RefreshSecret, RefreshToken)
+	return parseToken(tokenString, RefreshToken)
 }
 
-func parseToken(tokenString, secret string, expectedType TokenType) (*Claims, error) {
+func parseToken(tokenString string, expectedType TokenType) (*Claims, error) {
+	key, err := verifyKey(expectedType)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只接受配置的签名算法，防止算法混淆攻击(如用公钥作为HS256密钥伪造令牌)
+	cfg := config.Get().JWT
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{jwtAlgorithm()})}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+		return key, nil
+	}, opts...)
 
 	if err != nil {
 		return nil, err
@@ -116,12 +147,16 @@ func RefreshAccessToken(refreshTokenString string) (*TokenPair, error) {
 		return nil, err
 	}
 
-	return GenerateTokenPair(claims.UserID, claims.Username, claims.Role)
+	// 沿用原会话ID，保证会话在刷新后仍可被识别和撤销
+	if claims.SessionID == "" {
+		return GenerateTokenPair(claims.UserID, claims.Username, claims.Role)
+	}
+	return GenerateTokenPairWithSession(claims.UserID, claims.Username, claims.Role, claims.SessionID)
 }
 
 // 兼容旧接口
 func GenerateToken(userID uint, username string, role int8) (string, error) {
-	return generateToken(userID, username, role, AccessToken)
+	return generateToken(userID, username, role, "", AccessToken)
 }
 
 func ParseToken(tokenString string) (*Claims, error) {
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"goboot/config"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWT 签名算法
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+var (
+	rsaPrivateKey *rsa.PrivateKey
+	rsaPublicKey  *rsa.PublicKey
+)
+
+// InitJWT 根据配置初始化JWT签名密钥
+// HS256 使用配置中的共享密钥，无需初始化；RS256 需加载PEM格式的RSA密钥，
+// 只负责验证令牌的服务可以仅配置公钥
+func InitJWT() error {
+	cfg := config.Get().JWT
+
+	switch jwtAlgorithm() {
+	case AlgorithmHS256:
+		return nil
+	case AlgorithmRS256:
+		if cfg.PrivateKey != "" {
+			data, err := os.ReadFile(cfg.PrivateKey)
+			if err != nil {
+				return fmt.Errorf("读取JWT私钥失败: %w", err)
+			}
+			key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return fmt.Errorf("解析JWT私钥失败: %w", err)
+			}
+			rsaPrivateKey = key
+			rsaPublicKey = &key.PublicKey
+		}
+
+		if cfg.PublicKey != "" {
+			data, err := os.ReadFile(cfg.PublicKey)
+			if err != nil {
+				return fmt.Errorf("读取JWT公钥失败: %w", err)
+			}
+			key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return fmt.Errorf("解析JWT公钥失败: %w", err)
+			}
+			rsaPublicKey = key
+		}
+
+		if rsaPublicKey == nil {
+			return errors.New("RS256 需要配置 jwt.private_key 或 jwt.public_key")
+		}
+		return nil
+	default:
+		return fmt.Errorf("不支持的JWT签名算法: %s", config.Get().JWT.Algorithm)
+	}
+}
+
+// jwtAlgorithm 获取配置的签名算法，默认 HS256
+func jwtAlgorithm() string {
+	alg := strings.ToUpper(config.Get().JWT.Algorithm)
+	if alg == "" {
+		return AlgorithmHS256
+	}
+	return alg
+}
+
+// signingKey 获取签发令牌使用的算法和密钥
+func signingKey(tokenType TokenType) (jwt.SigningMethod, interface{}, error) {
+	switch jwtAlgorithm() {
+	case AlgorithmHS256:
+		return jwt.SigningMethodHS256, hmacSecret(tokenType), nil
+	case AlgorithmRS256:
+		if rsaPrivateKey == nil {
+			return nil, nil, errors.New("未加载JWT私钥，无法签发令牌")
+		}
+		return jwt.SigningMethodRS256, rsaPrivateKey, nil
+	default:
+		return nil, nil, fmt.Errorf("不支持的JWT签名算法: %s", config.Get().JWT.Algorithm)
+	}
+}
+
+// verifyKey 获取验证令牌使用的密钥
+func verifyKey(tokenType TokenType) (interface{}, error) {
+	switch jwtAlgorithm() {
+	case AlgorithmHS256:
+		return hmacSecret(tokenType), nil
+	case AlgorithmRS256:
+		if rsaPublicKey == nil {
+			return nil, errors.New("未加载JWT公钥，无法验证令牌")
+		}
+		return rsaPublicKey, nil
+	default:
+		return nil, fmt.Errorf("不支持的JWT签名算法: %s", config.Get().JWT.Algorithm)
+	}
+}
+
+// hmacSecret 获取HS256密钥，Access Token与Refresh Token使用不同密钥
+func hmacSecret(tokenType TokenType) []byte {
+	cfg := config.Get().JWT
+	if tokenType == AccessToken {
+		return []byte(cfg.Secret)
+	}
+	return []byte(cfg.RefreshSecret)
+}
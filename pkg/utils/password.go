@@ -1,13 +1,169 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
+	"goboot/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 密码哈希算法
+const (
+	PasswordAlgBcrypt   = "bcrypt"
+	PasswordAlgArgon2id = "argon2id"
+)
+
+// argon2id 默认参数(OWASP 推荐值)，哈希以 PHC 格式保存:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+const (
+	defaultArgon2Memory  = 64 * 1024
+	defaultArgon2Time    = 3
+	defaultArgon2Threads = 2
+	argon2SaltLen        = 16
+	argon2KeyLen         = 32
+	argon2Prefix         = "$argon2id$"
+)
+
+var errInvalidArgon2Hash = errors.New("argon2id 哈希格式错误")
+
+// argon2Params argon2id 哈希参数
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+}
+
+// passwordAlgorithm 配置的密码哈希算法，未配置时为 bcrypt
+func passwordAlgorithm() string {
+	if cfg := config.Get(); cfg != nil && cfg.Password.Algorithm == PasswordAlgArgon2id {
+		return PasswordAlgArgon2id
+	}
+	return PasswordAlgBcrypt
+}
+
+// bcryptCost 配置的 bcrypt 成本，未配置或超出范围时为 bcrypt.DefaultCost
+func bcryptCost() int {
+	if cfg := config.Get(); cfg != nil {
+		if cost := cfg.Password.BcryptCost; cost >= bcrypt.MinCost && cost <= bcrypt.MaxCost {
+			return cost
+		}
+	}
+	return bcrypt.DefaultCost
+}
+
+// configuredArgon2Params 配置的 argon2id 参数，未配置的项使用默认值
+func configuredArgon2Params() argon2Params {
+	p := argon2Params{memory: defaultArgon2Memory, time: defaultArgon2Time, threads: defaultArgon2Threads}
+	appCfg := config.Get()
+	if appCfg == nil {
+		return p
+	}
+	cfg := appCfg.Password
+	if cfg.Argon2Memory > 0 {
+		p.memory = cfg.Argon2Memory
+	}
+	if cfg.Argon2Time > 0 {
+		p.time = cfg.Argon2Time
+	}
+	if cfg.Argon2Threads > 0 {
+		p.threads = cfg.Argon2Threads
+	}
+	return p
+}
+
+// HashPassword 按 password.algorithm 配置的算法生成密码哈希，哈希自带算法标识，
+// 切换算法后旧哈希仍可通过 CheckPassword 验证
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if passwordAlgorithm() == PasswordAlgArgon2id {
+		return hashArgon2(password, configuredArgon2Params())
+	}
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost())
 	return string(bytes), err
 }
 
+// CheckPassword 根据哈希的算法标识验证密码，支持 bcrypt 和 argon2id
 func CheckPassword(password, hash string) bool {
+	if strings.HasPrefix(hash, argon2Prefix) {
+		ok, err := checkArgon2(password, hash)
+		return err == nil && ok
+	}
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// NeedsRehash 判断哈希的算法或参数是否与当前配置不一致，不一致时应在验证通过后用明文重新生成哈希
+func NeedsRehash(hash string) bool {
+	if strings.HasPrefix(hash, argon2Prefix) {
+		if passwordAlgorithm() != PasswordAlgArgon2id {
+			return true
+		}
+		params, _, _, err := parseArgon2(hash)
+		return err != nil || params != configuredArgon2Params()
+	}
+
+	if passwordAlgorithm() != PasswordAlgBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != bcryptCost()
+}
+
+// hashArgon2 使用随机盐生成 PHC 格式的 argon2id 哈希
+func hashArgon2(password string, p argon2Params) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, argon2KeyLen)
+
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix, argon2.Version, p.memory, p.time, p.threads,
+		enc.EncodeToString(salt), enc.EncodeToString(key),
+	), nil
+}
+
+// checkArgon2 使用哈希中的参数和盐重新计算并以常量时间比较
+func checkArgon2(password, hash string) (bool, error) {
+	p, salt, key, err := parseArgon2(hash)
+	if err != nil {
+		return false, err
+	}
+	actual := argon2.IDKey([]byte(password), salt, p.time, p.memory, p.threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+// parseArgon2 解析 PHC 格式的 argon2id 哈希
+func parseArgon2(hash string) (argon2Params, []byte, []byte, error) {
+	var p argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgArgon2id {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+	key, err := enc.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, errInvalidArgon2Hash
+	}
+	return p, salt, key, nil
+}
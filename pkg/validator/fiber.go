@@ -11,17 +11,17 @@ import (
 //
 //	var req LoginRequest
 //	if err := validator.BindAndValidate(c, &req); err != nil {
-//	    return err // 已经返回了标准错误响应
+//	    return err // 已经返回了标准错误响应，err 为 response.ErrAborted
 //	}
 func BindAndValidate(c fiber.Ctx, req any) error {
 	// 绑定请求体
 	if err := c.Bind().Body(req); err != nil {
-		return response.Fail(c, "参数格式错误: "+err.Error())
+		return response.Abort(c, "参数格式错误: "+err.Error())
 	}
 
 	// 执行验证
-	if err := Validate(req); err != nil {
-		return response.Fail(c, "参数错误: "+err.Error())
+	if err := validateRequest(c, req); err != nil {
+		return response.Abort(c, "参数错误: "+err.Error())
 	}
 
 	return nil
@@ -31,12 +31,44 @@ func BindAndValidate(c fiber.Ctx, req any) error {
 func BindQueryAndValidate(c fiber.Ctx, req any) error {
 	// 绑定Query参数
 	if err := c.Bind().Query(req); err != nil {
-		return response.Fail(c, "参数格式错误: "+err.Error())
+		return response.Abort(c, "参数格式错误: "+err.Error())
 	}
 
 	// 执行验证
-	if err := Validate(req); err != nil {
-		return response.Fail(c, "参数错误: "+err.Error())
+	if err := validateRequest(c, req); err != nil {
+		return response.Abort(c, "参数错误: "+err.Error())
+	}
+
+	return nil
+}
+
+// BindAllAndValidate 依次绑定请求体、Query参数和路由参数后统一验证
+// 优先级: 路由参数 > Query参数 > 请求体，后绑定的来源只覆盖其中出现的字段；
+// 各来源分别按 json、query、uri 标签匹配字段，请求体为空时跳过，不视为错误
+//
+//	type DetailRequest struct {
+//	    ID uint `json:"id" query:"id" uri:"id" validate:"required" label:"ID"`
+//	}
+func BindAllAndValidate(c fiber.Ctx, req any) error {
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(req); err != nil {
+			return response.Abort(c, "参数格式错误: "+err.Error())
+		}
+	}
+
+	if err := c.Bind().Query(req); err != nil {
+		return response.Abort(c, "参数格式错误: "+err.Error())
+	}
+
+	if len(c.Route().Params) > 0 {
+		if err := c.Bind().URI(req); err != nil {
+			return response.Abort(c, "参数格式错误: "+err.Error())
+		}
+	}
+
+	// 执行验证
+	if err := validateRequest(c, req); err != nil {
+		return response.Abort(c, "参数错误: "+err.Error())
 	}
 
 	return nil
@@ -45,17 +77,25 @@ func BindQueryAndValidate(c fiber.Ctx, req any) error {
 // MustValidate 仅验证（不绑定），返回错误响应
 // 适用于已经绑定后需要再次验证的场景
 func MustValidate(c fiber.Ctx, req any) error {
-	if err := Validate(req); err != nil {
-		return response.Fail(c, "参数错误: "+err.Error())
+	if err := validateRequest(c, req); err != nil {
+		return response.Abort(c, "参数错误: "+err.Error())
 	}
 	return nil
 }
 
+// validateRequest 按请求头 Accept-Language 选择错误消息的语言验证请求参数，未携带时使用验证器当前语言
+func validateRequest(c fiber.Ctx, req any) error {
+	if c.Get(fiber.HeaderAcceptLanguage) == "" {
+		return Validate(req)
+	}
+	return ValidateLang(req, response.Lang(c))
+}
+
 // ValidateVar 验证单个变量
 // 使用方式:
 //
 //	if err := validator.ValidateVar(email, "required,email"); err != nil {
-//	    return response.Fail(c, "邮箱格式不正确")
+//	    return response.Abort(c, "邮箱格式不正确")
 //	}
 func ValidateVar(value any, rules string) error {
 	// 创建一个临时结构体进行验证
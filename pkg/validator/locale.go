@@ -0,0 +1,155 @@
+package validator
+
+import "sync"
+
+// 内置语言
+const (
+	LangZH = "zh"
+	LangEN = "en"
+)
+
+var (
+	locales      = map[string]map[string]string{}
+	localesMutex sync.RWMutex
+)
+
+func init() {
+	RegisterLocale(LangZH, defaultMessages())
+	RegisterLocale(LangEN, englishMessages())
+}
+
+// RegisterLocale 注册语言消息集
+// 已存在的语言会被合并覆盖，未提供的规则保留原消息
+func RegisterLocale(lang string, messages map[string]string) {
+	localesMutex.Lock()
+	defer localesMutex.Unlock()
+
+	existing, ok := locales[lang]
+	if !ok {
+		existing = make(map[string]string, len(messages))
+		locales[lang] = existing
+	}
+	for tag, msg := range messages {
+		existing[tag] = msg
+	}
+}
+
+// getLocale 获取语言消息集副本
+func getLocale(lang string) (map[string]string, bool) {
+	localesMutex.RLock()
+	defer localesMutex.RUnlock()
+
+	messages, ok := locales[lang]
+	if !ok {
+		return nil, false
+	}
+	copied := make(map[string]string, len(messages))
+	for tag, msg := range messages {
+		copied[tag] = msg
+	}
+	return copied, true
+}
+
+// Option 验证器选项
+type Option func(*Validator)
+
+// WithLocale 指定验证器使用的语言
+func WithLocale(lang string) Option {
+	return func(v *Validator) {
+		v.SetLanguage(lang)
+	}
+}
+
+// SetLanguage 设置默认验证器的语言
+func SetLanguage(lang string) bool {
+	return defaultValidator.SetLanguage(lang)
+}
+
+// SetLanguage 切换错误消息语言
+// 切换后通过 SetMessage 设置的自定义消息会被替换，语言不存在时返回 false
+func (v *Validator) SetLanguage(lang string) bool {
+	messages, ok := getLocale(lang)
+	if !ok {
+		return false
+	}
+	v.lang = lang
+	v.messages = messages
+	return true
+}
+
+// ValidateLang 使用指定语言的错误消息验证结构体，不改变验证器当前的语言，可在并发请求中使用；
+// 非中文时字段名取 label_<lang> 标签(如 label_en)，未设置时使用 json 字段名；
+// 语言未注册时按当前语言验证。通过 SetMessage 设置的自定义消息只在当前语言下生效
+func (v *Validator) ValidateLang(s any, lang string) error {
+	if lang == v.lang {
+		return v.Validate(s)
+	}
+	messages, ok := getLocale(lang)
+	if !ok {
+		return v.Validate(s)
+	}
+
+	lv := *v
+	lv.lang = lang
+	lv.messages = messages
+	if lang != LangZH {
+		lv.labelTag = v.labelTag + "_" + lang
+	}
+	return lv.Validate(s)
+}
+
+// englishMessages 英文错误消息
+func englishMessages() map[string]string {
+	return map[string]string{
+		"default":    "{field} is invalid",
+		"required":   "{field} is required",
+		"min":        "{field} must be at least {param} in length",
+		"max":        "{field} must not exceed {param} in length",
+		"len":        "{field} must be exactly {param} in length",
+		"range":      "{field} length must be between {min} and {max}",
+		"email":      "{field} must be a valid email address",
+		"phone":      "{field} must be a valid phone number",
+		"url":        "{field} must be a valid URL",
+		"ip":         "{field} must be a valid IP address",
+		"ipv4":       "{field} must be a valid IPv4 address",
+		"ipv6":       "{field} must be a valid IPv6 address",
+		"cidr":       "{field} must be a valid CIDR notation",
+		"alpha":      "{field} may only contain letters",
+		"alphanum":   "{field} may only contain letters and digits",
+		"numeric":    "{field} may only contain digits",
+		"number":     "{field} must be a number",
+		"lowercase":  "{field} may only contain lowercase letters",
+		"uppercase":  "{field} may only contain uppercase letters",
+		"contains":   "{field} must contain {param}",
+		"startswith": "{field} must start with {param}",
+		"endswith":   "{field} must end with {param}",
+		"regex":      "{field} has an invalid format",
+		"eq":         "{field} must be equal to {param}",
+		"ne":         "{field} must not be equal to {param}",
+		"gt":         "{field} must be greater than {param}",
+		"gte":        "{field} must be greater than or equal to {param}",
+		"lt":         "{field} must be less than {param}",
+		"lte":        "{field} must be less than or equal to {param}",
+		"oneof":      "{field} must be one of: {param}",
+		"unique":     "{field} must not contain duplicate values",
+		"username":   "{field} may only contain letters, digits and underscores",
+		"password":   "{field} must contain letters and digits and be at least {param} characters",
+		"idcard":     "{field} must be a valid ID card number",
+		"bankcard":   "{field} must be a valid bank card number",
+		"uuid":       "{field} must be a valid UUID",
+		"json":       "{field} must be valid JSON",
+
+		// 子串和字符集合规则，containsany、excludesall 的参数为字符集合
+		"excludes":    "{field} must not contain {param}",
+		"excludesall": "{field} must not contain any of the characters: {param}",
+		"containsany": "{field} must contain at least one of the characters: {param}",
+
+		// 强密码规则，按未满足的要求细分消息
+		"strongpassword":        "{field} must be at least {param} characters and contain lowercase, uppercase, digit and symbol characters",
+		"strongpassword.length": "{field} must be at least {param} characters",
+		"strongpassword.lower":  "{field} must contain a lowercase letter",
+		"strongpassword.upper":  "{field} must contain an uppercase letter",
+		"strongpassword.digit":  "{field} must contain a digit",
+		"strongpassword.symbol": "{field} must contain a symbol",
+	}
+}
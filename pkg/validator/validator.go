@@ -1,11 +1,16 @@
 package validator
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -52,6 +57,7 @@ func (e ValidationErrors) All() []string {
 type Validator struct {
 	tagName    string            // 标签名称，默认 "validate"
 	labelTag   string            // 字段标签名，默认 "label"
+	lang       string            // 错误消息语言，默认中文
 	messages   map[string]string // 自定义错误消息
 	validators map[string]ValidatorFunc
 }
@@ -60,13 +66,17 @@ type Validator struct {
 type ValidatorFunc func(field reflect.Value, param string) bool
 
 // New 创建验证器
-func New() *Validator {
+func New(opts ...Option) *Validator {
 	v := &Validator{
 		tagName:    "validate",
 		labelTag:   "label",
+		lang:       LangZH,
 		messages:   defaultMessages(),
 		validators: make(map[string]ValidatorFunc),
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
 	return v
 }
 
@@ -78,6 +88,11 @@ func Validate(s any) error {
 	return defaultValidator.Validate(s)
 }
 
+// ValidateLang 使用默认验证器按指定语言验证结构体
+func ValidateLang(s any, lang string) error {
+	return defaultValidator.ValidateLang(s, lang)
+}
+
 // RegisterValidator 注册自定义验证器
 func RegisterValidator(name string, fn ValidatorFunc) {
 	defaultValidator.RegisterValidator(name, fn)
@@ -91,7 +106,11 @@ func SetMessage(tag, message string) {
 // Validate 验证结构体
 func (v *Validator) Validate(s any) error {
 	val := reflect.ValueOf(s)
+	visited := make(map[uintptr]bool)
 	if val.Kind() == reflect.Ptr {
+		if !val.IsNil() {
+			visited[val.Pointer()] = true
+		}
 		val = val.Elem()
 	}
 
@@ -100,7 +119,7 @@ func (v *Validator) Validate(s any) error {
 	}
 
 	var errors ValidationErrors
-	v.validateStruct(val, &errors)
+	v.validateStruct(val, "", visited, &errors)
 
 	if len(errors) > 0 {
 		return errors
@@ -119,7 +138,8 @@ func (v *Validator) SetMessage(tag, message string) {
 }
 
 // validateStruct 验证结构体
-func (v *Validator) validateStruct(val reflect.Value, errors *ValidationErrors) {
+// prefix 为嵌套字段的父级路径(如 "Address.")，visited 记录当前路径上已访问的指针，防止循环引用导致无限递归
+func (v *Validator) validateStruct(val reflect.Value, prefix string, visited map[uintptr]bool, errors *ValidationErrors) {
 	typ := val.Type()
 
 	for i := 0; i < val.NumField(); i++ {
@@ -131,62 +151,114 @@ func (v *Validator) validateStruct(val reflect.Value, errors *ValidationErrors)
 			continue
 		}
 
-		// 处理嵌套结构体
-		if field.Kind() == reflect.Struct && fieldType.Anonymous {
-			v.validateStruct(field, errors)
+		// 获取验证规则
+		tagValue := fieldType.Tag.Get(v.tagName)
+		if tagValue == "-" {
 			continue
 		}
 
-		// 获取验证规则
-		tagValue := fieldType.Tag.Get(v.tagName)
-		if tagValue == "" || tagValue == "-" {
+		// 处理匿名嵌入结构体，字段名不加前缀
+		if fieldType.Anonymous && field.Kind() == reflect.Struct {
+			v.validateStruct(field, prefix, visited, errors)
 			continue
 		}
 
-		// 获取字段标签（中文名）
-		label := fieldType.Tag.Get(v.labelTag)
-		if label == "" {
-			// 尝试从 json 标签获取
-			jsonTag := fieldType.Tag.Get("json")
-			if jsonTag != "" && jsonTag != "-" {
-				label = strings.Split(jsonTag, ",")[0]
-			} else {
-				label = fieldType.Name
-			}
+		name := prefix + fieldType.Name
+		if tagValue != "" && !v.validateRules(field, fieldType, name, tagValue, errors) {
+			continue // 字段自身验证失败时不再校验其内部字段
 		}
 
-		// 解析验证规则
-		rules := strings.Split(tagValue, ",")
-		for _, rule := range rules {
-			rule = strings.TrimSpace(rule)
-			if rule == "" {
-				continue
-			}
+		// 处理具名嵌套结构体及结构体指针
+		v.validateNested(field, name+".", visited, errors)
+	}
+}
+
+// validateNested 递归验证嵌套结构体字段
+func (v *Validator) validateNested(field reflect.Value, prefix string, visited map[uintptr]bool, errors *ValidationErrors) {
+	switch field.Kind() {
+	case reflect.Struct:
+		v.validateStruct(field, prefix, visited, errors)
+	case reflect.Ptr:
+		if field.IsNil() || field.Elem().Kind() != reflect.Struct {
+			return
+		}
+		ptr := field.Pointer()
+		if visited[ptr] {
+			return
+		}
+		visited[ptr] = true
+		v.validateStruct(field.Elem(), prefix, visited, errors)
+		delete(visited, ptr)
+	}
+}
+
+// validateRules 按标签规则验证单个字段，通过返回 true
+func (v *Validator) validateRules(field reflect.Value, fieldType reflect.StructField, name, tagValue string, errors *ValidationErrors) bool {
+	// 获取字段标签（中文名）
+	label := fieldType.Tag.Get(v.labelTag)
+	if label == "" {
+		// 尝试从 json 标签获取
+		jsonTag := fieldType.Tag.Get("json")
+		if jsonTag != "" && jsonTag != "-" {
+			label = strings.Split(jsonTag, ",")[0]
+		} else {
+			label = fieldType.Name
+		}
+	}
+
+	// 解析验证规则
+	rules := strings.Split(tagValue, ",")
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		// 解析规则名和参数
+		tag, param := parseRule(rule)
 
-			// 解析规则名和参数
-			tag, param := parseRule(rule)
-
-			// 执行验证
-			if !v.validateField(field, tag, param) {
-				msg := v.formatMessage(tag, label, param)
-				*errors = append(*errors, &ValidationError{
-					Field:   fieldType.Name,
-					Tag:     tag,
-					Value:   field.Interface(),
-					Message: msg,
-				})
-				break // 一个字段只报告第一个错误
+		// omitempty: 字段为空值时跳过其余规则，有值时继续验证
+		if tag == "omitempty" {
+			if isEmptyValue(field) {
+				return true
 			}
+			continue
+		}
+
+		// 执行验证
+		if !v.validateField(field, tag, param) {
+			msg := v.formatMessage(v.messageTag(field, tag, param), label, param)
+			*errors = append(*errors, &ValidationError{
+				Field:   name,
+				Tag:     tag,
+				Value:   field.Interface(),
+				Message: msg,
+			})
+			return false // 一个字段只报告第一个错误
 		}
 	}
+	return true
+}
+
+// isEmptyValue 是否为空值：字符串、切片、映射长度为 0，指针和接口为 nil，其他类型为零值
+func isEmptyValue(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return field.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return field.IsNil()
+	default:
+		return field.IsZero()
+	}
 }
 
 // parseRule 解析规则
+// 规则之间以逗号分隔，参数中的逗号写作 0x2C，如 excludesall=0x2C;
 func parseRule(rule string) (tag, param string) {
 	parts := strings.SplitN(rule, "=", 2)
 	tag = parts[0]
 	if len(parts) > 1 {
-		param = parts[1]
+		param = strings.ReplaceAll(parts[1], "0x2C", ",")
 	}
 	return
 }
@@ -218,6 +290,12 @@ func (v *Validator) validateField(field reflect.Value, tag, param string) bool {
 		return validateURL(field)
 	case "ip":
 		return validateIP(field)
+	case "ipv4":
+		return validateIPv4(field)
+	case "ipv6":
+		return validateIPv6(field)
+	case "cidr":
+		return validateCIDR(field)
 	case "alpha":
 		return validateAlpha(field)
 	case "alphanum":
@@ -232,6 +310,12 @@ func (v *Validator) validateField(field reflect.Value, tag, param string) bool {
 		return validateUppercase(field)
 	case "contains":
 		return validateContains(field, param)
+	case "containsany":
+		return validateContainsAny(field, param)
+	case "excludes":
+		return validateExcludes(field, param)
+	case "excludesall":
+		return validateExcludesAll(field, param)
 	case "startswith":
 		return validateStartsWith(field, param)
 	case "endswith":
@@ -252,22 +336,51 @@ func (v *Validator) validateField(field reflect.Value, tag, param string) bool {
 		return validateLte(field, param)
 	case "oneof":
 		return validateOneOf(field, param)
+	case "unique":
+		return validateUnique(field, param)
 	case "username":
 		return validateUsername(field)
 	case "password":
 		return validatePassword(field, param)
+	case "strongpassword":
+		return validateStrongPassword(field, param)
 	case "idcard":
 		return validateIDCard(field)
+	case "bankcard":
+		return validateBankCard(field)
+	case "uuid":
+		return validateUUID(field)
+	case "json":
+		return validateJSON(field)
 	default:
 		return true // 未知规则默认通过
 	}
 }
 
+// messageTag 获取错误消息对应的键
+// 部分规则可细分失败原因，如 strongpassword.upper，未配置细分消息时回退到规则名
+func (v *Validator) messageTag(field reflect.Value, tag, param string) string {
+	if _, custom := v.validators[tag]; custom {
+		return tag
+	}
+	if tag == "strongpassword" {
+		if reason := strongPasswordFailure(field, param); reason != "" {
+			if _, ok := v.messages[tag+"."+reason]; ok {
+				return tag + "." + reason
+			}
+		}
+	}
+	return tag
+}
+
 // formatMessage 格式化错误消息
 func (v *Validator) formatMessage(tag, label, param string) string {
 	msg, ok := v.messages[tag]
 	if !ok {
-		msg = "验证失败"
+		msg, ok = v.messages["default"]
+		if !ok {
+			msg = "验证失败"
+		}
 	}
 
 	// 替换占位符
@@ -298,6 +411,9 @@ func defaultMessages() map[string]string {
 		"phone":      "{field}必须是有效的手机号",
 		"url":        "{field}必须是有效的URL",
 		"ip":         "{field}必须是有效的IP地址",
+		"ipv4":       "{field}必须是有效的IPv4地址",
+		"ipv6":       "{field}必须是有效的IPv6地址",
+		"cidr":       "{field}必须是有效的CIDR网段",
 		"alpha":      "{field}只能包含字母",
 		"alphanum":   "{field}只能包含字母和数字",
 		"numeric":    "{field}只能包含数字",
@@ -315,9 +431,24 @@ func defaultMessages() map[string]string {
 		"lt":         "{field}必须小于{param}",
 		"lte":        "{field}必须小于或等于{param}",
 		"oneof":      "{field}必须是以下值之一: {param}",
+		"unique":     "{field}不能包含重复的值",
 		"username":   "{field}只能包含字母、数字和下划线",
 		"password":   "{field}必须包含字母和数字，长度至少{param}位",
 		"idcard":     "{field}必须是有效的身份证号",
+		"bankcard":   "{field}必须是有效的银行卡号",
+		"uuid":       "{field}必须是有效的UUID",
+		"json":       "{field}必须是有效的JSON",
+		// 子串和字符集合规则，containsany、excludesall 的参数为字符集合
+		"excludes":    "{field}不能包含{param}",
+		"excludesall": "{field}不能包含以下字符: {param}",
+		"containsany": "{field}必须包含以下字符之一: {param}",
+		// 强密码规则，按未满足的要求细分消息
+		"strongpassword":        "{field}必须包含大小写字母、数字和特殊符号，长度至少{param}位",
+		"strongpassword.length": "{field}长度至少{param}位",
+		"strongpassword.lower":  "{field}必须包含小写字母",
+		"strongpassword.upper":  "{field}必须包含大写字母",
+		"strongpassword.digit":  "{field}必须包含数字",
+		"strongpassword.symbol": "{field}必须包含特殊符号",
 	}
 }
 
@@ -419,16 +550,17 @@ func validateRange(field reflect.Value, param string) bool {
 
 // 正则表达式预编译
 var (
-	emailRegex    = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	phoneRegex    = regexp.MustCompile(`^1[3-9]\d{9}$`)
-	urlRegex      = regexp.MustCompile(`^https?://[^\s/$.?#].[^\s]*$`)
-	ipRegex       = regexp.MustCompile(`^(\d{1,3}\.){3}\d{1,3}$`)
-	alphaRegex    = regexp.MustCompile(`^[a-zA-Z]+$`)
-	alphaNumRegex = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
-	numericRegex  = regexp.MustCompile(`^[0-9]+$`)
-	numberRegex   = regexp.MustCompile(`^-?[0-9]+\.?[0-9]*$`)
-	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
-	idcardRegex   = regexp.MustCompile(`^[1-9]\d{5}(18|19|20)\d{2}(0[1-9]|1[0-2])(0[1-9]|[12]\d|3[01])\d{3}[\dXx]$`)
+	emailRegex      = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	phoneRegex      = regexp.MustCompile(`^1[3-9]\d{9}$`)
+	urlRegex        = regexp.MustCompile(`^https?://[^\s/$.?#].[^\s]*$`)
+	alphaRegex      = regexp.MustCompile(`^[a-zA-Z]+$`)
+	alphaNumRegex   = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	numericRegex    = regexp.MustCompile(`^[0-9]+$`)
+	numberRegex     = regexp.MustCompile(`^-?[0-9]+\.?[0-9]*$`)
+	usernameRegex   = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	idcardRegex     = regexp.MustCompile(`^[1-9]\d{5}(18|19|20)\d{2}(0[1-9]|1[0-2])(0[1-9]|[12]\d|3[01])\d{3}[\dXx]$`)
+	oneofParamRegex = regexp.MustCompile(`'([^']*)'|\S+`)
+	uuidRegex       = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-4[0-9a-fA-F]{3}-[89abAB][0-9a-fA-F]{3}-[0-9a-fA-F]{12}$`)
 )
 
 // validateEmail 邮箱验证
@@ -467,7 +599,7 @@ func validateURL(field reflect.Value) bool {
 	return urlRegex.MatchString(s)
 }
 
-// validateIP IP地址验证
+// validateIP IP地址验证(IPv4或IPv6)
 func validateIP(field reflect.Value) bool {
 	if field.Kind() != reflect.String {
 		return false
@@ -476,18 +608,45 @@ func validateIP(field reflect.Value) bool {
 	if s == "" {
 		return true
 	}
-	if !ipRegex.MatchString(s) {
+	return net.ParseIP(s) != nil
+}
+
+// validateIPv4 IPv4地址验证
+func validateIPv4(field reflect.Value) bool {
+	if field.Kind() != reflect.String {
 		return false
 	}
-	// 验证每个数字段在 0-255 范围内
-	parts := strings.Split(s, ".")
-	for _, part := range parts {
-		num, _ := strconv.Atoi(part)
-		if num > 255 {
-			return false
-		}
+	s := field.String()
+	if s == "" {
+		return true
 	}
-	return true
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+}
+
+// validateIPv6 IPv6地址验证
+func validateIPv6(field reflect.Value) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	s := field.String()
+	if s == "" {
+		return true
+	}
+	return net.ParseIP(s) != nil && strings.Contains(s, ":")
+}
+
+// validateCIDR CIDR网段验证
+func validateCIDR(field reflect.Value) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	s := field.String()
+	if s == "" {
+		return true
+	}
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
 }
 
 // validateAlpha 纯字母验证
@@ -570,6 +729,30 @@ func validateContains(field reflect.Value, param string) bool {
 	return strings.Contains(field.String(), param)
 }
 
+// validateContainsAny 包含字符集合中的任意一个字符
+func validateContainsAny(field reflect.Value, param string) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	return strings.ContainsAny(field.String(), param)
+}
+
+// validateExcludes 不包含指定子串
+func validateExcludes(field reflect.Value, param string) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	return !strings.Contains(field.String(), param)
+}
+
+// validateExcludesAll 不包含字符集合中的任何字符，如 excludesall=/\ 可阻止路径分隔符
+func validateExcludesAll(field reflect.Value, param string) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	return !strings.ContainsAny(field.String(), param)
+}
+
 // validateStartsWith 前缀验证
 func validateStartsWith(field reflect.Value, param string) bool {
 	if field.Kind() != reflect.String {
@@ -603,13 +786,30 @@ func validateRegex(field reflect.Value, param string) bool {
 	if s == "" {
 		return true
 	}
-	re, err := regexp.Compile(param)
-	if err != nil {
+	re := compileRegex(param)
+	if re == nil {
 		return false
 	}
 	return re.MatchString(s)
 }
 
+// regexCache 缓存 regex 规则编译后的正则，键为模式字符串；
+// 编译失败的模式缓存为 nil，不再重复编译。模式来自结构体标签，数量有限，不做淘汰
+var regexCache sync.Map
+
+// compileRegex 编译并缓存正则，模式无效时返回 nil
+func compileRegex(pattern string) *regexp.Regexp {
+	if cached, ok := regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	regexCache.Store(pattern, re)
+	return re
+}
+
 // validateEq 相等验证
 func validateEq(field reflect.Value, param string) bool {
 	switch field.Kind() {
@@ -704,40 +904,103 @@ func validateLte(field reflect.Value, param string) bool {
 
 // validateOneOf 枚举验证
 func validateOneOf(field reflect.Value, param string) bool {
-	if field.Kind() != reflect.String {
+	var val string
+	switch field.Kind() {
+	case reflect.String:
+		val = field.String()
+		if val == "" {
+			return true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		// 对于非字符串类型，转换后比较
-		switch field.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			val := strconv.FormatInt(field.Int(), 10)
-			for _, v := range strings.Split(param, " ") {
-				if val == v {
-					return true
-				}
-			}
-			return false
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			val := strconv.FormatUint(field.Uint(), 10)
-			for _, v := range strings.Split(param, " ") {
-				if val == v {
-					return true
-				}
-			}
-			return false
-		default:
-			return false
+		val = strconv.FormatInt(field.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val = strconv.FormatUint(field.Uint(), 10)
+	default:
+		return false
+	}
+
+	for _, v := range parseOneOfParam(param) {
+		if val == v {
+			return true
 		}
 	}
+	return false
+}
 
-	s := field.String()
-	if s == "" {
+// parseOneOfParam 解析枚举参数
+// 以空格分隔，支持单引号包裹含空格的值，如: 'North America' Europe
+func parseOneOfParam(param string) []string {
+	matches := oneofParamRegex.FindAllStringSubmatch(param, -1)
+	values := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if strings.HasPrefix(m[0], "'") {
+			values = append(values, m[1])
+		} else {
+			values = append(values, m[0])
+		}
+	}
+	return values
+}
+
+// validateUnique 元素唯一验证
+// 切片、数组的元素不能重复，映射的值不能重复；元素为结构体(或结构体指针)时可用 unique=字段名 按该字段去重
+func validateUnique(field reflect.Value, param string) bool {
+	switch field.Kind() {
+	case reflect.Slice, reflect.Array:
+		seen := make(map[any]struct{}, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			key, ok := uniqueKey(field.Index(i), param)
+			if !ok {
+				return false
+			}
+			if _, dup := seen[key]; dup {
+				return false
+			}
+			seen[key] = struct{}{}
+		}
+		return true
+	case reflect.Map:
+		seen := make(map[any]struct{}, field.Len())
+		iter := field.MapRange()
+		for iter.Next() {
+			key, ok := uniqueKey(iter.Value(), param)
+			if !ok {
+				return false
+			}
+			if _, dup := seen[key]; dup {
+				return false
+			}
+			seen[key] = struct{}{}
+		}
 		return true
+	default:
+		return false
 	}
-	for _, v := range strings.Split(param, " ") {
-		if s == v {
-			return true
+}
+
+// uniqueKey 返回元素用于去重的值，param 不为空时取结构体元素的同名字段；
+// 元素不是结构体、字段不存在或值不可比较时返回 false
+func uniqueKey(elem reflect.Value, param string) (any, bool) {
+	if param != "" {
+		for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+			if elem.IsNil() {
+				return nil, false
+			}
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			return nil, false
+		}
+		elem = elem.FieldByName(param)
+		if !elem.IsValid() {
+			return nil, false
 		}
 	}
-	return false
+	if !elem.CanInterface() || !elem.Comparable() {
+		return nil, false
+	}
+	return elem.Interface(), true
 }
 
 // validateUsername 用户名验证
@@ -785,7 +1048,70 @@ func validatePassword(field reflect.Value, param string) bool {
 	return hasLetter && hasDigit
 }
 
+// validateStrongPassword 强密码验证
+// param 为最小长度(默认8)，要求同时包含小写字母、大写字母、数字和特殊符号
+func validateStrongPassword(field reflect.Value, param string) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	return strongPasswordFailure(field, param) == ""
+}
+
+// strongPasswordFailure 返回强密码未满足的要求，满足时返回空字符串
+func strongPasswordFailure(field reflect.Value, param string) string {
+	if field.Kind() != reflect.String {
+		return ""
+	}
+	s := field.String()
+	if s == "" {
+		return ""
+	}
+
+	minLen := 8
+	if param != "" {
+		if n, err := strconv.Atoi(param); err == nil {
+			minLen = n
+		}
+	}
+	if utf8.RuneCountInString(s) < minLen {
+		return "length"
+	}
+
+	var hasLower, hasUpper, hasDigit, hasSymbol bool
+	for _, c := range s {
+		switch {
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		case unicode.IsPunct(c) || unicode.IsSymbol(c):
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case !hasLower:
+		return "lower"
+	case !hasUpper:
+		return "upper"
+	case !hasDigit:
+		return "digit"
+	case !hasSymbol:
+		return "symbol"
+	}
+	return ""
+}
+
+// idcardWeights 身份证号前 17 位的加权因子(GB 11643)
+var idcardWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// idcardCheckCodes 加权和除以 11 的余数对应的校验码
+const idcardCheckCodes = "10X98765432"
+
 // validateIDCard 身份证号验证
+// 校验 18 位格式、出生日期是否真实存在以及末位校验码(GB 11643)
 func validateIDCard(field reflect.Value) bool {
 	if field.Kind() != reflect.String {
 		return false
@@ -794,5 +1120,70 @@ func validateIDCard(field reflect.Value) bool {
 	if s == "" {
 		return true
 	}
-	return idcardRegex.MatchString(s)
+	if !idcardRegex.MatchString(s) {
+		return false
+	}
+	if _, err := time.Parse("20060102", s[6:14]); err != nil {
+		return false
+	}
+
+	sum := 0
+	for i, w := range idcardWeights {
+		sum += int(s[i]-'0') * w
+	}
+	return idcardCheckCodes[sum%11] == byte(unicode.ToUpper(rune(s[17])))
+}
+
+// validateBankCard 银行卡号验证
+// 12-19 位数字，并通过 Luhn 校验
+func validateBankCard(field reflect.Value) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	s := field.String()
+	if s == "" {
+		return true
+	}
+	if len(s) < 12 || len(s) > 19 || !numericRegex.MatchString(s) {
+		return false
+	}
+
+	// 从右往左，偶数位乘 2，结果大于 9 时减 9
+	sum := 0
+	for i := 0; i < len(s); i++ {
+		d := int(s[len(s)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validateUUID UUID(v4)验证
+func validateUUID(field reflect.Value) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	s := field.String()
+	if s == "" {
+		return true
+	}
+	return uuidRegex.MatchString(s)
+}
+
+// validateJSON JSON格式验证
+func validateJSON(field reflect.Value) bool {
+	if field.Kind() != reflect.String {
+		return false
+	}
+	s := field.String()
+	if s == "" {
+		return true
+	}
+	var raw json.RawMessage
+	return json.Unmarshal([]byte(s), &raw) == nil
 }
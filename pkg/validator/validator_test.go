@@ -0,0 +1,364 @@
+package validator
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"goboot/pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// validateValue 用给定规则验证单个值，规则以结构体标签的形式附加到临时结构体的字段上
+func validateValue(value any, rules string) error {
+	typ := reflect.StructOf([]reflect.StructField{{
+		Name: "Field",
+		Type: reflect.TypeOf(value),
+		Tag:  reflect.StructTag(`validate:"` + rules + `" label:"字段"`),
+	}})
+	v := reflect.New(typ)
+	v.Elem().Field(0).Set(reflect.ValueOf(value))
+	return New().Validate(v.Interface())
+}
+
+// ruleCase 单条规则的测试用例
+type ruleCase struct {
+	name  string
+	value any
+	valid bool
+}
+
+// runRuleCases 逐条验证用例，valid 为 true 时期望通过，否则期望失败
+func runRuleCases(t *testing.T, rules string, cases []ruleCase) {
+	t.Helper()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateValue(tc.value, rules)
+			if tc.valid && err != nil {
+				t.Errorf("%s: %#v 应通过验证，实际错误: %v", rules, tc.value, err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("%s: %#v 应验证失败，实际通过", rules, tc.value)
+			}
+		})
+	}
+}
+
+func TestUUID(t *testing.T) {
+	runRuleCases(t, "uuid", []ruleCase{
+		{"empty", "", true},
+		{"v4 lowercase", "3f1c2b8e-9a4d-4c6e-8f2a-1b3c5d7e9f01", true},
+		{"v4 uppercase", "3F1C2B8E-9A4D-4C6E-BF2A-1B3C5D7E9F01", true},
+		{"v1", "6ba7b810-9dad-11d1-80b4-00c04fd430c8", false},
+		{"invalid variant", "3f1c2b8e-9a4d-4c6e-cf2a-1b3c5d7e9f01", false},
+		{"no hyphens", "3f1c2b8e9a4d4c6e8f2a1b3c5d7e9f01", false},
+		{"too short", "3f1c2b8e-9a4d-4c6e-8f2a-1b3c5d7e9f0", false},
+		{"non hex", "3f1c2b8e-9a4d-4c6e-8f2a-1b3c5d7e9fzz", false},
+		{"braces", "{3f1c2b8e-9a4d-4c6e-8f2a-1b3c5d7e9f01}", false},
+		{"not string", 123, false},
+	})
+}
+
+func TestJSON(t *testing.T) {
+	runRuleCases(t, "json", []ruleCase{
+		{"empty", "", true},
+		{"object", `{"a":1,"b":[true,null]}`, true},
+		{"array", `[1,"2",{}]`, true},
+		{"number", `42`, true},
+		{"string", `"text"`, true},
+		{"null", `null`, true},
+		{"unclosed", `{"a":1`, false},
+		{"single quotes", `{'a':1}`, false},
+		{"trailing comma", `[1,2,]`, false},
+		{"bare word", `abc`, false},
+		{"trailing data", `{} {}`, false},
+		{"not string", []byte(`{}`), false},
+	})
+}
+
+func TestUUIDMessage(t *testing.T) {
+	err := validateValue("not-a-uuid", "uuid")
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("期望 1 个 ValidationError，实际: %v", err)
+	}
+	if errs[0].Tag != "uuid" || errs[0].Message != "字段必须是有效的UUID" {
+		t.Errorf("错误信息不符: tag=%s message=%s", errs[0].Tag, errs[0].Message)
+	}
+}
+
+func TestParseOneOfParam(t *testing.T) {
+	tests := []struct {
+		param string
+		want  []string
+	}{
+		{"新 旧", []string{"新", "旧"}},
+		{"1 2 3", []string{"1", "2", "3"}},
+		{"'North America' Europe", []string{"North America", "Europe"}},
+		{"Asia 'North America' 'South America'", []string{"Asia", "North America", "South America"}},
+		{"''  a", []string{"", "a"}},
+	}
+	for _, tt := range tests {
+		if got := parseOneOfParam(tt.param); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseOneOfParam(%q) = %q, want %q", tt.param, got, tt.want)
+		}
+	}
+}
+
+func TestOneOf(t *testing.T) {
+	t.Run("unquoted", func(t *testing.T) {
+		runRuleCases(t, "oneof=新 旧", []ruleCase{
+			{"empty", "", true},
+			{"first", "新", true},
+			{"second", "旧", true},
+			{"other", "中", false},
+			{"both", "新 旧", false},
+		})
+	})
+	t.Run("quoted", func(t *testing.T) {
+		runRuleCases(t, "oneof='North America' Europe", []ruleCase{
+			{"quoted value", "North America", true},
+			{"plain value", "Europe", true},
+			{"partial word", "North", false},
+			{"with quotes", "'North America'", false},
+			{"other", "Asia", false},
+		})
+	})
+	t.Run("int", func(t *testing.T) {
+		runRuleCases(t, "oneof=1 2 3", []ruleCase{
+			{"int 1", 1, true},
+			{"int 3", 3, true},
+			{"int 0", 0, false},
+			{"int 4", 4, false},
+			{"int8", int8(2), true},
+			{"uint", uint(3), true},
+			{"uint 5", uint(5), false},
+			{"float", 1.0, false},
+		})
+	})
+}
+
+func TestIDCard(t *testing.T) {
+	runRuleCases(t, "idcard", []ruleCase{
+		{"empty", "", true},
+		{"valid X", "11010519491231002X", true},
+		{"valid lowercase x", "11010519491231002x", true},
+		{"valid digit", "440524188001010014", true},
+		{"wrong check digit", "110105194912310021", false},
+		{"wrong check X", "44052418800101001X", false},
+		{"invalid date", "110105194902300020", false},
+		{"area starts with 0", "020105194912310028", false},
+		{"invalid century", "110105214912310025", false},
+		{"17 digits", "11010519491231002", false},
+		{"15 digits", "110105491231002", false},
+		{"not string", 11010519491231002, false},
+	})
+}
+
+func TestBankCard(t *testing.T) {
+	runRuleCases(t, "bankcard", []ruleCase{
+		{"empty", "", true},
+		{"visa", "4111111111111111", true},
+		{"mastercard", "5500000000000004", true},
+		{"amex 15 digits", "378282246310005", true},
+		{"unionpay 19 digits", "6212345678901234569", true},
+		{"12 digits", "123456789015", true},
+		{"checksum broken", "4111111111111112", false},
+		{"checksum broken 19 digits", "6212345678901234560", false},
+		{"too short", "12345674", false},
+		{"too long", "41111111111111111115", false},
+		{"spaces", "4111 1111 1111 1111", false},
+		{"letters", "411111111111111a", false},
+		{"not string", 4111111111111111, false},
+	})
+}
+
+func TestUnique(t *testing.T) {
+	type tag struct {
+		ID   int
+		Name string
+	}
+
+	t.Run("strings", func(t *testing.T) {
+		runRuleCases(t, "unique", []ruleCase{
+			{"nil", []string(nil), true},
+			{"distinct", []string{"a", "b", "c"}, true},
+			{"duplicate", []string{"a", "b", "a"}, false},
+			{"case sensitive", []string{"a", "A"}, true},
+			{"array duplicate", [3]string{"x", "y", "x"}, false},
+		})
+	})
+	t.Run("ints", func(t *testing.T) {
+		runRuleCases(t, "unique", []ruleCase{
+			{"distinct", []int{1, 2, 3}, true},
+			{"duplicate", []int{1, 2, 2}, false},
+			{"map values distinct", map[string]int{"a": 1, "b": 2}, true},
+			{"map values duplicate", map[string]int{"a": 1, "b": 1}, false},
+		})
+	})
+	t.Run("struct field", func(t *testing.T) {
+		runRuleCases(t, "unique=Name", []ruleCase{
+			{"distinct", []tag{{1, "go"}, {2, "rust"}}, true},
+			{"duplicate field", []tag{{1, "go"}, {2, "go"}}, false},
+			{"pointers", []*tag{{1, "go"}, {2, "rust"}}, true},
+			{"pointers duplicate", []*tag{{1, "go"}, {2, "go"}}, false},
+			{"nil pointer", []*tag{{1, "go"}, nil}, false},
+			{"not struct", []string{"a", "b"}, false},
+		})
+		runRuleCases(t, "unique=Missing", []ruleCase{
+			{"unknown field", []tag{{1, "go"}}, false},
+		})
+	})
+	t.Run("whole struct", func(t *testing.T) {
+		runRuleCases(t, "unique", []ruleCase{
+			{"same name different id", []tag{{1, "go"}, {2, "go"}}, true},
+			{"identical", []tag{{1, "go"}, {1, "go"}}, false},
+		})
+	})
+	t.Run("unsupported", func(t *testing.T) {
+		runRuleCases(t, "unique", []ruleCase{
+			{"not comparable", [][]int{{1}, {2}}, false},
+			{"string", "abc", false},
+		})
+	})
+}
+
+func TestRegexCache(t *testing.T) {
+	runRuleCases(t, `regex=^[a-z]+\\d*$`, []ruleCase{
+		{"empty", "", true},
+		{"match", "abc12", true},
+		{"no match", "ABC", false},
+	})
+
+	// 无效的模式缓存为 nil，验证始终失败
+	const bad = "([a-z"
+	if re := compileRegex(bad); re != nil {
+		t.Fatalf("无效模式应返回 nil")
+	}
+	cached, ok := regexCache.Load(bad)
+	if !ok || cached.(*regexp.Regexp) != nil {
+		t.Errorf("无效模式应缓存为 nil，实际: %v %v", cached, ok)
+	}
+	if err := validateValue("abc", "regex="+bad); err == nil {
+		t.Errorf("无效模式应验证失败")
+	}
+
+	// 同一模式返回同一个编译结果
+	if compileRegex("^x+$") != compileRegex("^x+$") {
+		t.Errorf("相同模式应复用缓存的正则")
+	}
+}
+
+// benchmarkPattern 基准测试使用的模式，近似常见的编码规则
+const benchmarkPattern = `^[A-Z]{2}-\d{4}-[a-z0-9]{6}$`
+
+func BenchmarkRegexRule(b *testing.B) {
+	const value = "AB-2024-x9k2m1"
+
+	b.Run("uncached", func(b *testing.B) {
+		for b.Loop() {
+			re, err := regexp.Compile(benchmarkPattern)
+			if err != nil || !re.MatchString(value) {
+				b.Fatal("unexpected mismatch")
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		for b.Loop() {
+			re := compileRegex(benchmarkPattern)
+			if re == nil || !re.MatchString(value) {
+				b.Fatal("unexpected mismatch")
+			}
+		}
+	})
+	b.Run("validate", func(b *testing.B) {
+		req := struct {
+			Code string `validate:"regex=^[A-Z]{2}-\\d{4}-[a-z0-9]{6}$"`
+		}{Code: value}
+		v := New()
+		for b.Loop() {
+			if err := v.Validate(&req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestValidateLang(t *testing.T) {
+	type request struct {
+		Username string `json:"username" validate:"required" label:"用户名"`
+		Email    string `json:"email" validate:"email" label:"邮箱" label_en:"Email address"`
+	}
+	req := request{Email: "invalid"}
+
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{LangZH, []string{"用户名不能为空", "邮箱必须是有效的邮箱地址"}},
+		{LangEN, []string{"username is required", "Email address must be a valid email address"}},
+		{"fr", []string{"用户名不能为空", "邮箱必须是有效的邮箱地址"}},
+	}
+	v := New()
+	for _, tt := range tests {
+		err := v.ValidateLang(&req, tt.lang)
+		errs, ok := err.(ValidationErrors)
+		if !ok {
+			t.Fatalf("lang=%s 期望 ValidationErrors，实际: %v", tt.lang, err)
+		}
+		if got := errs.All(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lang=%s 错误信息 = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+
+	// 不改变验证器当前语言
+	if err := v.Validate(&req); err == nil || err.Error() != "用户名不能为空" {
+		t.Errorf("ValidateLang 后默认语言应保持中文，实际: %v", err)
+	}
+}
+
+func TestBindAndValidateLang(t *testing.T) {
+	type request struct {
+		Username string `json:"username" validate:"required" label:"用户名"`
+	}
+	app := fiber.New(fiber.Config{ErrorHandler: response.ErrorHandler})
+	app.Post("/", func(c fiber.Ctx) error {
+		var req request
+		if err := BindAndValidate(c, &req); err != nil {
+			return err
+		}
+		return response.Success(c, nil)
+	})
+
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"", "参数错误: 用户名不能为空"},
+		{"zh-CN", "参数错误: 用户名不能为空"},
+		{"en-US,en;q=0.9", "Invalid parameters: username is required"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		if tt.acceptLanguage != "" {
+			req.Header.Set(fiber.HeaderAcceptLanguage, tt.acceptLanguage)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body response.Response
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if body.Message != tt.want {
+			t.Errorf("Accept-Language=%q message = %q, want %q", tt.acceptLanguage, body.Message, tt.want)
+		}
+	}
+}
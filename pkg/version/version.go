@@ -0,0 +1,33 @@
+// Package version 构建信息，通过 -ldflags 在编译时注入：
+//
+//	go build -ldflags "-X goboot/pkg/version.Version=v1.0.0 \
+//	  -X goboot/pkg/version.GitCommit=$(git rev-parse --short HEAD) \
+//	  -X goboot/pkg/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "runtime"
+
+// 未注入时均为 "dev"
+var (
+	Version   = "dev" // 版本号
+	GitCommit = "dev" // Git 提交
+	BuildTime = "dev" // 构建时间
+)
+
+// Info 构建信息
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"gitCommit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get 获取构建信息
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -1,83 +1,196 @@
 package router
 
 import (
+	"path/filepath"
+
+	"goboot/config"
 	"goboot/internal/handler"
 	"goboot/internal/middleware"
+	"goboot/internal/model"
+	"goboot/internal/service"
+	"goboot/pkg/metrics"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/gofiber/fiber/v3/middleware/static"
+	"github.com/gofiber/fiber/v3/middleware/pprof"
 )
 
 func SetupRouter(app *fiber.App) {
+	// 最先解析客户端IP，后续中间件通过 c.IP() 获取的均为真实客户端IP
+	app.Use(middleware.ClientIP())
+	app.Use(middleware.Metrics())
+	app.Use(middleware.RequestID())
 	app.Use(middleware.Logger())
 	app.Use(middleware.Recovery())
 	app.Use(middleware.Cors())
+	app.Use(middleware.SecurityHeaders())
+	app.Use(middleware.Compress())
 	app.Use(middleware.RateLimiter())
+	app.Use(middleware.MaintenanceMode())
+
+	cfg := config.Get()
+
+	// 静态文件服务，仅公开目录(如头像)可直接访问，其他上传文件通过 /api/upload/download 鉴权下载
+	// 目录在启动时确定，修改 upload_local_path 后需重启才能访问新目录中的公开文件
+	uploadDir := service.GetConfigService().GetUploadConfig().LocalPath
+	if uploadDir == "" {
+		uploadDir = "./uploads"
+	}
+	app.Get("/uploads/"+service.PublicCategory+"/*", uploadStatic(filepath.Join(uploadDir, service.PublicCategory)))
+	// 兼容旧版本保存在 avatars 目录的头像
+	app.Get("/uploads/avatars/*", uploadStatic(filepath.Join(uploadDir, "avatars")))
+
+	// Prometheus 指标
+	if cfg.Metrics.Enabled {
+		app.Get(middleware.MetricsPath, metrics.Handler())
+	}
 
-	// 静态文件服务(上传文件访问)
-	app.Get("/uploads/*", static.New("./uploads"))
+	// 性能分析(默认关闭)，仅超级管理员可访问
+	if cfg.Server.Pprof {
+		app.Use("/debug/pprof", middleware.JWTAuth(), middleware.RequirePermission(model.PermAll), pprof.New())
+	}
+
+	// 接口文档(默认关闭)，生产环境不应开启
+	if cfg.Server.Swagger {
+		setupSwagger(app)
+	}
 
 	// 健康检查接口
 	app.Get("/ping", handler.Ping)
 	app.Get("/health", handler.HealthCheck)
+	app.Get("/health/live", handler.HealthLive)
+	app.Get("/health/ready", handler.HealthCheck)
+
+	// 构建信息(无需登录，供部署工具检查)
+	app.Get("/version", handler.Version)
 
 	userHandler := handler.NewUserHandler()
 	auditHandler := handler.NewAuditHandler()
 	emailHandler := handler.NewEmailHandler()
 	uploadHandler := handler.NewUploadHandler()
 	configHandler := handler.NewConfigHandler()
+	rbacHandler := handler.NewRBACHandler()
+	logHandler := handler.NewLogHandler()
+	cronHandler := handler.NewCronHandler()
+	apiKeyHandler := handler.NewAPIKeyHandler()
+	maintenanceHandler := handler.NewMaintenanceHandler()
 
 	api := app.Group("/api")
 
+	// 写操作路由通过 middleware.Audit 自动记录审计日志，处理器已手动记录时不重复记录
+
 	// Public routes
 	userAuth := api.Group("/auth")
-	userAuth.Post("/register", userHandler.Register)
-	userAuth.Post("/login", userHandler.Login)
+	userAuth.Post("/register", middleware.Audit(model.ActionRegister, model.ModuleAuth), userHandler.Register)
+	userAuth.Post("/login", middleware.Audit(model.ActionLogin, model.ModuleAuth), userHandler.Login)
 	userAuth.Post("/refreshToken", userHandler.RefreshToken)
-	userAuth.Post("/logout", userHandler.Logout)
-	userAuth.Post("/forgotPassword", emailHandler.ForgotPassword)
-	userAuth.Post("/resetPassword", emailHandler.ResetPassword)
+	userAuth.Post("/changeExpiredPassword", middleware.Audit(model.ActionChangePassword, model.ModuleAuth), userHandler.ChangeExpiredPassword)
+	userAuth.Post("/logout", middleware.Audit(model.ActionLogout, model.ModuleAuth), userHandler.Logout)
+	userAuth.Post("/forgotPassword", middleware.Audit(model.ActionForgotPassword, model.ModuleAuth), emailHandler.ForgotPassword)
+	userAuth.Post("/resetPassword", middleware.Audit(model.ActionResetPassword, model.ModuleAuth), emailHandler.ResetPassword)
+	userAuth.Get("/verifyEmail", middleware.Audit(model.ActionVerifyEmail, model.ModuleAuth), emailHandler.VerifyEmail)
+	userAuth.Post("/resendVerification", middleware.Audit(model.ActionResendVerify, model.ModuleAuth), emailHandler.ResendVerification)
 
 	// 公开配置(无需登录)
 	api.Get("/config/public", configHandler.GetPublicConfigs)
 
-	// User authenticated routes
-	auth := api.Group("", middleware.JWTAuth())
+	// 签名链接下载文件(无需登录，由签名校验权限)
+	api.Get("/upload/signed", uploadHandler.SignedDownload)
+
+	// User authenticated routes (支持 Access Token 或 X-API-Key 认证)
+	// 全局限流在认证之前按IP计数，认证之后的路由组再按用户计数
+	auth := api.Group("", middleware.Auth(), middleware.UserRateLimiter())
 	auth.Get("/user/profile", userHandler.GetProfile)
-	auth.Post("/user/updateProfile", userHandler.UpdateProfile)
-	auth.Post("/user/changePassword", userHandler.ChangePassword)
+	auth.Post("/user/updateProfile", middleware.Audit(model.ActionUpdateProfile, model.ModuleUser), userHandler.UpdateProfile)
+	auth.Post("/user/avatar", middleware.Audit(model.ActionUpdateProfile, model.ModuleUser), userHandler.UploadAvatar)
+	auth.Post("/user/changePassword", middleware.DenyAPIKey(), middleware.Audit(model.ActionChangePassword, model.ModuleUser), userHandler.ChangePassword)
+	auth.Get("/user/sessions", middleware.DenyAPIKey(), userHandler.GetSessions)
+	auth.Post("/user/sessions/revoke", middleware.DenyAPIKey(), middleware.Audit(model.ActionLogout, model.ModuleAuth), userHandler.RevokeSession)
+	auth.Post("/user/logoutAll", middleware.DenyAPIKey(), middleware.Audit(model.ActionLogout, model.ModuleAuth), userHandler.LogoutAll)
+
+	// API Key 管理，与密码、会话相关接口一样只能通过登录令牌操作
+	apiKeys := auth.Group("/user/apiKeys", middleware.DenyAPIKey())
+	apiKeys.Get("", apiKeyHandler.GetAPIKeys)
+	apiKeys.Post("/add", middleware.Audit(model.ActionCreate, model.ModuleUser), apiKeyHandler.CreateAPIKey)
+	apiKeys.Post("/revoke", middleware.Audit(model.ActionDelete, model.ModuleUser), apiKeyHandler.RevokeAPIKey)
 
 	// Upload routes (需要登录)
 	upload := auth.Group("/upload")
-	upload.Post("/file", uploadHandler.UploadFile)
-	upload.Post("/image", uploadHandler.UploadImage)
-	upload.Post("/files", uploadHandler.UploadFiles)
-	upload.Post("/delete", uploadHandler.DeleteFile)
+	upload.Post("/file", middleware.Audit(model.ActionUpload, model.ModuleFile), uploadHandler.UploadFile)
+	upload.Post("/image", middleware.Audit(model.ActionUpload, model.ModuleFile), uploadHandler.UploadImage)
+	upload.Post("/files", middleware.Audit(model.ActionUpload, model.ModuleFile), uploadHandler.UploadFiles)
+	upload.Post("/delete", middleware.Audit(model.ActionDelete, model.ModuleFile), uploadHandler.DeleteFile)
 	upload.Get("/info", uploadHandler.GetFileInfo)
+	upload.Get("/download", uploadHandler.Download)
+	upload.Get("/presign", uploadHandler.PresignURL)
 
-	// Admin routes
-	admin := api.Group("/admin", middleware.JWTAuth(), middleware.AdminAuth())
+	// 当前用户的有效权限
+	auth.Get("/user/permissions", rbacHandler.GetMyPermissions)
+
+	// Admin routes，按权限细分，role=1 的管理员拥有全部权限
+	// 配置 admin_ip_filter 后仅允许指定IP访问管理接口
+	ipFilter := cfg.AdminIPFilter
+	admin := api.Group("/admin", middleware.IPFilter(ipFilter.Allow, ipFilter.Deny), middleware.Auth(), middleware.UserRateLimiter())
 	// User management
-	admin.Post("/user/list", userHandler.AdminGetUserList)
-	admin.Post("/user/add", userHandler.AdminCreateUser)
-	admin.Get("/user/detail", userHandler.AdminGetUserDetail)
-	admin.Post("/user/update", userHandler.AdminUpdateUser)
-	admin.Post("/user/delete", userHandler.AdminDeleteUser)
-	admin.Post("/user/resetPassword", userHandler.AdminResetPassword)
-	admin.Post("/user/updateStatus", userHandler.AdminUpdateUserStatus)
+	userAdmin := admin.Group("/user", middleware.RequirePermission(model.PermUserManage))
+	userAdmin.Post("/list", userHandler.AdminGetUserList)
+	userAdmin.Post("/add", middleware.Audit(model.ActionCreateUser, model.ModuleAdmin), userHandler.AdminCreateUser)
+	userAdmin.Post("/import", middleware.Audit(model.ActionImport, model.ModuleAdmin), userHandler.AdminImportUsers)
+	userAdmin.Post("/export", userHandler.AdminExportUsers)
+	userAdmin.Get("/detail", userHandler.AdminGetUserDetail)
+	userAdmin.Post("/update", middleware.Audit(model.ActionUpdateUser, model.ModuleAdmin), userHandler.AdminUpdateUser)
+	userAdmin.Post("/delete", middleware.Audit(model.ActionDeleteUser, model.ModuleAdmin), userHandler.AdminDeleteUser)
+	userAdmin.Post("/restore", middleware.Audit(model.ActionRestoreUser, model.ModuleAdmin), userHandler.AdminRestoreUser)
+	userAdmin.Post("/resetPassword", middleware.Audit(model.ActionResetPassword, model.ModuleAdmin), userHandler.AdminResetPassword)
+	userAdmin.Post("/unlock", middleware.Audit(model.ActionUnlockUser, model.ModuleAdmin), userHandler.AdminUnlockUser)
+	userAdmin.Post("/updateStatus", middleware.Audit(model.ActionUpdateStatus, model.ModuleAdmin), userHandler.AdminUpdateUserStatus)
+	userAdmin.Get("/tokens", emailHandler.AdminGetTokenStats)
+	userAdmin.Post("/tokens/purge", middleware.Audit(model.ActionDelete, model.ModuleAdmin), emailHandler.AdminPurgeTokens)
 
 	// Audit log
-	admin.Post("/audit/list", auditHandler.GetAuditLogs)
+	admin.Post("/audit/list", middleware.RequirePermission(model.PermAuditView), auditHandler.GetAuditLogs)
+	admin.Get("/audit/export", middleware.RequirePermission(model.PermAuditView), auditHandler.ExportAuditLogs)
+
+	// Role & permission management (角色权限管理)
+	roleAdmin := admin.Group("/role", middleware.RequirePermission(model.PermRoleManage))
+	roleAdmin.Get("/list", rbacHandler.GetRoles)
+	roleAdmin.Get("/permissions", rbacHandler.GetPermissions)
+	roleAdmin.Post("/add", middleware.Audit(model.ActionCreate, model.ModuleRole), rbacHandler.CreateRole)
+	roleAdmin.Post("/update", middleware.Audit(model.ActionUpdate, model.ModuleRole), rbacHandler.UpdateRole)
+	roleAdmin.Post("/delete", middleware.Audit(model.ActionDelete, model.ModuleRole), rbacHandler.DeleteRole)
+	roleAdmin.Get("/user", rbacHandler.GetUserRoles)
+	roleAdmin.Post("/assign", middleware.Audit(model.ActionAssignRoles, model.ModuleRole), rbacHandler.AssignUserRoles)
 
 	// Config management (系统配置管理)
-	configAdmin := admin.Group("/config")
+	configAdmin := admin.Group("/config", middleware.RequirePermission(model.PermConfigManage))
 	configAdmin.Get("/list", configHandler.GetAllConfigs)
 	configAdmin.Get("/group", configHandler.GetConfigsByGroup)
-	configAdmin.Post("/add", configHandler.CreateConfig)
-	configAdmin.Post("/update", configHandler.UpdateConfig)
-	configAdmin.Post("/delete", configHandler.DeleteConfig)
-	configAdmin.Post("/batchUpdate", configHandler.BatchUpdateConfigs)
-	configAdmin.Post("/refresh", configHandler.RefreshCache)
+	configAdmin.Post("/add", middleware.Audit(model.ActionCreate, model.ModuleConfig), configHandler.CreateConfig)
+	configAdmin.Post("/update", middleware.Audit(model.ActionUpdate, model.ModuleConfig), configHandler.UpdateConfig)
+	configAdmin.Post("/delete", middleware.Audit(model.ActionDelete, model.ModuleConfig), configHandler.DeleteConfig)
+	configAdmin.Post("/batchUpdate", middleware.Audit(model.ActionUpdate, model.ModuleConfig), configHandler.BatchUpdateConfigs)
+	configAdmin.Post("/refresh", middleware.Audit(model.ActionUpdate, model.ModuleConfig), configHandler.RefreshCache)
+	configAdmin.Get("/history", configHandler.GetConfigHistory)
+	configAdmin.Post("/rollback", middleware.Audit(model.ActionUpdate, model.ModuleConfig), configHandler.RollbackConfig)
+	configAdmin.Get("/export", configHandler.ExportConfigs)
+	configAdmin.Post("/import", middleware.Audit(model.ActionImport, model.ModuleConfig), configHandler.ImportConfigs)
 	configAdmin.Get("/email", configHandler.GetEmailConfig)
-	configAdmin.Post("/email", configHandler.UpdateEmailConfig)
+	configAdmin.Post("/email", middleware.Audit(model.ActionUpdate, model.ModuleConfig), configHandler.UpdateEmailConfig)
+	configAdmin.Post("/email/test", configHandler.SendTestEmail)
+	configAdmin.Get("/upload", configHandler.GetUploadConfig)
+	configAdmin.Post("/upload", middleware.Audit(model.ActionUpdate, model.ModuleConfig), configHandler.UpdateUploadConfig)
+
+	// Log level (运行时日志级别)
+	logAdmin := admin.Group("/log", middleware.RequirePermission(model.PermLogManage))
+	logAdmin.Get("/level", logHandler.GetLogLevel)
+	logAdmin.Post("/level", middleware.Audit(model.ActionUpdate, model.ModuleSystem), logHandler.SetLogLevel)
+
+	// Maintenance mode (维护模式)
+	maintenanceAdmin := admin.Group("/maintenance", middleware.RequirePermission(model.PermConfigManage))
+	maintenanceAdmin.Get("", maintenanceHandler.GetMaintenance)
+	maintenanceAdmin.Post("", middleware.Audit(model.ActionUpdate, model.ModuleSystem), maintenanceHandler.SetMaintenance)
+
+	// Cron jobs (定时任务，仅超级管理员)
+	cronAdmin := admin.Group("/cron", middleware.RequirePermission(model.PermAll))
+	cronAdmin.Get("/jobs", cronHandler.GetJobs)
+	cronAdmin.Get("/events", cronHandler.JobEvents)
 }
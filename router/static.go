@@ -0,0 +1,57 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"goboot/config"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/static"
+)
+
+// defaultStaticMaxAge 上传文件静态访问的默认缓存时间(秒)，文件名为 UUID，内容不会变化
+const defaultStaticMaxAge = 30 * 24 * 3600
+
+// uploadStatic 上传文件的静态文件服务：附带长期缓存头，
+// 除图片、音频和视频外的文件(包括 SVG、HTML)以附件形式返回，避免浏览器直接渲染上传内容
+func uploadStatic(dir string) fiber.Handler {
+	maxAge := config.Get().Upload.StaticMaxAge
+	if maxAge == 0 {
+		maxAge = defaultStaticMaxAge
+	}
+
+	return static.New(dir, static.Config{
+		ModifyResponse: func(c fiber.Ctx) error {
+			if maxAge > 0 {
+				c.Set(fiber.HeaderCacheControl, "public, max-age="+strconv.Itoa(maxAge)+", immutable")
+				c.Set(fiber.HeaderExpires, time.Now().Add(time.Duration(maxAge)*time.Second).UTC().Format(http.TimeFormat))
+			}
+			if !isInlineMimeType(string(c.Response().Header.ContentType())) {
+				c.Attachment(filepath.Base(c.Path()))
+			}
+			return nil
+		},
+	})
+}
+
+// isInlineMimeType 是否允许浏览器直接展示：图片(SVG 除外)、音频和视频
+func isInlineMimeType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "image/svg+xml":
+		return false
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package router
+
+import (
+	"goboot/docs"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/static"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// swaggerInitializer 替换 Swagger UI 默认的初始化脚本，加载本服务生成的接口文档
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "doc.json",
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    persistAuthorization: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    plugins: [
+      SwaggerUIBundle.plugins.DownloadUrl
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// setupSwagger 注册接口文档路由: /swagger/index.html 为 Swagger UI，/swagger/doc.json 为 OpenAPI 文档
+// 文档由 swag 根据处理器注释生成，修改注释后需执行 swag init 重新生成 docs 目录
+func setupSwagger(app *fiber.App) {
+	app.Get("/swagger", func(c fiber.Ctx) error {
+		return c.Redirect().To("/swagger/index.html")
+	})
+	app.Get("/swagger/doc.json", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.SendString(docs.SwaggerInfo.ReadDoc())
+	})
+	app.Get("/swagger/swagger-initializer.js", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "text/javascript; charset=utf-8")
+		return c.SendString(swaggerInitializer)
+	})
+	app.Get("/swagger/*", static.New("", static.Config{FS: swaggerFiles.FS}))
+}